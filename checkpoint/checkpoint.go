@@ -0,0 +1,143 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package checkpoint records the progress of a collection so that an interrupted collection may be resumed.
+package checkpoint
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// Store records, per stage of a collection, the keys of the objects the stage has finished enumerating. Records are
+// appended to a file as they are completed so that they survive the collection being interrupted.
+type Store struct {
+	mutex   sync.Mutex
+	file    *os.File
+	resumed map[string]map[string]bool
+}
+
+type record struct {
+	Stage string `json:"stage"`
+	Key   string `json:"key"`
+}
+
+// Create creates an empty store at the path, replacing any existing store
+func Create(path string) (*Store, error) {
+	if file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600); err != nil {
+		return nil, err
+	} else {
+		return &Store{file: file, resumed: map[string]map[string]bool{}}, nil
+	}
+}
+
+// Open opens the store at the path to resume the collection that it records progress for
+func Open(path string) (*Store, error) {
+	if file, err := os.OpenFile(path, os.O_RDWR, 0600); err != nil {
+		return nil, err
+	} else if resumed, offset, err := read(file); err != nil {
+		file.Close()
+		return nil, err
+	} else if err := file.Truncate(offset); err != nil {
+		// a record may have been partially written when the collection was interrupted
+		file.Close()
+		return nil, err
+	} else if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	} else {
+		return &Store{file: file, resumed: resumed}, nil
+	}
+}
+
+// read returns the records in the file and the offset following the last complete record
+func read(file *os.File) (map[string]map[string]bool, int64, error) {
+	var (
+		reader  = bufio.NewReader(file)
+		records = map[string]map[string]bool{}
+		offset  int64
+	)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return records, offset, nil
+		} else if err != nil {
+			return nil, 0, err
+		}
+
+		var record record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return records, offset, nil
+		} else if keys, ok := records[record.Stage]; ok {
+			keys[record.Key] = true
+		} else {
+			records[record.Stage] = map[string]bool{record.Key: true}
+		}
+		offset += int64(len(line))
+	}
+}
+
+// Done returns true if the stage finished enumerating the object with the key before the collection was resumed. A nil
+// store has no records.
+func (s *Store) Done(stage, key string) bool {
+	if s == nil {
+		return false
+	} else {
+		return s.resumed[stage][key]
+	}
+}
+
+// Complete records that the stage has finished enumerating the object with the key. It does nothing to a nil store.
+func (s *Store) Complete(stage, key string) error {
+	if s == nil {
+		return nil
+	} else if bytes, err := json.Marshal(record{Stage: stage, Key: key}); err != nil {
+		return err
+	} else {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		_, err := s.file.Write(append(bytes, '\n'))
+		return err
+	}
+}
+
+// Resumed returns the number of objects completed before the collection was resumed
+func (s *Store) Resumed() int {
+	if s == nil {
+		return 0
+	}
+
+	count := 0
+	for _, keys := range s.resumed {
+		count += len(keys)
+	}
+	return count
+}
+
+// Close closes the store's file
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	} else {
+		return s.file.Close()
+	}
+}
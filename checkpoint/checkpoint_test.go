@@ -0,0 +1,90 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkpoint_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/checkpoint"
+)
+
+func TestResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.json.checkpoint")
+
+	if store, err := checkpoint.Create(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if store.Done("AZGroupMember", "foo") {
+		t.Error("new store should have no records")
+	} else if err := store.Complete("AZGroupMember", "foo"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := store.Complete("AZUser", "bar"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if store.Done("AZGroupMember", "foo") {
+		t.Error("records should only be done once resumed")
+	} else if err := store.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// simulate a record partially written when interrupted
+	if file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := file.WriteString(`{"stage":"AZUser","ke`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else {
+		file.Close()
+	}
+
+	store, err := checkpoint.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !store.Done("AZGroupMember", "foo") || !store.Done("AZUser", "bar") {
+		t.Error("expected completed records to be done")
+	}
+	if store.Done("AZUser", "foo") {
+		t.Error("records are per stage")
+	}
+	if store.Resumed() != 2 {
+		t.Errorf("got %v, want %v", store.Resumed(), 2)
+	}
+	if err := store.Complete("AZUser", "baz"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.Close()
+
+	if store, err := checkpoint.Open(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if store.Resumed() != 3 || !store.Done("AZUser", "baz") {
+		t.Errorf("expected the partial record to have been replaced, got %v records", store.Resumed())
+	} else {
+		store.Close()
+	}
+}
+
+func TestNilStore(t *testing.T) {
+	var store *checkpoint.Store
+	if store.Done("AZUser", "foo") {
+		t.Error("nil store should have no records")
+	} else if err := store.Complete("AZUser", "foo"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if err := store.Close(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
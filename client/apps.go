@@ -19,8 +19,8 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"net/url"
 	"strings"
 
 	"github.com/bloodhoundad/azurehound/client/query"
@@ -101,167 +101,52 @@ func (s *azureClient) GetAzureADApps(ctx context.Context, filter, search, orderB
 }
 
 func (s *azureClient) ListAzureADApps(ctx context.Context, filter, search, orderBy, expand string, selectCols []string) <-chan azure.ApplicationResult {
-	out := make(chan azure.ApplicationResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.ApplicationResult{}
-			nextLink  string
-		)
-
-		if list, err := s.GetAzureADApps(ctx, filter, search, orderBy, expand, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.ApplicationResult{Ok: u}
+	return paginate(ctx, s.msgraph,
+		func() ([]azure.Application, string, error) {
+			list, err := s.GetAzureADApps(ctx, filter, search, orderBy, expand, selectCols, 999, false)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.Application]) azure.ApplicationResult {
+			return azure.ApplicationResult{
+				Error: result.Error,
+				Ok:    result.Ok,
+				Page:  result.Page,
 			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.ApplicationList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else {
-					for _, u := range list.Value {
-						out <- azure.ApplicationResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
 
 func (s *azureClient) ListAzureADAppOwners(ctx context.Context, objectId string, filter, search, orderBy string, selectCols []string) <-chan azure.AppOwnerResult {
-	out := make(chan azure.AppOwnerResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.AppOwnerResult{}
-			nextLink  string
-		)
-
-		if list, err := s.GetAzureADAppOwners(ctx, objectId, filter, search, orderBy, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.AppOwnerResult{
-					AppId: objectId,
-					Ok:    u,
-				}
+	return paginate(ctx, s.msgraph,
+		func() ([]json.RawMessage, string, error) {
+			list, err := s.GetAzureADAppOwners(ctx, objectId, filter, search, orderBy, selectCols, 999, false)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[json.RawMessage]) azure.AppOwnerResult {
+			return azure.AppOwnerResult{
+				AppId: objectId,
+				Error: result.Error,
+				Ok:    result.Ok,
+				Page:  result.Page,
 			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.DirectoryObjectList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else {
-					for _, u := range list.Value {
-						out <- azure.AppOwnerResult{
-							AppId: objectId,
-							Ok:    u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
 
 func (s *azureClient) ListAzureADAppMemberObjects(ctx context.Context, objectId string, securityEnabledOnly bool) <-chan azure.MemberObjectResult {
-	out := make(chan azure.MemberObjectResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.MemberObjectResult{
+	return paginate(ctx, s.msgraph,
+		func() ([]json.RawMessage, string, error) {
+			list, err := s.GetAzureADAppMemberObjects(ctx, objectId, securityEnabledOnly)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[json.RawMessage]) azure.MemberObjectResult {
+			return azure.MemberObjectResult{
 				ParentId:   objectId,
 				ParentType: string(enums.EntityApplication),
+				Error:      result.Error,
+				Ok:         result.Ok,
+				Page:       result.Page,
 			}
-			nextLink string
-		)
-		if list, err := s.GetAzureADAppMemberObjects(ctx, objectId, securityEnabledOnly); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.MemberObjectResult{
-					ParentId:   objectId,
-					ParentType: string(enums.EntityApplication),
-					Ok:         u,
-				}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.MemberObjectList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.MemberObjectResult{
-							ParentId:   objectId,
-							ParentType: string(enums.EntityApplication),
-							Ok:         u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
@@ -31,28 +31,36 @@ import (
 )
 
 func NewClient(config config.Config) (AzureClient, error) {
+	for _, jwt := range config.JWT {
+		if err := rest.ValidateJWT(jwt); err != nil {
+			return nil, err
+		}
+	}
+
 	if msgraph, err := rest.NewRestClient(config.GraphUrl(), config); err != nil {
 		return nil, err
 	} else if resourceManager, err := rest.NewRestClient(config.ResourceManagerUrl(), config); err != nil {
 		return nil, err
+	} else if graphJWT, err := rest.SelectJWT(config.JWT, config.GraphUrl()); err != nil {
+		return nil, err
+	} else if rmJWT, err := rest.SelectJWT(config.JWT, config.ResourceManagerUrl()); err != nil {
+		return nil, err
 	} else {
+		// an audience without a JWT is still reachable if tokens can be acquired for it
+		var (
+			canAcquire = len(config.JWT) == 0 || config.HasCredential()
+			hasGraph   = graphJWT != "" || canAcquire
+			hasRM      = rmJWT != "" || canAcquire
+		)
 
-		if config.JWT != "" {
-			if aud, err := rest.ParseAud(config.JWT); err != nil {
-				return nil, err
-			} else if aud == config.GraphUrl() {
-				return initClientViaGraph(msgraph, resourceManager)
-			} else if aud == config.ResourceManagerUrl() {
-				if body, err := rest.ParseBody(config.JWT); err != nil {
-					return nil, err
-				} else {
-					return initClientViaRM(msgraph, resourceManager, body["tid"])
-				}
-			} else {
-				return nil, fmt.Errorf("error: invalid token audience")
-			}
+		if hasGraph {
+			return initClientViaGraph(msgraph, resourceManager, hasRM)
+		} else if !hasRM {
+			return nil, fmt.Errorf("error: invalid token audience; none of the provided JWTs are for %s or %s", config.GraphUrl(), config.ResourceManagerUrl())
+		} else if body, err := rest.ParseBody(rmJWT); err != nil {
+			return nil, err
 		} else {
-			return initClientViaGraph(msgraph, resourceManager)
+			return initClientViaRM(msgraph, resourceManager, body["tid"])
 		}
 	}
 }
@@ -61,6 +69,7 @@ func initClientViaRM(msgraph, resourceManager rest.RestClient, tid interface{})
 	client := &azureClient{
 		msgraph:         msgraph,
 		resourceManager: resourceManager,
+		hasRM:           true,
 	}
 	if result, err := client.GetAzureADTenants(context.Background(), true); err != nil {
 		return nil, err
@@ -75,10 +84,12 @@ func initClientViaRM(msgraph, resourceManager rest.RestClient, tid interface{})
 	}
 }
 
-func initClientViaGraph(msgraph, resourceManager rest.RestClient) (AzureClient, error) {
+func initClientViaGraph(msgraph, resourceManager rest.RestClient, hasRM bool) (AzureClient, error) {
 	client := &azureClient{
 		msgraph:         msgraph,
 		resourceManager: resourceManager,
+		hasGraph:        true,
+		hasRM:           hasRM,
 	}
 	if org, err := client.GetAzureADOrganization(context.Background(), nil); err != nil {
 		return nil, err
@@ -92,12 +103,22 @@ type azureClient struct {
 	msgraph         rest.RestClient
 	resourceManager rest.RestClient
 	tenant          azure.Tenant
+	hasGraph        bool // Whether requests can be authenticated for Microsoft Graph
+	hasRM           bool // Whether requests can be authenticated for Azure Resource Manager
 }
 
 func (s azureClient) TenantInfo() azure.Tenant {
 	return s.tenant
 }
 
+func (s azureClient) HasGraphAccess() bool {
+	return s.hasGraph
+}
+
+func (s azureClient) HasResourceManagerAccess() bool {
+	return s.hasRM
+}
+
 type AzureClient interface {
 	GetAzureADApp(ctx context.Context, objectId string, selectCols []string) (*azure.Application, error)
 	GetAzureADApps(ctx context.Context, filter, search, orderBy, expand string, selectCols []string, top int32, count bool) (azure.ApplicationList, error)
@@ -130,6 +151,8 @@ type AzureClient interface {
 	GetAzureVirtualMachines(ctx context.Context, subscriptionId string, statusOnly bool) (azure.VirtualMachineList, error)
 	GetResourceRoleAssignments(ctx context.Context, subscriptionId string, filter string, expand string) (azure.RoleAssignmentList, error)
 	GetRoleAssignmentsForResource(ctx context.Context, resourceId string, filter string) (azure.RoleAssignmentList, error)
+	HasGraphAccess() bool
+	HasResourceManagerAccess() bool
 	ListAzureADAppMemberObjects(ctx context.Context, objectId string, securityEnabledOnly bool) <-chan azure.MemberObjectResult
 	ListAzureADAppOwners(ctx context.Context, objectId string, filter, search, orderBy string, selectCols []string) <-chan azure.AppOwnerResult
 	ListAzureADApps(ctx context.Context, filter, search, orderBy, expand string, selectCols []string) <-chan azure.ApplicationResult
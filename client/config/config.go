@@ -22,23 +22,53 @@ import (
 )
 
 type Config struct {
-	ApplicationId  string   // The Application Id that the  Azure app registration portal assigned when the app was registered.
-	Authority      string   // The Azure ActiveDirectory Authority URL
-	ClientSecret   string   // The Application Secret that was generated for the app in the app registration portal.
-	ClientCert     string   // The certificate uploaded to the app registration portal."
-	ClientKey      string   // The key for a certificate uploaded to the app registration portal."
-	ClientKeyPass  string   // The passphrase to use in conjuction with the associated key of a certificate uploaded to the app registration portal."
-	Graph          string   // The Microsoft Graph URL
-	JWT            string   // The JSON web token that will be used to authenticate requests sent to Azure APIs
-	Management     string   // The Azure ResourceManager URL
-	MgmtGroupId    []string // The Management Group Id to use as a filter
-	Password       string   // The password associated with the user principal name associated with the Azure portal.
-	ProxyUrl       string   // The forward proxy url
-	RefreshToken   string   // The refresh token that will be used to authenticate requests sent to Azure APIs
-	Region         string   // The region of the Azure Cloud deployment.
-	SubscriptionId []string // The Subscription Id(s) to use as a filter
-	Tenant         string   // The directory tenant that you want to request permission from. This can be in GUID or friendly name format
-	Username       string   // The user principal name associated with the Azure portal.
+	ApplicationId           string   // The Application Id that the  Azure app registration portal assigned when the app was registered.
+	Authority               string   // The Azure ActiveDirectory Authority URL
+	AuthRateLimit           int      // The maximum number of requests per second sent to the Azure ActiveDirectory Authority
+	CACerts                 []string // The PEM files of root certificates, e.g. of an SSL inspecting proxy, to trust in addition to the system roots
+	ClientSecret            string   // The Application Secret that was generated for the app in the app registration portal.
+	ClientCert              string   // The certificate uploaded to the app registration portal."
+	ClientKey               string   // The key for a certificate uploaded to the app registration portal."
+	ClientKeyPass           string   // The passphrase to use in conjuction with the associated key of a certificate uploaded to the app registration portal."
+	DeviceCode              bool     // Whether the user signs in interactively using the OAuth device code flow
+	FederatedTokenFile      string   // The file containing an OIDC token from an external identity provider trusted by the app registration
+	Graph                   string   // The Microsoft Graph URL
+	GraphBatch              bool     // Whether per-object Microsoft Graph requests are coalesced into JSON batches
+	GraphRateLimit          int      // The maximum number of requests per second sent to Microsoft Graph
+	JWT                     []string // The JSON web tokens, one per audience, that will be used to authenticate requests sent to Azure APIs
+	Management              string   // The Azure ResourceManager URL
+	ManagedIdentity         bool     // Whether to authenticate as the managed identity of the Azure resource AzureHound runs on
+	ManagedIdentityEndpoint string   // The managed identity token endpoint; defaults to IDENTITY_ENDPOINT or the Instance Metadata Service
+	MaxRetries              int      // The maximum number of times a throttled or otherwise transiently failed request is retried
+	MgmtGroupId             []string // The Management Group Id to use as a filter
+	MgmtRateLimit           int      // The maximum number of requests per second sent to Azure Resource Manager
+	Password                string   // The password associated with the user principal name associated with the Azure portal.
+	ProxyUrl                string   // The forward proxy url
+	Record                  string   // The cassette file every request and response is recorded to, scrubbed of credentials and tokens
+	RefreshToken            string   // The refresh token that will be used to authenticate requests sent to Azure APIs
+	RefreshTokenClientId    string   // The client the refresh token was issued to; defaults to the Azure PowerShell client id
+	Region                  string   // The region of the Azure Cloud deployment.
+	Replay                  string   // The cassette file recorded responses are replayed from instead of sending requests to Azure
+	SendCertChain           bool     // Whether the certificate chain is sent with client assertions to support subject name and issuer authentication
+	SubscriptionId          []string // The Subscription Id(s) to use as a filter
+	Tenant                  string   // The directory tenant that you want to request permission from. This can be in GUID or friendly name format
+	TLSCert                 string   // The PEM file of the client certificate presented to proxies that require mutual TLS
+	TLSKey                  string   // The PEM file of the private key of the TLS client certificate
+	TokenCache              string   // The file in which acquired tokens are cached, encrypted, across runs; tokens are only cached in memory if empty
+	TokenCacheKey           string   // The secret from which the key that encrypts the TokenCache is derived
+	Username                string   // The user principal name associated with the Azure portal.
+}
+
+// HasCredential reports whether a credential other than a JWT was provided from which tokens for any audience can be
+// acquired
+func (s Config) HasCredential() bool {
+	return s.ClientSecret != "" ||
+		(s.ClientCert != "" && s.ClientKey != "") ||
+		(s.Username != "" && s.Password != "") ||
+		s.RefreshToken != "" ||
+		s.DeviceCode ||
+		s.ManagedIdentity ||
+		s.FederatedTokenFile != ""
 }
 
 func AuthorityUrl(region string, defaultUrl string) string {
@@ -58,8 +88,13 @@ func AuthorityUrl(region string, defaultUrl string) string {
 	}
 }
 
+// AuthorityUrl returns the explicitly configured authority, e.g. of a test server, or else the region's
 func (s Config) AuthorityUrl() string {
-	return AuthorityUrl(s.Region, s.Authority)
+	if s.Authority != "" {
+		return s.Authority
+	} else {
+		return AuthorityUrl(s.Region, constants.AzureCloud().ActiveDirectoryAuthority)
+	}
 }
 
 func GraphUrl(region string, defaultUrl string) string {
@@ -79,8 +114,13 @@ func GraphUrl(region string, defaultUrl string) string {
 	}
 }
 
+// GraphUrl returns the explicitly configured Microsoft Graph url or else the region's
 func (s Config) GraphUrl() string {
-	return GraphUrl(s.Region, s.Graph)
+	if s.Graph != "" {
+		return s.Graph
+	} else {
+		return GraphUrl(s.Region, constants.AzureCloud().MicrosoftGraphUrl)
+	}
 }
 
 func ResourceManagerUrl(region string, defaultUrl string) string {
@@ -100,6 +140,11 @@ func ResourceManagerUrl(region string, defaultUrl string) string {
 	}
 }
 
+// ResourceManagerUrl returns the explicitly configured Azure Resource Manager url or else the region's
 func (s Config) ResourceManagerUrl() string {
-	return ResourceManagerUrl(s.Region, s.Graph)
+	if s.Management != "" {
+		return s.Management
+	} else {
+		return ResourceManagerUrl(s.Region, constants.AzureCloud().ResourceManagerUrl)
+	}
 }
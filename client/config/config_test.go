@@ -0,0 +1,71 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+	"github.com/bloodhoundad/azurehound/constants"
+)
+
+func TestRegionUrls(t *testing.T) {
+	tests := map[string]constants.Environment{
+		constants.China:   constants.AzureChina(),
+		constants.Cloud:   constants.AzureCloud(),
+		constants.Germany: constants.AzureGermany(),
+		constants.USGovL4: constants.AzureUSGovernment(),
+		constants.USGovL5: constants.AzureUSGovernmentL5(),
+	}
+
+	public := constants.AzureCloud()
+	for region, environment := range tests {
+		t.Run(region, func(t *testing.T) {
+			if got := config.AuthorityUrl(region, public.ActiveDirectoryAuthority); got != environment.ActiveDirectoryAuthority {
+				t.Errorf("got authority %s, want %s", got, environment.ActiveDirectoryAuthority)
+			}
+			if got := config.GraphUrl(region, public.MicrosoftGraphUrl); got != environment.MicrosoftGraphUrl {
+				t.Errorf("got graph %s, want %s", got, environment.MicrosoftGraphUrl)
+			}
+			if got := config.ResourceManagerUrl(region, public.ResourceManagerUrl); got != environment.ResourceManagerUrl {
+				t.Errorf("got resource manager %s, want %s", got, environment.ResourceManagerUrl)
+			}
+
+			regional := config.Config{Region: region}
+			if got := regional.AuthorityUrl(); got != environment.ActiveDirectoryAuthority {
+				t.Errorf("got authority %s, want %s", got, environment.ActiveDirectoryAuthority)
+			}
+			if got := regional.GraphUrl(); got != environment.MicrosoftGraphUrl {
+				t.Errorf("got graph %s, want %s", got, environment.MicrosoftGraphUrl)
+			}
+			if got := regional.ResourceManagerUrl(); got != environment.ResourceManagerUrl {
+				t.Errorf("got resource manager %s, want %s", got, environment.ResourceManagerUrl)
+			}
+
+			// explicitly configured urls, e.g. of a test server, override the region
+			explicit := config.Config{Region: region, Authority: "http://127.0.0.1:1", Graph: "http://127.0.0.1:2", Management: "http://127.0.0.1:3"}
+			if explicit.AuthorityUrl() != explicit.Authority || explicit.GraphUrl() != explicit.Graph || explicit.ResourceManagerUrl() != explicit.Management {
+				t.Errorf("got %s, %s and %s, want the explicitly configured urls", explicit.AuthorityUrl(), explicit.GraphUrl(), explicit.ResourceManagerUrl())
+			}
+		})
+	}
+
+	if got := config.GraphUrl("unknown", public.MicrosoftGraphUrl); got != public.MicrosoftGraphUrl {
+		t.Errorf("got %s, want the default for an unknown region", got)
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package fake
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/gofrs/uuid"
+)
+
+// The built-in roles that generated role assignments are for
+var generatedRoles = []string{
+	"8e3af657-a8ff-443c-a75c-2fe8c4bcb635", // Owner
+	"b24988ac-6180-42a0-ab88-20f7382dd24c", // Contributor
+	"18d7d88d-d35e-4fb5-a5c3-7773c20a72d9", // User Access Administrator
+	"acdd72a7-3385-48ef-bd42-f606fba81ae7", // Reader
+}
+
+// Shape is the size and shape of a generated tenant
+type Shape struct {
+	Users                           int
+	Groups                          int
+	GroupNestingDepth               int // The number of groups nested beneath each top-level group
+	MembersPerGroup                 int // The number of users in each group, in addition to any nested group
+	ServicePrincipals               int
+	Subscriptions                   int
+	ResourceGroupsPerSubscription   int
+	VirtualMachinesPerResourceGroup int
+	KeyVaultsPerResourceGroup       int
+	OwnersPerObject                 int // The number of users owning each group and service principal
+	RoleAssignmentsPerScope         int // The number of role assignments at each subscription, resource group and resource
+}
+
+// Objects returns the number of users, groups, service principals and Azure Resource Manager resources in the shape
+func (s Shape) Objects() int {
+	var (
+		resourceGroups = s.Subscriptions * s.ResourceGroupsPerSubscription
+		resources      = resourceGroups * (s.VirtualMachinesPerResourceGroup + s.KeyVaultsPerResourceGroup)
+	)
+	return s.Users + s.Groups + s.ServicePrincipals + s.Subscriptions + resourceGroups + resources
+}
+
+// Generate returns a tenant of the given shape. The same shape and seed always generate the same tenant.
+func Generate(shape Shape, seed int64) Tenant {
+	var (
+		random = rand.New(rand.NewSource(seed))
+		tenant = Tenant{
+			TenantId:     newId(random),
+			DisplayName:  fmt.Sprintf("Synthetic %d", seed),
+			GroupMembers: make(map[string][]string),
+			Owners:       make(map[string][]string),
+		}
+		principals []string
+	)
+
+	for i := 0; i < shape.Users; i++ {
+		var user azure.User
+		user.Id = newId(random)
+		user.DisplayName = fmt.Sprintf("User %d", i)
+		user.UserPrincipalName = fmt.Sprintf("user%d@synthetic.example", i)
+		tenant.Users = append(tenant.Users, user)
+		principals = append(principals, user.Id)
+	}
+
+	for i := 0; i < shape.Groups; i++ {
+		var group azure.Group
+		group.Id = newId(random)
+		group.DisplayName = fmt.Sprintf("Group %d", i)
+		group.SecurityEnabled = true
+		tenant.Groups = append(tenant.Groups, group)
+		principals = append(principals, group.Id)
+
+		members := pick(random, tenant.Users, shape.MembersPerGroup)
+
+		// groups are nested in chains of GroupNestingDepth+1, each group being a member of the one before it
+		if depth := shape.GroupNestingDepth + 1; i%depth != 0 {
+			parent := tenant.Groups[i-1].Id
+			tenant.GroupMembers[parent] = append(tenant.GroupMembers[parent], group.Id)
+		}
+		tenant.GroupMembers[group.Id] = append(tenant.GroupMembers[group.Id], members...)
+		tenant.Owners[group.Id] = pick(random, tenant.Users, shape.OwnersPerObject)
+	}
+
+	for i := 0; i < shape.ServicePrincipals; i++ {
+		var servicePrincipal azure.ServicePrincipal
+		servicePrincipal.Id = newId(random)
+		servicePrincipal.AppId = newId(random)
+		servicePrincipal.DisplayName = fmt.Sprintf("Service Principal %d", i)
+		tenant.ServicePrincipals = append(tenant.ServicePrincipals, servicePrincipal)
+		tenant.Owners[servicePrincipal.Id] = pick(random, tenant.Users, shape.OwnersPerObject)
+		principals = append(principals, servicePrincipal.Id)
+	}
+
+	assignRoles := func(scope string) {
+		subscriptionId := subscriptionOf(scope)
+		for i := 0; i < shape.RoleAssignmentsPerScope && len(principals) > 0; i++ {
+			name := newId(random)
+			tenant.RoleAssignments = append(tenant.RoleAssignments, azure.RoleAssignment{
+				Id:   scope + "/providers/Microsoft.Authorization/roleAssignments/" + name,
+				Name: name,
+				Type: "Microsoft.Authorization/roleAssignments",
+				Properties: azure.RoleAssignmentPropertiesWithScope{
+					PrincipalId:      principals[random.Intn(len(principals))],
+					RoleDefinitionId: "/subscriptions/" + subscriptionId + "/providers/Microsoft.Authorization/roleDefinitions/" + generatedRoles[random.Intn(len(generatedRoles))],
+					Scope:            scope,
+				},
+			})
+		}
+	}
+
+	for i := 0; i < shape.Subscriptions; i++ {
+		var subscription azure.Subscription
+		subscription.SubscriptionId = newId(random)
+		subscription.Id = "/subscriptions/" + subscription.SubscriptionId
+		subscription.DisplayName = fmt.Sprintf("Subscription %d", i)
+		subscription.TenantId = tenant.TenantId
+		tenant.Subscriptions = append(tenant.Subscriptions, subscription)
+		assignRoles(subscription.Id)
+
+		for j := 0; j < shape.ResourceGroupsPerSubscription; j++ {
+			var group azure.ResourceGroup
+			group.Name = fmt.Sprintf("rg-%d", j)
+			group.Id = subscription.Id + "/resourceGroups/" + group.Name
+			group.Location = "westus"
+			tenant.ResourceGroups = append(tenant.ResourceGroups, group)
+			assignRoles(group.Id)
+
+			for k := 0; k < shape.VirtualMachinesPerResourceGroup; k++ {
+				var vm azure.VirtualMachine
+				vm.Name = fmt.Sprintf("vm-%d-%d", j, k)
+				vm.Id = group.Id + "/providers/Microsoft.Compute/virtualMachines/" + vm.Name
+				vm.Location = group.Location
+				tenant.VirtualMachines = append(tenant.VirtualMachines, vm)
+				assignRoles(vm.Id)
+			}
+
+			for k := 0; k < shape.KeyVaultsPerResourceGroup; k++ {
+				var vault azure.KeyVault
+				vault.Name = fmt.Sprintf("kv-%d-%d", j, k)
+				vault.Id = group.Id + "/providers/Microsoft.KeyVault/vaults/" + vault.Name
+				vault.Location = group.Location
+				tenant.KeyVaults = append(tenant.KeyVaults, vault)
+				assignRoles(vault.Id)
+			}
+		}
+	}
+	return tenant
+}
+
+// newId returns a random version 4 UUID drawn from random
+func newId(random *rand.Rand) string {
+	var id uuid.UUID
+	random.Read(id[:])
+	id.SetVersion(uuid.V4)
+	id.SetVariant(uuid.VariantRFC4122)
+	return id.String()
+}
+
+// pick returns the ids of n consecutive users starting at random, or of every user if there are fewer than n
+func pick(random *rand.Rand, users []azure.User, n int) []string {
+	if n > len(users) {
+		n = len(users)
+	}
+
+	var (
+		ids   = make([]string, 0, n)
+		start = 0
+	)
+
+	if len(users) > 0 {
+		start = random.Intn(len(users))
+	}
+
+	for i := 0; i < n; i++ {
+		ids = append(ids, users[(start+i)%len(users)].Id)
+	}
+	return ids
+}
@@ -0,0 +1,87 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package fake
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client"
+)
+
+func TestGenerate(t *testing.T) {
+	shape := Shape{
+		Users:                           10,
+		Groups:                          6,
+		GroupNestingDepth:               2,
+		MembersPerGroup:                 3,
+		ServicePrincipals:               2,
+		Subscriptions:                   2,
+		ResourceGroupsPerSubscription:   2,
+		VirtualMachinesPerResourceGroup: 2,
+		KeyVaultsPerResourceGroup:       1,
+		OwnersPerObject:                 2,
+		RoleAssignmentsPerScope:         1,
+	}
+
+	tenant := Generate(shape, 42)
+	if !reflect.DeepEqual(tenant, Generate(shape, 42)) {
+		t.Errorf("expected the same seed to generate the same tenant")
+	} else if reflect.DeepEqual(tenant, Generate(shape, 43)) {
+		t.Errorf("expected another seed to generate another tenant")
+	} else if len(tenant.VirtualMachines) != 8 || len(tenant.KeyVaults) != 4 {
+		t.Errorf("got %v virtual machines and %v key vaults, want 8 and 4", len(tenant.VirtualMachines), len(tenant.KeyVaults))
+	} else if want := 2 + 4 + 8 + 4; len(tenant.RoleAssignments) != want {
+		t.Errorf("got %v role assignments, want %v", len(tenant.RoleAssignments), want)
+	}
+
+	server := NewServer(tenant)
+	defer server.Close()
+	server.PageSize = 2
+
+	azClient, err := client.NewClient(server.Config())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the first group of each chain has its users and the next group as members
+	var members int
+	for result := range azClient.ListAzureADGroupMembers(context.Background(), tenant.Groups[0].Id, "", "", "", nil) {
+		if result.Error != nil {
+			t.Fatalf("unexpected error: %v", result.Error)
+		}
+		members++
+	}
+
+	if members != shape.MembersPerGroup+1 {
+		t.Errorf("got %v members, want %v", members, shape.MembersPerGroup+1)
+	}
+
+	var owners int
+	for result := range azClient.ListAzureADServicePrincipalOwners(context.Background(), tenant.ServicePrincipals[0].Id, "", "", "", nil) {
+		if result.Error != nil {
+			t.Fatalf("unexpected error: %v", result.Error)
+		}
+		owners++
+	}
+
+	if owners != shape.OwnersPerObject {
+		t.Errorf("got %v owners, want %v", owners, shape.OwnersPerObject)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package fake
+
+import (
+	"strings"
+
+	"github.com/bloodhoundad/azurehound/models/azure"
+)
+
+// directoryObject is how a user, group or service principal is listed as a member or owner
+type directoryObject struct {
+	Type        string `json:"@odata.type"`
+	Id          string `json:"id"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// index looks up the tenant's objects so that large synthetic tenants can be served without scanning the fixture on
+// every request
+type index struct {
+	users             []interface{}
+	groups            []interface{}
+	servicePrincipals []interface{}
+	subscriptions     []interface{}
+	objects           map[string]interface{}     // The users, groups and service principals by id
+	directoryObjects  map[string]directoryObject // How each user, group and service principal is listed as a member or owner
+	subscriptionById  map[string]azure.Subscription
+	resourceGroups    map[string][]interface{} // The resource groups in each subscription
+	virtualMachines   map[string][]interface{} // The virtual machines in each subscription
+	keyVaults         map[string][]interface{} // The key vaults in each subscription
+	roleAssignments   map[string][]interface{} // The role assignments at each scope
+}
+
+func newIndex(tenant Tenant) *index {
+	s := &index{
+		objects:          make(map[string]interface{}),
+		directoryObjects: make(map[string]directoryObject),
+		subscriptionById: make(map[string]azure.Subscription),
+		resourceGroups:   make(map[string][]interface{}),
+		virtualMachines:  make(map[string][]interface{}),
+		keyVaults:        make(map[string][]interface{}),
+		roleAssignments:  make(map[string][]interface{}),
+	}
+
+	for _, user := range tenant.Users {
+		s.users = append(s.users, user)
+		s.objects[user.Id] = user
+		s.directoryObjects[user.Id] = directoryObject{"#microsoft.graph.user", user.Id, user.DisplayName}
+	}
+
+	for _, group := range tenant.Groups {
+		s.groups = append(s.groups, group)
+		s.objects[group.Id] = group
+		s.directoryObjects[group.Id] = directoryObject{"#microsoft.graph.group", group.Id, group.DisplayName}
+	}
+
+	for _, servicePrincipal := range tenant.ServicePrincipals {
+		s.servicePrincipals = append(s.servicePrincipals, servicePrincipal)
+		s.objects[servicePrincipal.Id] = servicePrincipal
+		s.directoryObjects[servicePrincipal.Id] = directoryObject{"#microsoft.graph.servicePrincipal", servicePrincipal.Id, servicePrincipal.DisplayName}
+	}
+
+	for _, subscription := range tenant.Subscriptions {
+		s.subscriptions = append(s.subscriptions, subscription)
+		s.subscriptionById[strings.ToLower(subscription.SubscriptionId)] = subscription
+	}
+
+	for _, group := range tenant.ResourceGroups {
+		key := subscriptionOf(group.Id)
+		s.resourceGroups[key] = append(s.resourceGroups[key], group)
+	}
+
+	for _, vm := range tenant.VirtualMachines {
+		key := subscriptionOf(vm.Id)
+		s.virtualMachines[key] = append(s.virtualMachines[key], vm)
+	}
+
+	for _, vault := range tenant.KeyVaults {
+		key := subscriptionOf(vault.Id)
+		s.keyVaults[key] = append(s.keyVaults[key], vault)
+	}
+
+	for _, assignment := range tenant.RoleAssignments {
+		key := strings.ToLower(strings.TrimSuffix(assignment.Properties.Scope, "/"))
+		s.roleAssignments[key] = append(s.roleAssignments[key], assignment)
+	}
+	return s
+}
+
+// related returns the users, groups and service principals with the ids, e.g. the members of a group
+func (s *index) related(ids []string) []interface{} {
+	items := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		if object, ok := s.directoryObjects[id]; ok {
+			items = append(items, object)
+		}
+	}
+	return items
+}
+
+// scopeRoleAssignments returns the role assignments that apply to the resource, i.e. those assigned at its scope or
+// above
+func (s *index) scopeRoleAssignments(resourceId string) []interface{} {
+	var (
+		items    = []interface{}{}
+		segments = strings.Split(strings.ToLower(strings.Trim(resourceId, "/")), "/")
+	)
+
+	items = append(items, s.roleAssignments[""]...)
+	for i := range segments {
+		items = append(items, s.roleAssignments["/"+strings.Join(segments[:i+1], "/")]...)
+	}
+	return items
+}
+
+// subscriptionOf returns the subscription id in the resource id, e.g. '/subscriptions/<id>/resourceGroups/<name>'
+func subscriptionOf(resourceId string) string {
+	if segments := strings.Split(strings.Trim(resourceId, "/"), "/"); len(segments) > 1 && strings.EqualFold(segments[0], "subscriptions") {
+		return strings.ToLower(segments[1])
+	} else {
+		return ""
+	}
+}
@@ -0,0 +1,346 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package fake serves a declarative tenant fixture from the Azure ActiveDirectory token endpoint, Microsoft Graph and
+// Azure Resource Manager so that collection can be tested end to end.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+	"github.com/bloodhoundad/azurehound/constants"
+)
+
+const (
+	DefaultPageSize = 100
+	ClientId        = "11111111-1111-1111-1111-111111111111"
+	ClientSecret    = "fake-client-secret"
+)
+
+// Fault is injected into the responses to requests, to any of the servers, whose path matches
+type Fault struct {
+	Path       *regexp.Regexp // The paths of the requests to fail; all requests if nil
+	StatusCode int            // The status code of the failed responses, e.g. 429 to throttle requests
+	Code       string         // The OData error code of the failed responses
+	RetryAfter int            // The Retry-After header of the failed responses in seconds, if set
+	Times      int            // The number of requests to fail; every matching request is failed if zero
+}
+
+// Server is a fake tenant. The token endpoint, Microsoft Graph and Azure Resource Manager are served by separate
+// servers so that each has its own url, as they do in Azure.
+type Server struct {
+	Auth            *httptest.Server
+	Graph           *httptest.Server
+	ResourceManager *httptest.Server
+	Tenant          Tenant
+	PageSize        int // The maximum number of objects in each page of a list response
+
+	index         *index
+	mutex         sync.Mutex
+	faults        []*Fault
+	tokens        map[string]string // The audience of each access token issued
+	refreshTokens map[string]bool   // The refresh tokens issued
+	requests      map[string]int    // The number of requests received for each path
+}
+
+// NewServer starts serving the tenant; the caller must Close the server when done. The tenant must not be modified
+// once served.
+func NewServer(tenant Tenant) *Server {
+	s := &Server{
+		Tenant:        tenant,
+		PageSize:      DefaultPageSize,
+		index:         newIndex(tenant),
+		tokens:        make(map[string]string),
+		refreshTokens: make(map[string]bool),
+		requests:      make(map[string]int),
+	}
+	s.Auth = httptest.NewServer(s.handler(s.serveAuth, false))
+	s.Graph = httptest.NewServer(s.handler(s.serveGraph, true))
+	s.ResourceManager = httptest.NewServer(s.handler(s.serveResourceManager, true))
+	return s
+}
+
+func (s *Server) Close() {
+	s.Auth.Close()
+	s.Graph.Close()
+	s.ResourceManager.Close()
+}
+
+// Config returns a client config that authenticates to the server with a client secret
+func (s *Server) Config() config.Config {
+	return config.Config{
+		ApplicationId: ClientId,
+		Authority:     s.Auth.URL,
+		ClientSecret:  ClientSecret,
+		Graph:         s.Graph.URL,
+		Management:    s.ResourceManager.URL,
+		MaxRetries:    constants.DefaultMaxRetries,
+		Tenant:        s.Tenant.TenantId,
+	}
+}
+
+// Inject fails the requests matching the fault
+func (s *Server) Inject(fault Fault) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// Requests returns the number of requests received for the path
+func (s *Server) Requests(path string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests[path]
+}
+
+func (s *Server) handler(serve func(w http.ResponseWriter, r *http.Request), authenticate bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		s.requests[r.URL.Path]++
+		fault := s.fault(r.URL.Path)
+		audience, authorized := s.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+		s.mutex.Unlock()
+
+		if fault != nil {
+			if fault.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(fault.RetryAfter))
+			}
+			writeError(w, fault.StatusCode, fault.Code, "injected fault")
+		} else if authenticate && (!authorized || audience != "http://"+r.Host) {
+			writeError(w, http.StatusUnauthorized, "InvalidAuthenticationToken", "access token is missing, invalid or for another audience")
+		} else {
+			serve(w, r)
+		}
+	}
+}
+
+// fault returns the fault to inject into the response to the path, if any; the caller must hold the mutex
+func (s *Server) fault(path string) *Fault {
+	for i, fault := range s.faults {
+		if fault.Path == nil || fault.Path.MatchString(path) {
+			if fault.Times > 0 {
+				if fault.Times--; fault.Times == 0 {
+					s.faults = append(s.faults[:i], s.faults[i+1:]...)
+				}
+			}
+			return fault
+		}
+	}
+	return nil
+}
+
+func (s *Server) issued(refreshToken string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.refreshTokens[refreshToken]
+}
+
+var tokenPath = regexp.MustCompile(`^/([^/]+)/oauth2/v2.0/token$`)
+
+func (s *Server) serveAuth(w http.ResponseWriter, r *http.Request) {
+	if match := tokenPath.FindStringSubmatch(r.URL.Path); match == nil || r.Method != http.MethodPost {
+		writeError(w, http.StatusNotFound, "not_found", "unknown endpoint")
+	} else if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+	} else if !strings.EqualFold(match[1], s.Tenant.TenantId) {
+		writeOAuthError(w, "invalid_tenant", "tenant not found")
+	} else if r.Form.Get("grant_type") == "client_credentials" && r.Form.Get("client_assertion") == "" && r.Form.Get("client_secret") != ClientSecret {
+		writeOAuthError(w, "invalid_client", "invalid client secret")
+	} else if r.Form.Get("grant_type") == "refresh_token" && !s.issued(r.Form.Get("refresh_token")) {
+		writeOAuthError(w, "invalid_grant", "refresh token was not issued by this server")
+	} else {
+		var audience string
+		for _, scope := range strings.Fields(r.Form.Get("scope")) {
+			if strings.HasSuffix(scope, "/.default") {
+				audience = strings.TrimSuffix(scope, "/.default")
+			}
+		}
+
+		s.mutex.Lock()
+		var (
+			token        = fmt.Sprintf("fake-access-token-%d", len(s.tokens)+1)
+			refreshToken = fmt.Sprintf("fake-refresh-token-%p-%d", s, len(s.tokens)+1)
+		)
+		s.tokens[token] = audience
+		s.refreshTokens[refreshToken] = true
+		s.mutex.Unlock()
+
+		writeJSON(w, map[string]interface{}{
+			"token_type":    "Bearer",
+			"access_token":  token,
+			"refresh_token": refreshToken,
+			"expires_in":    3600,
+		})
+	}
+}
+
+var (
+	graphCollection   = regexp.MustCompile(`^/(?:v1.0|beta)/([A-Za-z]+)$`)
+	graphObject       = regexp.MustCompile(`^/(?:v1.0|beta)/([A-Za-z]+)/([^/]+)$`)
+	graphRelationship = regexp.MustCompile(`^/(?:v1.0|beta)/(?:groups|servicePrincipals|applications)/([^/]+)/(members|owners)$`)
+)
+
+func (s *Server) serveGraph(w http.ResponseWriter, r *http.Request) {
+	var (
+		collection   = graphCollection.FindStringSubmatch(r.URL.Path)
+		object       = graphObject.FindStringSubmatch(r.URL.Path)
+		relationship = graphRelationship.FindStringSubmatch(r.URL.Path)
+	)
+
+	if collection != nil && collection[1] == "organization" {
+		writeJSON(w, map[string]interface{}{"value": []interface{}{s.Tenant.organization()}})
+	} else if collection != nil {
+		s.writePage(w, r, s.Graph.URL, "@odata.nextLink", s.graphObjects(collection[1]))
+	} else if object != nil {
+		if item, ok := s.graphObject(object[1], object[2]); ok {
+			writeJSON(w, item)
+		} else {
+			writeError(w, http.StatusNotFound, "Request_ResourceNotFound", "resource does not exist")
+		}
+	} else if relationship != nil && relationship[2] == "members" {
+		s.writePage(w, r, s.Graph.URL, "@odata.nextLink", s.index.related(s.Tenant.GroupMembers[relationship[1]]))
+	} else if relationship != nil {
+		s.writePage(w, r, s.Graph.URL, "@odata.nextLink", s.index.related(s.Tenant.Owners[relationship[1]]))
+	} else {
+		// other relationships, e.g. app role assignments, are not part of the fixture
+		writeJSON(w, map[string]interface{}{"value": []interface{}{}})
+	}
+}
+
+func (s *Server) graphObjects(collection string) []interface{} {
+	switch collection {
+	case "users":
+		return s.index.users
+	case "groups":
+		return s.index.groups
+	case "servicePrincipals":
+		return s.index.servicePrincipals
+	default:
+		return []interface{}{}
+	}
+}
+
+func (s *Server) graphObject(collection, objectId string) (interface{}, bool) {
+	if item, ok := s.index.objects[objectId]; !ok {
+		return nil, false
+	} else if object, ok := s.index.directoryObjects[objectId]; !ok || !strings.HasSuffix(object.Type, "."+strings.TrimSuffix(collection, "s")) {
+		return nil, false
+	} else {
+		return item, true
+	}
+}
+
+var (
+	subscriptionPath   = regexp.MustCompile(`(?i)^/subscriptions/([^/]+)$`)
+	resourceGroupsPath = regexp.MustCompile(`(?i)^/subscriptions/([^/]+)/resourcegroups$`)
+	vmsPath            = regexp.MustCompile(`(?i)^/subscriptions/([^/]+)/providers/Microsoft.Compute/virtualMachines$`)
+	keyVaultsPath      = regexp.MustCompile(`(?i)^/subscriptions/([^/]+)/providers/Microsoft.KeyVault/vaults$`)
+	roleAssignmentPath = regexp.MustCompile(`(?i)^(.*)/providers/Microsoft.Authorization/roleAssignments$`)
+)
+
+func (s *Server) serveResourceManager(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if path == "/tenants" {
+		s.writePage(w, r, s.ResourceManager.URL, "nextLink", []interface{}{s.Tenant.tenant()})
+	} else if path == "/subscriptions" {
+		s.writePage(w, r, s.ResourceManager.URL, "nextLink", s.index.subscriptions)
+	} else if match := subscriptionPath.FindStringSubmatch(path); match != nil {
+		if subscription, ok := s.index.subscriptionById[strings.ToLower(match[1])]; ok {
+			writeJSON(w, subscription)
+		} else {
+			writeError(w, http.StatusNotFound, "SubscriptionNotFound", "subscription not found")
+		}
+	} else if match := resourceGroupsPath.FindStringSubmatch(path); match != nil {
+		s.writePage(w, r, s.ResourceManager.URL, "nextLink", s.index.resourceGroups[strings.ToLower(match[1])])
+	} else if match := vmsPath.FindStringSubmatch(path); match != nil {
+		s.writePage(w, r, s.ResourceManager.URL, "nextLink", s.index.virtualMachines[strings.ToLower(match[1])])
+	} else if match := keyVaultsPath.FindStringSubmatch(path); match != nil {
+		s.writePage(w, r, s.ResourceManager.URL, "nextLink", s.index.keyVaults[strings.ToLower(match[1])])
+	} else if match := roleAssignmentPath.FindStringSubmatch(path); match != nil {
+		s.writePage(w, r, s.ResourceManager.URL, "nextLink", s.index.scopeRoleAssignments(match[1]))
+	} else {
+		// e.g. management groups, which are not part of the fixture
+		writeJSON(w, map[string]interface{}{"value": []interface{}{}})
+	}
+}
+
+// writePage writes the page of items requested by the $skiptoken and $top query parameters along with a link to the
+// next page, if any
+func (s *Server) writePage(w http.ResponseWriter, r *http.Request, baseUrl, nextLinkKey string, items []interface{}) {
+	var (
+		query   = r.URL.Query()
+		skip, _ = strconv.Atoi(query.Get("$skiptoken"))
+		size    = s.PageSize
+	)
+
+	if top, err := strconv.Atoi(query.Get("$top")); err == nil && top > 0 && top < size {
+		size = top
+	}
+
+	if items == nil {
+		items = []interface{}{}
+	}
+
+	if skip > len(items) {
+		skip = len(items)
+	}
+
+	end := skip + size
+	if end > len(items) {
+		end = len(items)
+	}
+
+	page := map[string]interface{}{"value": items[skip:end]}
+	if end < len(items) {
+		query.Set("$skiptoken", strconv.Itoa(end))
+		page[nextLinkKey] = baseUrl + r.URL.Path + "?" + query.Encode()
+	}
+	writeJSON(w, page)
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+func writeOAuthError(w http.ResponseWriter, code, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":             code,
+		"error_description": description,
+	})
+}
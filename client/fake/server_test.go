@@ -0,0 +1,124 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package fake
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client"
+)
+
+func newTestServer(t *testing.T) *Server {
+	tenant, err := ReadTenant("testdata/tenant.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer(tenant)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestServerPaging(t *testing.T) {
+	server := newTestServer(t)
+	server.PageSize = 2
+
+	azClient, err := client.NewClient(server.Config())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if tenant := azClient.TenantInfo(); tenant.TenantId != server.Tenant.TenantId {
+		t.Errorf("got tenant %v, want %v", tenant.TenantId, server.Tenant.TenantId)
+	}
+
+	var users []string
+	for result := range azClient.ListAzureADUsers(context.Background(), "", "", "", nil) {
+		if result.Error != nil {
+			t.Fatalf("unexpected error: %v", result.Error)
+		}
+		users = append(users, result.Ok.DisplayName)
+	}
+
+	if len(users) != len(server.Tenant.Users) {
+		t.Errorf("got %v users, want %v", users, len(server.Tenant.Users))
+	} else if requests := server.Requests("/v1.0/users"); requests != 3 {
+		t.Errorf("got %v page requests, want %v", requests, 3)
+	}
+
+	subscriptionId := server.Tenant.Subscriptions[0].SubscriptionId
+	var vms int
+	for result := range azClient.ListAzureVirtualMachines(context.Background(), subscriptionId, false) {
+		if result.Error != nil {
+			t.Fatalf("unexpected error: %v", result.Error)
+		}
+		vms++
+
+		// the subscription's role assignment is inherited by every vm
+		var assignments int
+		for result := range azClient.ListRoleAssignmentsForResource(context.Background(), result.Ok.Id, "") {
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+			assignments++
+		}
+
+		if want := map[string]int{"web-1": 2, "web-2": 1}[result.Ok.Name]; assignments != want {
+			t.Errorf("got %v role assignments for %v, want %v", assignments, result.Ok.Name, want)
+		}
+	}
+
+	if vms != len(server.Tenant.VirtualMachines) {
+		t.Errorf("got %v virtual machines, want %v", vms, len(server.Tenant.VirtualMachines))
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	server := newTestServer(t)
+	azClient, err := client.NewClient(server.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// throttled requests are retried
+	server.Inject(Fault{Path: regexp.MustCompile(`^/subscriptions$`), StatusCode: http.StatusTooManyRequests, RetryAfter: 1, Times: 1})
+	if result, err := azClient.GetAzureSubscriptions(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if len(result.Value) != len(server.Tenant.Subscriptions) {
+		t.Errorf("got %v subscriptions, want %v", len(result.Value), len(server.Tenant.Subscriptions))
+	} else if requests := server.Requests("/subscriptions"); requests != 2 {
+		t.Errorf("got %v requests, want %v", requests, 2)
+	}
+
+	server.Inject(Fault{Path: regexp.MustCompile(`^/v1.0/groups$`), StatusCode: http.StatusForbidden, Code: "Authorization_RequestDenied"})
+	for result := range azClient.ListAzureADGroups(context.Background(), "", "", "", "", nil) {
+		if result.Error == nil {
+			t.Errorf("expected an error for a forbidden request")
+		}
+	}
+}
+
+func TestServerAuthentication(t *testing.T) {
+	server := newTestServer(t)
+	config := server.Config()
+	config.ClientSecret = "wrong"
+
+	if _, err := client.NewClient(config); err == nil {
+		t.Errorf("expected an error for an invalid client secret")
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/bloodhoundad/azurehound/models/azure"
+)
+
+// Tenant declares the objects served by a fake tenant. Azure Resource Manager resources belong to the subscription
+// and resource group in their id, and role assignments apply to their scope and every resource beneath it.
+type Tenant struct {
+	TenantId          string                   `json:"tenantId"`
+	DisplayName       string                   `json:"displayName"`
+	Users             []azure.User             `json:"users,omitempty"`
+	Groups            []azure.Group            `json:"groups,omitempty"`
+	ServicePrincipals []azure.ServicePrincipal `json:"servicePrincipals,omitempty"`
+	Subscriptions     []azure.Subscription     `json:"subscriptions,omitempty"`
+	ResourceGroups    []azure.ResourceGroup    `json:"resourceGroups,omitempty"`
+	VirtualMachines   []azure.VirtualMachine   `json:"virtualMachines,omitempty"`
+	KeyVaults         []azure.KeyVault         `json:"keyVaults,omitempty"`
+	RoleAssignments   []azure.RoleAssignment   `json:"roleAssignments,omitempty"`
+	GroupMembers      map[string][]string      `json:"groupMembers,omitempty"` // The ids of the members of each group
+	Owners            map[string][]string      `json:"owners,omitempty"`       // The ids of the owners of each group and service principal
+}
+
+// ReadTenant reads a tenant fixture from a JSON file
+func ReadTenant(path string) (Tenant, error) {
+	var tenant Tenant
+	if content, err := os.ReadFile(path); err != nil {
+		return tenant, err
+	} else if err := json.Unmarshal(content, &tenant); err != nil {
+		return tenant, fmt.Errorf("unable to parse tenant fixture %s: %w", path, err)
+	} else {
+		return tenant, nil
+	}
+}
+
+func (s Tenant) organization() azure.Organization {
+	org := azure.Organization{DisplayName: s.DisplayName}
+	org.Id = s.TenantId
+	return org
+}
+
+func (s Tenant) tenant() azure.Tenant {
+	return azure.Tenant{
+		DisplayName: s.DisplayName,
+		Id:          "/tenants/" + s.TenantId,
+		TenantId:    s.TenantId,
+	}
+}
@@ -19,8 +19,8 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"net/url"
 	"strings"
 
 	"github.com/bloodhoundad/azurehound/client/query"
@@ -98,168 +98,52 @@ func (s *azureClient) GetAzureADGroups(ctx context.Context, filter, search, orde
 }
 
 func (s *azureClient) ListAzureADGroups(ctx context.Context, filter, search, orderBy, expand string, selectCols []string) <-chan azure.GroupResult {
-	out := make(chan azure.GroupResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.GroupResult{}
-			nextLink  string
-		)
-
-		if list, err := s.GetAzureADGroups(ctx, filter, search, orderBy, expand, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.GroupResult{Ok: u}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.GroupList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.GroupResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
+	return paginate(ctx, s.msgraph,
+		func() ([]azure.Group, string, error) {
+			list, err := s.GetAzureADGroups(ctx, filter, search, orderBy, expand, selectCols, 999, false)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.Group]) azure.GroupResult {
+			return azure.GroupResult{
+				Error: result.Error,
+				Ok:    result.Ok,
+				Page:  result.Page,
 			}
-		}
-	}()
-	return out
+		},
+	)
 }
 
 func (s *azureClient) ListAzureADGroupOwners(ctx context.Context, objectId string, filter, search, orderBy string, selectCols []string) <-chan azure.GroupOwnerResult {
-	out := make(chan azure.GroupOwnerResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.GroupOwnerResult{}
-			nextLink  string
-		)
-
-		if list, err := s.GetAzureADGroupOwners(ctx, objectId, filter, search, orderBy, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.GroupOwnerResult{
-					GroupId: objectId,
-					Ok:      u,
-				}
+	return paginate(ctx, s.msgraph,
+		func() ([]json.RawMessage, string, error) {
+			list, err := s.GetAzureADGroupOwners(ctx, objectId, filter, search, orderBy, selectCols, 999, false)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[json.RawMessage]) azure.GroupOwnerResult {
+			return azure.GroupOwnerResult{
+				GroupId: objectId,
+				Error:   result.Error,
+				Ok:      result.Ok,
+				Page:    result.Page,
 			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.DirectoryObjectList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.GroupOwnerResult{
-							GroupId: objectId,
-							Ok:      u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
 
 func (s *azureClient) ListAzureADGroupMembers(ctx context.Context, objectId string, filter, search, orderBy string, selectCols []string) <-chan azure.MemberObjectResult {
-	out := make(chan azure.MemberObjectResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.MemberObjectResult{
+	return paginate(ctx, s.msgraph,
+		func() ([]json.RawMessage, string, error) {
+			list, err := s.GetAzureADGroupMembers(ctx, objectId, filter, search, false)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[json.RawMessage]) azure.MemberObjectResult {
+			return azure.MemberObjectResult{
 				ParentId:   objectId,
 				ParentType: string(enums.EntityGroup),
+				Error:      result.Error,
+				Ok:         result.Ok,
+				Page:       result.Page,
 			}
-			nextLink string
-		)
-
-		if list, err := s.GetAzureADGroupMembers(ctx, objectId, filter, search, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.MemberObjectResult{
-					ParentId:   objectId,
-					ParentType: string(enums.EntityGroup),
-					Ok:         u,
-				}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.MemberObjectList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.MemberObjectResult{
-							ParentId:   objectId,
-							ParentType: string(enums.EntityGroup),
-							Ok:         u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
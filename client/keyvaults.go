@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/bloodhoundad/azurehound/client/query"
 	"github.com/bloodhoundad/azurehound/client/rest"
@@ -61,59 +60,18 @@ func (s *azureClient) GetAzureKeyVaults(ctx context.Context, subscriptionId stri
 }
 
 func (s *azureClient) ListAzureKeyVaults(ctx context.Context, subscriptionId string, top int32) <-chan azure.KeyVaultResult {
-	out := make(chan azure.KeyVaultResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.KeyVaultResult{
+	return paginate(ctx, s.resourceManager,
+		func() ([]azure.KeyVault, string, error) {
+			list, err := s.GetAzureKeyVaults(ctx, subscriptionId, top)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.KeyVault]) azure.KeyVaultResult {
+			return azure.KeyVaultResult{
 				SubscriptionId: subscriptionId,
+				Error:          result.Error,
+				Ok:             result.Ok,
+				Page:           result.Page,
 			}
-			nextLink string
-		)
-
-		if result, err := s.GetAzureKeyVaults(ctx, subscriptionId, top); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range result.Value {
-				out <- azure.KeyVaultResult{
-					SubscriptionId: subscriptionId,
-					Ok:             u,
-				}
-			}
-
-			nextLink = result.NextLink
-			for nextLink != "" {
-				var list azure.KeyVaultList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.resourceManager.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.KeyVaultResult{
-							SubscriptionId: subscriptionId,
-							Ok:             u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/bloodhoundad/azurehound/client/query"
 	"github.com/bloodhoundad/azurehound/client/rest"
@@ -78,101 +77,33 @@ func (s *azureClient) GetAzureManagementGroupDescendants(ctx context.Context, gr
 }
 
 func (s *azureClient) ListAzureManagementGroups(ctx context.Context) <-chan azure.ManagementGroupResult {
-	out := make(chan azure.ManagementGroupResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.ManagementGroupResult{}
-			nextLink  string
-		)
-
-		if result, err := s.GetAzureManagementGroups(ctx); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range result.Value {
-				out <- azure.ManagementGroupResult{Ok: u}
+	return paginate(ctx, s.resourceManager,
+		func() ([]azure.ManagementGroup, string, error) {
+			list, err := s.GetAzureManagementGroups(ctx)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.ManagementGroup]) azure.ManagementGroupResult {
+			return azure.ManagementGroupResult{
+				Error: result.Error,
+				Ok:    result.Ok,
+				Page:  result.Page,
 			}
-
-			nextLink = result.NextLink
-			for nextLink != "" {
-				var list azure.ManagementGroupList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.resourceManager.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.ManagementGroupResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
 
 func (s *azureClient) ListAzureManagementGroupDescendants(ctx context.Context, groupId string) <-chan azure.DescendantInfoResult {
-	out := make(chan azure.DescendantInfoResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.DescendantInfoResult{}
-			nextLink  string
-		)
-
-		if result, err := s.GetAzureManagementGroupDescendants(ctx, groupId, 3000); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range result.Value {
-				out <- azure.DescendantInfoResult{Ok: u}
+	return paginate(ctx, s.resourceManager,
+		func() ([]azure.DescendantInfo, string, error) {
+			list, err := s.GetAzureManagementGroupDescendants(ctx, groupId, 3000)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.DescendantInfo]) azure.DescendantInfoResult {
+			return azure.DescendantInfoResult{
+				Error: result.Error,
+				Ok:    result.Ok,
+				Page:  result.Page,
 			}
-
-			nextLink = result.NextLink
-			for nextLink != "" {
-				var list azure.DescendantInfoList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.resourceManager.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.DescendantInfoResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
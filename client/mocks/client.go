@@ -518,6 +518,34 @@ func (mr *MockAzureClientMockRecorder) GetRoleAssignmentsForResource(arg0, arg1,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleAssignmentsForResource", reflect.TypeOf((*MockAzureClient)(nil).GetRoleAssignmentsForResource), arg0, arg1, arg2)
 }
 
+// HasGraphAccess mocks base method.
+func (m *MockAzureClient) HasGraphAccess() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasGraphAccess")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasGraphAccess indicates an expected call of HasGraphAccess.
+func (mr *MockAzureClientMockRecorder) HasGraphAccess() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasGraphAccess", reflect.TypeOf((*MockAzureClient)(nil).HasGraphAccess))
+}
+
+// HasResourceManagerAccess mocks base method.
+func (m *MockAzureClient) HasResourceManagerAccess() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasResourceManagerAccess")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasResourceManagerAccess indicates an expected call of HasResourceManagerAccess.
+func (mr *MockAzureClientMockRecorder) HasResourceManagerAccess() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasResourceManagerAccess", reflect.TypeOf((*MockAzureClient)(nil).HasResourceManagerAccess))
+}
+
 // ListAzureADAppMemberObjects mocks base method.
 func (m *MockAzureClient) ListAzureADAppMemberObjects(arg0 context.Context, arg1 string, arg2 bool) <-chan azure.MemberObjectResult {
 	m.ctrl.T.Helper()
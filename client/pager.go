@@ -0,0 +1,123 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/bloodhoundad/azurehound/client/rest"
+)
+
+// Page is a single page of a paginated list response. Microsoft Graph links to the next page with '@odata.nextLink'
+// whereas Azure Resource Manager uses 'nextLink'.
+type Page[T any] struct {
+	ODataNextLink string `json:"@odata.nextLink,omitempty"`
+	NextLink      string `json:"nextLink,omitempty"`
+	Value         []T    `json:"value"`
+}
+
+// Next returns the URL of the next page or an empty string if this is the last page
+func (s Page[T]) Next() string {
+	if s.ODataNextLink != "" {
+		return s.ODataNextLink
+	} else {
+		return s.NextLink
+	}
+}
+
+// PageResult is a single listed item, or the error that ended the listing, along with the number of the page the item
+// was listed from. The page of the last result is therefore the number of pages listed.
+type PageResult[T any] struct {
+	Error error
+	Ok    T
+	Page  int
+}
+
+// paginate streams every item of a paginated list endpoint. The first page is retrieved with first, returning the
+// page's items and its link to the next page, after which each next link is followed until the last page has been
+// listed or the context is done. Every item, and any error that ends the listing, is converted by wrap before being
+// sent downstream. A listing that is cut short by the context ends with the context's error so that it cannot be
+// mistaken for a complete one.
+func paginate[T any, R any](ctx context.Context, client rest.RestClient, first func() ([]T, string, error), wrap func(PageResult[T]) R) <-chan R {
+	out := make(chan R)
+
+	go func() {
+		defer close(out)
+
+		send := func(result PageResult[T]) bool {
+			select {
+			case out <- wrap(result):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// the error that ends the listing is sent even once the context is done since consumers read until the
+		// listing ends
+		fail := func(err error, page int) {
+			out <- wrap(PageResult[T]{Error: err, Page: page})
+		}
+
+		items, nextLink, err := first()
+		for page := 1; ; page++ {
+			if ctx.Err() != nil {
+				fail(ctx.Err(), page)
+				return
+			} else if err != nil {
+				fail(fmt.Errorf("unable to list page %d: %w", page, err), page)
+				return
+			}
+
+			for _, item := range items {
+				if !send(PageResult[T]{Ok: item, Page: page}) {
+					fail(ctx.Err(), page)
+					return
+				}
+			}
+
+			if nextLink == "" {
+				return
+			} else {
+				var next Page[T]
+				next, err = getPage[T](ctx, client, nextLink)
+				items, nextLink = next.Value, next.Next()
+			}
+		}
+	}()
+
+	return out
+}
+
+func getPage[T any](ctx context.Context, client rest.RestClient, nextLink string) (Page[T], error) {
+	var page Page[T]
+	if endpoint, err := url.Parse(nextLink); err != nil {
+		return page, err
+	} else if req, err := rest.NewRequest(ctx, http.MethodGet, endpoint, nil, nil, nil); err != nil {
+		return page, err
+	} else if res, err := client.Send(req); err != nil {
+		return page, err
+	} else if err := rest.Decode(res.Body, &page); err != nil {
+		return page, err
+	} else {
+		return page, nil
+	}
+}
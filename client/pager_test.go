@@ -0,0 +1,137 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/rest/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (s *trackedBody) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestPaginate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	var (
+		mockClient = mocks.NewMockRestClient(ctrl)
+		page2      = &trackedBody{Reader: strings.NewReader(`{"@odata.nextLink": "https://graph.microsoft.com/v1.0/groups?$skiptoken=3", "value": ["c"]}`)}
+		page3      = &trackedBody{Reader: strings.NewReader(`{"nextLink": "", "value": ["d", "e"]}`)}
+	)
+
+	mockClient.EXPECT().Send(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: page2}, nil).Times(1)
+	mockClient.EXPECT().Send(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK, Body: page3}, nil).Times(1)
+
+	first := func() ([]string, string, error) {
+		return []string{"a", "b"}, "https://graph.microsoft.com/v1.0/groups?$skiptoken=2", nil
+	}
+
+	var (
+		items []string
+		pages int
+	)
+	for result := range paginate(ctx, mockClient, first, func(result PageResult[string]) PageResult[string] { return result }) {
+		if result.Error != nil {
+			t.Fatalf("unexpected error: %v", result.Error)
+		}
+		items = append(items, result.Ok)
+		pages = result.Page
+	}
+
+	if strings.Join(items, "") != "abcde" {
+		t.Errorf("got %v, want %v", items, []string{"a", "b", "c", "d", "e"})
+	}
+
+	if pages != 3 {
+		t.Errorf("got %v pages, want %v", pages, 3)
+	}
+
+	if !page2.closed || !page3.closed {
+		t.Errorf("expected all response bodies to be closed")
+	}
+}
+
+func TestPaginateError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	var (
+		mockClient = mocks.NewMockRestClient(ctrl)
+		mockError  = fmt.Errorf("I'm an error")
+	)
+
+	mockClient.EXPECT().Send(gomock.Any()).Return(nil, mockError).Times(1)
+
+	first := func() ([]string, string, error) {
+		return []string{"a"}, "https://management.azure.com/subscriptions?$skiptoken=2", nil
+	}
+
+	var results []PageResult[string]
+	for result := range paginate(ctx, mockClient, first, func(result PageResult[string]) PageResult[string] { return result }) {
+		results = append(results, result)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("got %v results, want %v", len(results), 2)
+	} else if results[1].Error == nil || results[1].Page != 2 {
+		t.Errorf("got %+v, want an error from page 2", results[1])
+	}
+}
+
+func TestPaginateCancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mockClient := mocks.NewMockRestClient(ctrl)
+
+	first := func() ([]string, string, error) {
+		return []string{"a", "b", "c"}, "https://graph.microsoft.com/v1.0/groups?$skiptoken=2", nil
+	}
+
+	results := paginate(ctx, mockClient, first, func(result PageResult[string]) PageResult[string] { return result })
+	<-results
+	cancel()
+
+	// The pager must stop without requesting the next page, ending the listing with the context's error
+	var last PageResult[string]
+	for result := range results {
+		last = result
+	}
+	if !errors.Is(last.Error, context.Canceled) || last.Page != 1 {
+		t.Errorf("got %+v, want the listing of page 1 to end with %v", last, context.Canceled)
+	}
+}
@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/bloodhoundad/azurehound/client/query"
 	"github.com/bloodhoundad/azurehound/client/rest"
@@ -61,58 +60,18 @@ func (s *azureClient) GetAzureResourceGroups(ctx context.Context, subscriptionId
 }
 
 func (s *azureClient) ListAzureResourceGroups(ctx context.Context, subscriptionId, filter string) <-chan azure.ResourceGroupResult {
-	out := make(chan azure.ResourceGroupResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			objectId  = fmt.Sprintf("/subscriptions/%s", subscriptionId)
-			errResult = azure.ResourceGroupResult{SubscriptionId: objectId}
-			nextLink  string
-		)
-
-		if result, err := s.GetAzureResourceGroups(ctx, subscriptionId, filter, 1000); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range result.Value {
-				out <- azure.ResourceGroupResult{
-					SubscriptionId: objectId,
-					Ok:             u,
-				}
+	return paginate(ctx, s.resourceManager,
+		func() ([]azure.ResourceGroup, string, error) {
+			list, err := s.GetAzureResourceGroups(ctx, subscriptionId, filter, 1000)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.ResourceGroup]) azure.ResourceGroupResult {
+			return azure.ResourceGroupResult{
+				SubscriptionId: "/subscriptions/" + subscriptionId,
+				Error:          result.Error,
+				Ok:             result.Ok,
+				Page:           result.Page,
 			}
-
-			nextLink = result.NextLink
-			for nextLink != "" {
-				var list azure.ResourceGroupList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.resourceManager.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.ResourceGroupResult{
-							SubscriptionId: objectId,
-							Ok:             u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
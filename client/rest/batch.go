@@ -0,0 +1,253 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// The maximum number of requests Microsoft Graph accepts in a single JSON batch
+	MaxBatchSize int = 20
+
+	// How long a request may wait for others to join its batch
+	DefaultBatchLinger time.Duration = 10 * time.Millisecond
+)
+
+type batchRequest struct {
+	Id      string            `json:"id"`
+	Method  string            `json:"method"`
+	Url     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+type batchResponse struct {
+	Id      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+type batchResult struct {
+	res *http.Response
+	err error
+}
+
+type batchItem struct {
+	ctx     context.Context
+	request batchRequest
+	retry   int
+	result  chan batchResult
+}
+
+// batcher coalesces independent Microsoft Graph GET requests into JSON batches of up to 20 requests. Callers block
+// until the response to their own request is available; requests that fail with a retryable status within an otherwise
+// successful batch are retried individually in a later batch.
+//
+// See https://docs.microsoft.com/en-us/graph/json-batching
+type batcher struct {
+	client  *restClient
+	linger  time.Duration
+	mutex   sync.Mutex
+	pending map[string][]*batchItem
+	timers  map[string]*time.Timer
+}
+
+func newBatcher(client *restClient, linger time.Duration) *batcher {
+	return &batcher{
+		client:  client,
+		linger:  linger,
+		pending: make(map[string][]*batchItem),
+		timers:  make(map[string]*time.Timer),
+	}
+}
+
+// Do enqueues the request and waits for its response. Microsoft Graph batches are scoped to an API version so the
+// request is batched with others for the same version.
+func (s *batcher) Do(req *http.Request) (*http.Response, error) {
+	version, path := splitVersion(req.URL.RequestURI())
+
+	item := &batchItem{
+		ctx: req.Context(),
+		request: batchRequest{
+			Method:  req.Method,
+			Url:     path,
+			Headers: make(map[string]string),
+		},
+		result: make(chan batchResult, 1),
+	}
+
+	for key := range req.Header {
+		switch http.CanonicalHeaderKey(key) {
+		case "Authorization", "User-Agent":
+		default:
+			item.request.Headers[key] = req.Header.Get(key)
+		}
+	}
+
+	s.enqueue(version, item)
+
+	select {
+	case result := <-item.result:
+		return result.res, result.err
+	case <-item.ctx.Done():
+		return nil, item.ctx.Err()
+	}
+}
+
+func (s *batcher) enqueue(version string, item *batchItem) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pending[version] = append(s.pending[version], item)
+	if len(s.pending[version]) >= MaxBatchSize {
+		s.flushLocked(version)
+	} else if _, ok := s.timers[version]; !ok {
+		s.timers[version] = time.AfterFunc(s.linger, func() {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			s.flushLocked(version)
+		})
+	}
+}
+
+// flushLocked sends the pending requests for the given version; the caller must hold the mutex
+func (s *batcher) flushLocked(version string) {
+	if timer, ok := s.timers[version]; ok {
+		timer.Stop()
+		delete(s.timers, version)
+	}
+
+	if items := s.pending[version]; len(items) > 0 {
+		delete(s.pending, version)
+		go s.send(version, items)
+	}
+}
+
+func (s *batcher) send(version string, items []*batchItem) {
+	var (
+		requests = make([]batchRequest, 0, len(items))
+		byId     = make(map[string]*batchItem, len(items))
+		body     struct {
+			Responses []batchResponse `json:"responses"`
+		}
+	)
+
+	for i, item := range items {
+		if item.ctx.Err() != nil {
+			// the caller has already given up on this request
+			continue
+		}
+		item.request.Id = strconv.Itoa(i + 1)
+		requests = append(requests, item.request)
+		byId[item.request.Id] = item
+	}
+
+	if len(requests) == 0 {
+		return
+	}
+
+	ctx, cancel := batchContext(items)
+	defer cancel()
+
+	path := fmt.Sprintf("%s/$batch", version)
+	if res, err := s.client.Post(ctx, path, map[string]interface{}{"requests": requests}, nil, nil); err != nil {
+		for _, item := range byId {
+			item.result <- batchResult{err: err}
+		}
+	} else if err := Decode(res.Body, &body); err != nil {
+		for _, item := range byId {
+			item.result <- batchResult{err: fmt.Errorf("unable to decode batch response: %w", err)}
+		}
+	} else {
+		for _, response := range body.Responses {
+			if item, ok := byId[response.Id]; ok {
+				delete(byId, response.Id)
+				s.handle(version, item, response)
+			}
+		}
+
+		for _, item := range byId {
+			item.result <- batchResult{err: fmt.Errorf("batch response is missing a response for request %s", item.request.Url)}
+		}
+	}
+}
+
+func (s *batcher) handle(version string, item *batchItem, response batchResponse) {
+	res := &http.Response{
+		Status:     fmt.Sprintf("%d %s", response.Status, http.StatusText(response.Status)),
+		StatusCode: response.Status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(bytes.NewReader(response.Body)),
+	}
+	for key, value := range response.Headers {
+		res.Header.Set(key, value)
+	}
+
+	if s.client.retry.ShouldRetry(item.retry, res, nil) {
+		delay := s.client.retry.Backoff(item.retry, res)
+		item.retry++
+		go func() {
+			// the caller stops waiting once its context is done, so the request is no longer retried either
+			if Sleep(item.ctx, delay) == nil {
+				s.enqueue(version, item)
+			}
+		}()
+	} else if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+		item.result <- batchResult{err: newAPIError(res)}
+	} else {
+		item.result <- batchResult{res: res}
+	}
+}
+
+// batchContext returns a context that is done once the context of every item is done, i.e. once no caller is waiting
+// for the batch any longer
+func batchContext(items []*batchItem) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for _, item := range items {
+			select {
+			case <-item.ctx.Done():
+			case <-ctx.Done():
+				return
+			}
+		}
+		cancel()
+	}()
+	return ctx, cancel
+}
+
+// splitVersion splits a Microsoft Graph request URI into its API version and the remaining path and query, e.g.
+// '/v1.0/groups?$top=1' becomes '/v1.0' and '/groups?$top=1'
+func splitVersion(requestUri string) (string, string) {
+	trimmed := strings.TrimPrefix(requestUri, "/")
+	if i := strings.Index(trimmed, "/"); i < 0 {
+		return "/" + trimmed, "/"
+	} else {
+		return "/" + trimmed[:i], trimmed[i:]
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSplitVersion(t *testing.T) {
+	if version, path := splitVersion("/v1.0/groups/1/members?$top=1"); version != "/v1.0" || path != "/groups/1/members?$top=1" {
+		t.Errorf("got %v and %v", version, path)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	var (
+		mutex     sync.Mutex
+		batches   int
+		throttled bool
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Requests []batchRequest `json:"requests"`
+		}
+
+		if r.URL.Path != "/v1.0/$batch" || r.Method != http.MethodPost {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		} else if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("unable to decode batch: %v", err)
+		} else if len(body.Requests) > MaxBatchSize {
+			t.Errorf("got %v requests, want at most %v", len(body.Requests), MaxBatchSize)
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		batches++
+
+		responses := []batchResponse{}
+		for _, req := range body.Requests {
+			switch req.Url {
+			case "/groups/throttled/members":
+				if !throttled {
+					throttled = true
+					responses = append(responses, batchResponse{Id: req.Id, Status: http.StatusTooManyRequests, Headers: map[string]string{"Retry-After": "0"}})
+					continue
+				}
+			case "/groups/missing/members":
+				responses = append(responses, batchResponse{Id: req.Id, Status: http.StatusNotFound, Body: json.RawMessage(`{"error": {"code": "Request_ResourceNotFound", "message": "not found"}}`)})
+				continue
+			}
+			responses = append(responses, batchResponse{Id: req.Id, Status: http.StatusOK, Body: json.RawMessage(fmt.Sprintf(`{"url": %q}`, req.Url))})
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"responses": responses})
+	}))
+	defer server.Close()
+
+	api, _ := url.Parse(server.URL)
+	client := &restClient{
+		api:   *api,
+		http:  server.Client(),
+		token: Token{accessToken: "token", expires: time.Now().Add(time.Hour)},
+		retry: RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond},
+	}
+	client.batch = newBatcher(client, 10*time.Millisecond)
+
+	var (
+		wg    sync.WaitGroup
+		paths = []string{"throttled", "missing"}
+	)
+	for i := 0; i < 45; i++ {
+		paths = append(paths, fmt.Sprint(i))
+	}
+
+	for _, id := range paths {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			var body struct {
+				Url string `json:"url"`
+			}
+
+			path := fmt.Sprintf("/v1.0/groups/%s/members", id)
+			if res, err := client.Get(context.Background(), path, nil, nil); id == "missing" {
+				if !IsNotFound(err) {
+					t.Errorf("got %v, want not found error", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if err := Decode(res.Body, &body); err != nil {
+				t.Errorf("unable to decode response: %v", err)
+			} else if body.Url != path[len("/v1.0"):] {
+				t.Errorf("got response for %v, want %v", body.Url, path)
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	if !throttled {
+		t.Errorf("expected throttled request to be retried")
+	}
+
+	if batches < 3 || batches >= len(paths) {
+		t.Errorf("got %v batches for %v requests", batches, len(paths))
+	}
+}
+
+func TestBatchCancel(t *testing.T) {
+	var (
+		mutex     sync.Mutex
+		batches   int
+		cancelled = make(chan struct{})
+		done      = make(chan struct{})
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the server only notices the client going away once the body has been read
+		io.Copy(io.Discard, r.Body)
+
+		mutex.Lock()
+		batches++
+		batch := batches
+		mutex.Unlock()
+
+		if batch == 1 {
+			// throttled until the caller gives up
+			json.NewEncoder(w).Encode(map[string]interface{}{"responses": []batchResponse{{Id: "1", Status: http.StatusTooManyRequests}}})
+		} else {
+			// held until the caller gives up, which should cancel the batch request
+			select {
+			case <-r.Context().Done():
+				close(cancelled)
+			case <-done:
+			}
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	api, _ := url.Parse(server.URL)
+	client := &restClient{
+		api:   *api,
+		http:  server.Client(),
+		token: Token{accessToken: "token", expires: time.Now().Add(time.Hour)},
+		retry: RetryPolicy{MaxRetries: 3, MinBackoff: 200 * time.Millisecond, MaxBackoff: 200 * time.Millisecond},
+	}
+	client.batch = newBatcher(client, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.Get(ctx, "/v1.0/groups/retried/members", nil, nil); err == nil {
+		t.Fatal("expected an error once the context is done")
+	}
+
+	// the retry would have been sent after the backoff had the caller still been waiting
+	time.Sleep(400 * time.Millisecond)
+	mutex.Lock()
+	if batches != 1 {
+		t.Errorf("got %v batches, want the request not to be retried once its context is done", batches)
+	}
+	mutex.Unlock()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.Get(ctx, "/v1.0/groups/held/members", nil, nil); err == nil {
+		t.Fatal("expected an error once the context is done")
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Error("expected the batch request to be cancelled once its caller's context is done")
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces credentials and tokens in recorded interactions
+const Redacted = "REDACTED"
+
+var (
+	// form values of token requests that are scrubbed before recording
+	scrubbedFormKeys = []string{"client_assertion", "client_secret", "code", "device_code", "password", "refresh_token"}
+
+	// JSON properties of token responses that are scrubbed before recording
+	scrubbedJSONKeys = []string{"access_token", "device_code", "id_token", "refresh_token", "user_code"}
+)
+
+// Interaction is a request and the response it received
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Cassette is a file of recorded interactions, one JSON object per line, that can be replayed to reproduce a
+// collection without access to the tenant. Authorization headers are never recorded and credentials and tokens are
+// scrubbed from request and response bodies.
+type Cassette struct {
+	path         string
+	mutex        sync.Mutex
+	file         *os.File      // The file interactions are appended to when recording
+	interactions []Interaction // The interactions loaded for replay
+	replayed     []bool
+}
+
+// CreateCassette truncates the file at path and returns a cassette that records interactions to it
+func CreateCassette(path string) (*Cassette, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	} else if file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0600); err != nil {
+		return nil, fmt.Errorf("unable to create cassette: %w", err)
+	} else {
+		return &Cassette{path: path, file: file}, nil
+	}
+}
+
+// LoadCassette reads the interactions recorded to the file at path so that they can be replayed
+func LoadCassette(path string) (*Cassette, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open cassette: %w", err)
+	}
+	defer file.Close()
+
+	var (
+		cassette = &Cassette{path: path}
+		scanner  = bufio.NewScanner(file)
+	)
+
+	// graph list responses may be several megabytes
+	scanner.Buffer(make([]byte, 64*1024), 256*1024*1024)
+	for scanner.Scan() {
+		var interaction Interaction
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		} else if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, fmt.Errorf("unable to parse cassette %s: %w", path, err)
+		} else {
+			cassette.interactions = append(cassette.interactions, interaction)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read cassette %s: %w", path, err)
+	} else {
+		cassette.replayed = make([]bool, len(cassette.interactions))
+		return cassette, nil
+	}
+}
+
+// Record appends the interaction to the cassette file
+func (s *Cassette) Record(interaction Interaction) error {
+	if s.file == nil {
+		return fmt.Errorf("cassette %s was loaded for replay and cannot be recorded to", s.path)
+	} else if data, err := json.Marshal(interaction); err != nil {
+		return err
+	} else {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		if _, err := s.file.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("unable to write to cassette %s: %w", s.path, err)
+		} else {
+			return nil
+		}
+	}
+}
+
+// Match returns the recorded response to the request. Collection is concurrent so requests are matched by method, URL
+// and body rather than by order; identical requests, e.g. retries, are answered in the order they were recorded and
+// the last response is repeated once all have been replayed.
+func (s *Cassette) Match(req RecordedRequest) (RecordedResponse, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var (
+		sameBody  = -1
+		sameUrl   = -1
+		lastMatch = -1
+	)
+
+	for i, interaction := range s.interactions {
+		if interaction.Request.Method != req.Method || interaction.Request.URL != req.URL {
+			continue
+		}
+
+		lastMatch = i
+		if s.replayed[i] {
+			continue
+		} else if interaction.Request.Body == req.Body {
+			sameBody = i
+			break
+		} else if sameUrl < 0 {
+			sameUrl = i
+		}
+	}
+
+	for _, i := range []int{sameBody, sameUrl, lastMatch} {
+		if i >= 0 {
+			s.replayed[i] = true
+			return s.interactions[i].Response, true
+		}
+	}
+	return RecordedResponse{}, false
+}
+
+// Cassettes are shared by every client using the same path so that the Microsoft Graph and Azure Resource Manager
+// clients record to, and replay from, the same file.
+var cassettes = struct {
+	sync.Mutex
+	recording map[string]*Cassette
+	replaying map[string]*Cassette
+}{recording: make(map[string]*Cassette), replaying: make(map[string]*Cassette)}
+
+func sharedCassette(path string, replay bool) (*Cassette, error) {
+	cassettes.Lock()
+	defer cassettes.Unlock()
+
+	if replay {
+		if cassette, ok := cassettes.replaying[path]; ok {
+			return cassette, nil
+		} else if cassette, err := LoadCassette(path); err != nil {
+			return nil, err
+		} else {
+			cassettes.replaying[path] = cassette
+			return cassette, nil
+		}
+	} else if cassette, ok := cassettes.recording[path]; ok {
+		return cassette, nil
+	} else if cassette, err := CreateCassette(path); err != nil {
+		return nil, err
+	} else {
+		cassettes.recording[path] = cassette
+		return cassette, nil
+	}
+}
+
+// NewRecordingTransport returns a transport that records every interaction sent through base to the cassette
+func NewRecordingTransport(base http.RoundTripper, cassette *Cassette) http.RoundTripper {
+	return recordingTransport{base, cassette}
+}
+
+type recordingTransport struct {
+	base     http.RoundTripper
+	cassette *Cassette
+}
+
+func (s recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if reqBody, err := readRequestBody(req); err != nil {
+		return nil, err
+	} else if res, err := s.base.RoundTrip(req); err != nil {
+		return nil, err
+	} else if resBody, err := io.ReadAll(res.Body); err != nil {
+		res.Body.Close()
+		return nil, err
+	} else {
+		res.Body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+		header := res.Header.Clone()
+		header.Del("Set-Cookie")
+
+		interaction := Interaction{
+			Request: scrubRequest(req, reqBody),
+			Response: RecordedResponse{
+				StatusCode: res.StatusCode,
+				Header:     header,
+				Body:       scrubJSON(resBody),
+			},
+		}
+
+		if err := s.cassette.Record(interaction); err != nil {
+			return nil, err
+		} else {
+			return res, nil
+		}
+	}
+}
+
+// NewReplayingTransport returns a transport that answers requests with the responses recorded to the cassette
+// instead of sending them
+func NewReplayingTransport(cassette *Cassette) http.RoundTripper {
+	return replayingTransport{cassette}
+}
+
+type replayingTransport struct {
+	cassette *Cassette
+}
+
+func (s replayingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if body, err := readRequestBody(req); err != nil {
+		return nil, err
+	} else if recorded, ok := s.cassette.Match(scrubRequest(req, body)); !ok {
+		return nil, fmt.Errorf("no recorded response for %s %s in cassette %s", req.Method, req.URL, s.cassette.path)
+	} else {
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        recorded.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       req,
+		}, nil
+	}
+}
+
+// readRequestBody returns the request body, leaving the body unread for the transport
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	} else if req.GetBody != nil {
+		if body, err := req.GetBody(); err != nil {
+			return nil, err
+		} else {
+			defer body.Close()
+			return io.ReadAll(body)
+		}
+	} else if body, err := io.ReadAll(req.Body); err != nil {
+		return nil, err
+	} else {
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		return body, nil
+	}
+}
+
+func scrubRequest(req *http.Request, body []byte) RecordedRequest {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Body:   string(body),
+	}
+
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			for _, key := range scrubbedFormKeys {
+				if form.Has(key) {
+					form.Set(key, Redacted)
+				}
+			}
+			recorded.Body = form.Encode()
+		}
+	}
+	return recorded
+}
+
+// scrubJSON redacts the tokens in a JSON object, e.g. a token response
+func scrubJSON(body []byte) string {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil {
+		return string(body)
+	}
+
+	var scrubbed bool
+	for _, key := range scrubbedJSONKeys {
+		if _, ok := object[key]; ok {
+			object[key], _ = json.Marshal(Redacted)
+			scrubbed = true
+		}
+	}
+
+	if !scrubbed {
+		return string(body)
+	} else if data, err := json.Marshal(object); err != nil {
+		return string(body)
+	} else {
+		return string(data)
+	}
+}
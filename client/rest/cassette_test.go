@@ -0,0 +1,115 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+)
+
+func TestCassetteRecordReplay(t *testing.T) {
+	var (
+		path  = filepath.Join(t.TempDir(), "collection.cassette")
+		pages int
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/oauth2/v2.0/token") {
+			fmt.Fprint(w, `{"access_token": "secret-access-token", "refresh_token": "secret-refresh-token", "expires_in": 3600}`)
+		} else if r.Header.Get("Authorization") != "Bearer secret-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		} else {
+			pages++
+			fmt.Fprintf(w, `{"value": [{"id": "page-%d"}]}`, pages)
+		}
+	}))
+
+	recorder, err := NewRestClient(server.URL, config.Config{
+		Authority:     server.URL,
+		ApplicationId: "app",
+		ClientSecret:  "secret-client-secret",
+		Tenant:        "contoso",
+		Record:        path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var recorded []string
+	for i := 0; i < 2; i++ {
+		if res, err := recorder.Get(context.Background(), "/v1.0/users", nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else {
+			body, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+			recorded = append(recorded, string(body))
+		}
+	}
+	server.Close()
+
+	if content, err := os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	} else {
+		for _, secret := range []string{"secret-access-token", "secret-refresh-token", "secret-client-secret"} {
+			if strings.Contains(string(content), secret) {
+				t.Errorf("cassette contains %v", secret)
+			}
+		}
+	}
+
+	// the server is gone so every response must come from the cassette
+	replayer, err := NewRestClient(server.URL, config.Config{
+		Authority: server.URL,
+		Tenant:    "contoso",
+		Replay:    path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if res, err := replayer.Get(context.Background(), "/v1.0/users", nil, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else {
+			body, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+
+			// identical requests are answered in order, repeating the last response
+			want := recorded[len(recorded)-1]
+			if i < len(recorded) {
+				want = recorded[i]
+			}
+			if string(body) != want {
+				t.Errorf("got %v, want %v", string(body), want)
+			}
+		}
+	}
+
+	if _, err := replayer.Get(context.Background(), "/v1.0/groups", nil, nil); err == nil {
+		t.Errorf("expected an error for a request that was not recorded")
+	}
+}
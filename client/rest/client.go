@@ -21,7 +21,6 @@ package rest
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -46,13 +45,20 @@ func NewRestClient(apiUrl string, config config.Config) (RestClient, error) {
 		return nil, err
 	} else if api, err := url.Parse(apiUrl); err != nil {
 		return nil, err
-	} else if http, err := NewHTTPClient(config.ProxyUrl); err != nil {
+	} else if http, err := NewHTTPClient(config.ProxyUrl, config.CACerts, config.TLSCert, config.TLSKey); err != nil {
+		return nil, err
+	} else if jwt, err := SelectJWT(config.JWT, apiUrl); err != nil {
 		return nil, err
 	} else {
+		rateLimit := config.MgmtRateLimit
+		if apiUrl == config.GraphUrl() {
+			rateLimit = config.GraphRateLimit
+		}
+
 		client := &restClient{
 			*api,
 			*auth,
-			config.JWT,
+			jwt,
 			config.ApplicationId,
 			config.ClientSecret,
 			config.ClientCert,
@@ -67,39 +73,170 @@ func NewRestClient(apiUrl string, config config.Config) (RestClient, error) {
 			Token{},
 			config.SubscriptionId,
 			config.MgmtGroupId,
+			RetryPolicy{
+				MaxRetries: config.MaxRetries,
+				MinBackoff: DefaultMinBackoff,
+				MaxBackoff: DefaultMaxBackoff,
+			},
+			nil,
+			hostLimiter(api.Host, rateLimit),
+			hostLimiter(auth.Host, config.AuthRateLimit),
+			sync.Mutex{},
+			sharedTokenCache(config.TokenCache, config.TokenCacheKey),
+			config.DeviceCode,
+			nil,
+			config.FederatedTokenFile,
+			config.RefreshTokenClientId,
+			config.SendCertChain,
+			config.Replay != "",
+		}
+		if config.ManagedIdentity {
+			if identity, err := newManagedIdentity(config.ManagedIdentityEndpoint); err != nil {
+				return nil, err
+			} else {
+				identity.bypassProxy(http)
+				client.managedIdentity = identity
+			}
+		}
+		if config.Replay != "" {
+			if cassette, err := sharedCassette(config.Replay, true); err != nil {
+				return nil, err
+			} else {
+				http.Transport = NewReplayingTransport(cassette)
+			}
+		} else if config.Record != "" {
+			if cassette, err := sharedCassette(config.Record, false); err != nil {
+				return nil, err
+			} else {
+				http.Transport = NewRecordingTransport(http.Transport, cassette)
+			}
+		}
+		if config.GraphBatch && apiUrl == config.GraphUrl() {
+			client.batch = newBatcher(client, DefaultBatchLinger)
 		}
 		return client, nil
 	}
 }
 
 type restClient struct {
-	api           url.URL
-	authUrl       url.URL
-	jwt           string
-	clientId      string
-	clientSecret  string
-	clientCert    string
-	clientKey     string
-	clientKeyPass string
-	username      string
-	password      string
-	http          *http.Client
-	mutex         sync.RWMutex
-	refreshToken  string
-	tenant        string
-	token         Token
-	subId         []string
-	mgmtGroupId   []string
+	api                  url.URL
+	authUrl              url.URL
+	jwt                  string
+	clientId             string
+	clientSecret         string
+	clientCert           string
+	clientKey            string
+	clientKeyPass        string
+	username             string
+	password             string
+	http                 *http.Client
+	mutex                sync.RWMutex
+	refreshToken         string
+	tenant               string
+	token                Token
+	subId                []string
+	mgmtGroupId          []string
+	retry                RetryPolicy
+	batch                *batcher
+	limiter              *RateLimiter
+	authLimiter          *RateLimiter
+	authMutex            sync.Mutex
+	tokenCache           *TokenCache
+	deviceCode           bool
+	managedIdentity      *managedIdentity
+	federatedTokenFile   string
+	refreshTokenClientId string
+	sendCertChain        bool
+	replay               bool
 }
 
+// Authenticate acquires a new access token. A valid token from the token cache is used if available, otherwise a
+// refresh token, if any was issued to a previous request or cached, is redeemed before falling back to the configured
+// credential.
 func (s *restClient) Authenticate() error {
-	var (
-		path         = url.URL{Path: fmt.Sprintf("/%s/oauth2/v2.0/token", s.tenant)}
-		endpoint     = s.authUrl.ResolveReference(&path)
-		defaultScope = url.URL{Path: "/.default"}
-		scope        = s.api.ResolveReference(&defaultScope)
-		body         = url.Values{}
-	)
+	key := s.tokenCacheKey()
+
+	if cached, ok, err := s.tokenCache.Get(key); err != nil {
+		return err
+	} else if ok && !cached.NeedsRefresh() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.token = cached
+		return nil
+	}
+
+	s.mutex.RLock()
+	refreshToken := s.token.refreshToken
+	s.mutex.RUnlock()
+
+	if refreshToken == "" && s.refreshToken == "" {
+		if cached, err := s.tokenCache.RefreshToken(key); err != nil {
+			return err
+		} else {
+			refreshToken = cached
+		}
+	}
+
+	if refreshToken != "" {
+		if err := s.requestToken(key, refreshTokenGrant(key.ClientId, refreshToken)); err == nil {
+			return nil
+		}
+		// the refresh token may have expired or been revoked; fall back to the configured credential
+	}
+
+	if s.managedIdentity != nil {
+		return s.authenticateManagedIdentity(key)
+	} else if s.deviceCode {
+		return s.authenticateDeviceCode(key)
+	} else if body, err := s.credentialGrant(); err != nil {
+		return err
+	} else {
+		return s.requestToken(key, body)
+	}
+}
+
+func (s *restClient) tokenEndpoint() *url.URL {
+	path := url.URL{Path: fmt.Sprintf("/%s/oauth2/v2.0/token", s.tenant)}
+	return s.authUrl.ResolveReference(&path)
+}
+
+func (s *restClient) tokenCacheKey() TokenCacheKey {
+	key := TokenCacheKey{
+		Tenant:   s.tenant,
+		ClientId: s.refreshClientId(),
+		Audience: s.api.String(),
+		Account:  s.username,
+	}
+
+	if s.managedIdentity != nil {
+		key.ClientId = s.clientId
+		key.Account = "managed identity"
+	} else if s.refreshToken == "" && s.clientId != "" && (s.clientSecret != "" || (s.clientCert != "" && s.clientKey != "") || s.federatedTokenFile != "") {
+		key.ClientId = s.clientId
+	}
+	return key
+}
+
+// refreshClientId returns the client that refresh tokens are redeemed by; refresh tokens imported from another
+// application's token cache are bound to that application's client id
+func (s *restClient) refreshClientId() string {
+	if s.refreshToken != "" && s.refreshTokenClientId != "" {
+		return s.refreshTokenClientId
+	} else {
+		return constants.AzPowerShellClientID
+	}
+}
+
+func refreshTokenGrant(clientId, refreshToken string) url.Values {
+	body := url.Values{}
+	body.Add("client_id", clientId)
+	body.Add("grant_type", "refresh_token")
+	body.Add("refresh_token", refreshToken)
+	return body
+}
+
+func (s *restClient) credentialGrant() (url.Values, error) {
+	body := url.Values{}
 
 	if s.clientId == "" {
 		body.Add("client_id", constants.AzPowerShellClientID)
@@ -107,18 +244,14 @@ func (s *restClient) Authenticate() error {
 		body.Add("client_id", s.clientId)
 	}
 
-	body.Add("scope", scope.ResolveReference(&defaultScope).String())
-
 	if s.refreshToken != "" {
-		body.Add("grant_type", "refresh_token")
-		body.Add("refresh_token", s.refreshToken)
-		body.Set("client_id", constants.AzPowerShellClientID)
+		return refreshTokenGrant(s.refreshClientId(), s.refreshToken), nil
 	} else if s.clientSecret != "" {
 		body.Add("grant_type", "client_credentials")
 		body.Add("client_secret", s.clientSecret)
 	} else if s.clientCert != "" && s.clientKey != "" {
-		if clientAssertion, err := NewClientAssertion(endpoint.String(), s.clientId, s.clientCert, s.clientKey, s.clientKeyPass); err != nil {
-			return err
+		if clientAssertion, err := NewClientAssertion(s.tokenEndpoint().String(), s.clientId, s.clientCert, s.clientKey, s.clientKeyPass, s.sendCertChain); err != nil {
+			return nil, err
 		} else {
 			body.Add("grant_type", "client_credentials")
 			body.Add("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
@@ -129,26 +262,98 @@ func (s *restClient) Authenticate() error {
 		body.Add("username", s.username)
 		body.Add("password", s.password)
 		body.Set("client_id", constants.AzPowerShellClientID)
+	} else if s.federatedTokenFile != "" {
+		// the token is re-read on every request because the issuer rotates it before it expires
+		if clientAssertion, err := ReadFederatedToken(s.federatedTokenFile); err != nil {
+			return nil, err
+		} else {
+			body.Add("grant_type", "client_credentials")
+			body.Add("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+			body.Add("client_assertion", clientAssertion)
+		}
 	} else {
-		return fmt.Errorf("unable to authenticate. no valid credential provided")
+		return nil, fmt.Errorf("unable to authenticate. no valid credential provided")
+	}
+	return body, nil
+}
+
+// requestToken redeems the grant for an access token to the client's API, caching the token
+func (s *restClient) requestToken(key TokenCacheKey, body url.Values) error {
+	var (
+		defaultScope = url.URL{Path: "/.default"}
+		scope        = s.api.ResolveReference(&defaultScope).String()
+		token        Token
+	)
+
+	if body.Get("client_secret") == "" && body.Get("client_assertion") == "" {
+		// public clients are only issued refresh tokens when asked for them
+		scope += " offline_access"
 	}
+	body.Set("scope", scope)
 
-	if req, err := NewRequest(context.Background(), "POST", endpoint, body, nil, nil); err != nil {
+	if req, err := NewRequest(context.Background(), http.MethodPost, s.tokenEndpoint(), body, nil, nil); err != nil {
 		return err
 	} else if res, err := s.send(req); err != nil {
 		return err
+	} else if err := Decode(res.Body, &token); err != nil {
+		return err
 	} else {
-		defer res.Body.Close()
-		s.mutex.Lock()
-		defer s.mutex.Unlock()
-		if err := json.NewDecoder(res.Body).Decode(&s.token); err != nil {
-			return err
-		} else {
-			return nil
+		if token.refreshToken == "" {
+			// the refresh token was not rotated
+			token.refreshToken = body.Get("refresh_token")
 		}
+
+		return s.setToken(key, token)
+	}
+}
+
+func (s *restClient) setToken(key TokenCacheKey, token Token) error {
+	s.mutex.Lock()
+	s.token = token
+	s.mutex.Unlock()
+
+	if err := s.tokenCache.Put(key, token); err != nil {
+		return fmt.Errorf("unable to cache token: %w", err)
+	} else {
+		return nil
 	}
 }
 
+// getToken returns a valid access token. An expired token is renewed by a single caller while the others wait for
+// the renewed token, and a token that is about to expire is renewed in the background.
+func (s *restClient) getToken() (Token, error) {
+	s.mutex.RLock()
+	token := s.token
+	s.mutex.RUnlock()
+
+	if token.IsExpired() {
+		s.authMutex.Lock()
+		defer s.authMutex.Unlock()
+
+		// another caller may have renewed the token while this one waited
+		s.mutex.RLock()
+		token = s.token
+		s.mutex.RUnlock()
+
+		if token.IsExpired() {
+			if err := s.Authenticate(); err != nil {
+				return token, err
+			}
+			s.mutex.RLock()
+			token = s.token
+			s.mutex.RUnlock()
+		}
+	} else if token.NeedsRefresh() && s.authMutex.TryLock() {
+		go func() {
+			defer s.authMutex.Unlock()
+			// on failure the token is renewed synchronously once it expires
+			s.Authenticate()
+		}()
+	}
+
+	return token, nil
+}
+
 func (s *restClient) Delete(ctx context.Context, path string, body interface{}, params, headers map[string]string) (*http.Response, error) {
 	endpoint := s.api.ResolveReference(&url.URL{Path: path})
 	if req, err := NewRequest(ctx, http.MethodDelete, endpoint, body, params, headers); err != nil {
@@ -162,6 +367,8 @@ func (s *restClient) Get(ctx context.Context, path string, params, headers map[s
 	endpoint := s.api.ResolveReference(&url.URL{Path: path})
 	if req, err := NewRequest(ctx, http.MethodGet, endpoint, nil, params, headers); err != nil {
 		return nil, err
+	} else if s.batch != nil {
+		return s.batch.Do(req)
 	} else {
 		return s.Send(req)
 	}
@@ -196,37 +403,53 @@ func (s *restClient) Put(ctx context.Context, path string, body interface{}, par
 
 func (s *restClient) Send(req *http.Request) (*http.Response, error) {
 	if s.jwt != "" {
-		if aud, err := ParseAud(s.jwt); err != nil {
-			return nil, err
-		} else if aud != s.api.String() {
-			return nil, fmt.Errorf("invalid audience")
-		}
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.jwt))
+	} else if s.replay {
+		// recorded responses are replayed without authenticating since their tokens were scrubbed
+	} else if token, err := s.getToken(); err != nil {
+		return nil, err
 	} else {
-		if s.token.IsExpired() {
-			if err := s.Authenticate(); err != nil {
-				return nil, err
-			}
-		}
-		req.Header.Set("Authorization", s.token.String())
+		req.Header.Set("Authorization", token.String())
 	}
 	return s.send(req)
 }
 
 func (s *restClient) send(req *http.Request) (*http.Response, error) {
-	res, err := s.http.Do(req)
-	if err != nil {
-		return nil, err
-	}
+	for retry := 0; ; retry++ {
+		if retry > 0 && req.GetBody != nil {
+			// the previous attempt consumed the body
+			if body, err := req.GetBody(); err != nil {
+				return nil, err
+			} else {
+				req.Body = body
+			}
+		}
 
-	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
-		var errRes map[string]interface{}
-		if err := Decode(res.Body, &errRes); err != nil {
-			return nil, fmt.Errorf("malformed error response, status code: %d", res.StatusCode)
+		limiter := s.limiter
+		if req.URL.Host == s.authUrl.Host {
+			limiter = s.authLimiter
+		}
+		if err := limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		res, err := s.http.Do(req)
+		if s.retry.ShouldRetry(retry, res, err) {
+			if res != nil {
+				drain(res.Body)
+			}
+			if err := Sleep(req.Context(), s.retry.Backoff(retry, res)); err != nil {
+				return nil, err
+			}
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+			return nil, newAPIError(res)
 		} else {
-			return nil, fmt.Errorf("Error: %v", errRes)
+			return res, nil
 		}
-	} else {
-		return res, nil
 	}
 }
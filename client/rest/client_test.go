@@ -0,0 +1,64 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFederatedTokenFile(t *testing.T) {
+	var (
+		tokenFile = filepath.Join(t.TempDir(), "token")
+		client    = &restClient{clientId: "workload", tenant: "contoso", federatedTokenFile: tokenFile}
+	)
+
+	for _, token := range []string{"first", "second"} {
+		// the token is rotated by its issuer between requests
+		if err := os.WriteFile(tokenFile, []byte(token+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		} else if body, err := client.credentialGrant(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else if body.Get("grant_type") != "client_credentials" || body.Get("client_id") != "workload" {
+			t.Errorf("got %v grant for %v", body.Get("grant_type"), body.Get("client_id"))
+		} else if body.Get("client_assertion_type") != "urn:ietf:params:oauth:client-assertion-type:jwt-bearer" {
+			t.Errorf("got assertion type %v", body.Get("client_assertion_type"))
+		} else if body.Get("client_assertion") != token {
+			t.Errorf("got assertion %v, want %v", body.Get("client_assertion"), token)
+		}
+	}
+
+	if key := client.tokenCacheKey(); key.ClientId != "workload" {
+		t.Errorf("got cache key client id %v, want %v", key.ClientId, "workload")
+	}
+
+	// explicitly configured credentials take precedence over a federated token
+	client.username, client.password = "someone@contoso.com", "password"
+	if body, err := client.credentialGrant(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if body.Get("grant_type") != "password" {
+		t.Errorf("got %v grant, want the user's password grant", body.Get("grant_type"))
+	}
+	client.username, client.password = "", ""
+
+	os.Remove(tokenFile)
+	if _, err := client.credentialGrant(); err == nil {
+		t.Errorf("expected an error for a missing token file")
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/constants"
+)
+
+// DeviceCodeWriter is where the device code flow instructs the user to sign in
+var DeviceCodeWriter io.Writer = os.Stderr
+
+// Only one client at a time may ask the user to sign in. The other clients wait and then redeem the refresh token
+// issued to the first.
+var deviceCodeMutex sync.Mutex
+
+type deviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationUri string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+	Message         string `json:"message"`
+}
+
+// authenticateDeviceCode signs the user in using the OAuth 2.0 device authorization grant.
+//
+// See https://docs.microsoft.com/en-us/azure/active-directory/develop/v2-oauth2-device-code
+func (s *restClient) authenticateDeviceCode(key TokenCacheKey) error {
+	deviceCodeMutex.Lock()
+	defer deviceCodeMutex.Unlock()
+
+	// another client may have signed the user in while this one waited
+	if refreshToken, err := s.tokenCache.RefreshToken(key); err != nil {
+		return err
+	} else if refreshToken != "" {
+		if err := s.requestToken(key, refreshTokenGrant(key.ClientId, refreshToken)); err == nil {
+			return nil
+		}
+	}
+
+	var (
+		ctx          = context.Background()
+		path         = url.URL{Path: fmt.Sprintf("/%s/oauth2/v2.0/devicecode", s.tenant)}
+		endpoint     = s.authUrl.ResolveReference(&path)
+		defaultScope = url.URL{Path: "/.default"}
+		body         = url.Values{}
+		code         deviceCode
+	)
+
+	body.Add("client_id", constants.AzPowerShellClientID)
+	body.Add("scope", s.api.ResolveReference(&defaultScope).String()+" offline_access")
+
+	if req, err := NewRequest(ctx, http.MethodPost, endpoint, body, nil, nil); err != nil {
+		return err
+	} else if res, err := s.send(req); err != nil {
+		return fmt.Errorf("unable to request device code: %w", err)
+	} else if err := Decode(res.Body, &code); err != nil {
+		return fmt.Errorf("unable to decode device code: %w", err)
+	}
+
+	if code.Message != "" {
+		fmt.Fprintln(DeviceCodeWriter, code.Message)
+	} else {
+		fmt.Fprintf(DeviceCodeWriter, "To sign in, use a web browser to open the page %s and enter the code %s to authenticate.\n", code.VerificationUri, code.UserCode)
+	}
+
+	var (
+		deadline = time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+		interval = time.Duration(code.Interval) * time.Second
+	)
+
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	for time.Now().Before(deadline) {
+		if err := Sleep(ctx, interval); err != nil {
+			return err
+		}
+
+		grant := url.Values{}
+		grant.Add("client_id", constants.AzPowerShellClientID)
+		grant.Add("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+		grant.Add("device_code", code.DeviceCode)
+
+		var apiErr APIError
+		if err := s.requestToken(key, grant); err == nil {
+			return nil
+		} else if !errors.As(err, &apiErr) {
+			return err
+		} else if apiErr.Code == "authorization_pending" {
+			continue
+		} else if apiErr.Code == "slow_down" {
+			interval += 5 * time.Second
+		} else {
+			return fmt.Errorf("device code authentication failed: %w", err)
+		}
+	}
+
+	return fmt.Errorf("device code authentication failed: the device code expired before the user signed in")
+}
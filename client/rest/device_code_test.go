@@ -0,0 +1,110 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+)
+
+func TestDeviceCode(t *testing.T) {
+	var (
+		deviceCodes int
+		polls       int
+		refreshes   int
+		prompt      bytes.Buffer
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch {
+		case r.URL.Path == "/contoso/oauth2/v2.0/devicecode":
+			deviceCodes++
+			if !strings.Contains(r.Form.Get("scope"), "offline_access") {
+				t.Errorf("expected a refresh token to be requested")
+			}
+			fmt.Fprint(w, `{"device_code": "device", "user_code": "ABC123", "verification_uri": "https://microsoft.com/devicelogin", "expires_in": 60, "interval": 1, "message": "enter ABC123"}`)
+		case r.Form.Get("grant_type") == "urn:ietf:params:oauth:grant-type:device_code":
+			if polls++; polls == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": "authorization_pending", "error_description": "pending"}`)
+			} else {
+				fmt.Fprint(w, `{"access_token": "graph", "refresh_token": "refresh", "expires_in": 3600}`)
+			}
+		case r.Form.Get("grant_type") == "refresh_token":
+			refreshes++
+			if r.Form.Get("refresh_token") != "refresh" {
+				t.Errorf("got refresh token %v, want %v", r.Form.Get("refresh_token"), "refresh")
+			}
+			fmt.Fprint(w, `{"access_token": "arm", "refresh_token": "rotated", "expires_in": 3600}`)
+		}
+	}))
+	defer server.Close()
+
+	DeviceCodeWriter = &prompt
+	defer func() { DeviceCodeWriter = os.Stderr }()
+	cfg := config.Config{
+		Authority:     server.URL,
+		DeviceCode:    true,
+		Tenant:        "contoso",
+		TokenCache:    filepath.Join(t.TempDir(), "tokens"),
+		TokenCacheKey: "passphrase",
+	}
+
+	if graph, err := NewRestClient(server.URL+"/graph", cfg); err != nil {
+		t.Fatal(err)
+	} else if err := graph.Authenticate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if arm, err := NewRestClient(server.URL+"/arm", cfg); err != nil {
+		t.Fatal(err)
+	} else if err := arm.Authenticate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(prompt.String(), "ABC123") {
+		t.Errorf("got prompt %q, want the user code", prompt.String())
+	}
+
+	// the second client redeems the refresh token issued to the first instead of signing the user in again
+	if deviceCodes != 1 || polls != 2 || refreshes != 1 {
+		t.Errorf("got %v device codes, %v polls and %v refreshes", deviceCodes, polls, refreshes)
+	}
+
+	// later runs reuse the cached tokens
+	tokenCaches.Lock()
+	delete(tokenCaches.byPath, cfg.TokenCache)
+	tokenCaches.Unlock()
+
+	if client, err := NewRestClient(server.URL+"/arm", cfg); err != nil {
+		t.Fatal(err)
+	} else if token, err := client.(*restClient).getToken(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if token.accessToken != "arm" || token.refreshToken != "rotated" {
+		t.Errorf("got %+v, want the cached token", token)
+	} else if deviceCodes != 1 || refreshes != 1 {
+		t.Errorf("expected the cached token to be used")
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/bloodhoundad/azurehound/models/azure"
+)
+
+// Well-known error codes returned by Microsoft Graph and Azure Resource Manager
+const (
+	ErrCodeAuthorizationFailed        string = "AuthorizationFailed"
+	ErrCodeAuthorizationRequestDenied string = "Authorization_RequestDenied"
+	ErrCodeRequestResourceNotFound    string = "Request_ResourceNotFound"
+	ErrCodeResourceNotFound           string = "ResourceNotFound"
+	ErrCodeResourceGroupNotFound      string = "ResourceGroupNotFound"
+	ErrCodeSubscriptionNotFound       string = "SubscriptionNotFound"
+	ErrCodeTooManyRequests            string = "TooManyRequests"
+	ErrCodeActivityLimitReached       string = "activityLimitReached"
+)
+
+// APIError is returned when Azure responds to a request with an unsuccessful status code
+type APIError struct {
+	StatusCode      int                // The HTTP status code of the response
+	Code            string             // The OData error code, e.g. Authorization_RequestDenied
+	Message         string             // The OData error message
+	RequestId       string             // The request-id assigned by Azure; useful when raising issues with Microsoft
+	ClientRequestId string             // The client-request-id that Azure correlated with the request
+	InnerError      *azure.ODataError  // The inner error, if provided
+	Details         []azure.ODataError // Additional errors, e.g. per-field validation failures
+}
+
+func (s APIError) Error() string {
+	msg := fmt.Sprintf("azure api error: status code %d", s.StatusCode)
+	if s.Code != "" {
+		msg += fmt.Sprintf(", code %s", s.Code)
+	}
+	if s.Message != "" {
+		msg += fmt.Sprintf(": %s", s.Message)
+	}
+	if s.RequestId != "" {
+		msg += fmt.Sprintf(" (request-id: %s)", s.RequestId)
+	}
+	return msg
+}
+
+// IsNotFound reports whether the requested object does not exist, for example because it was deleted after being
+// listed
+func (s APIError) IsNotFound() bool {
+	switch s.Code {
+	case ErrCodeRequestResourceNotFound, ErrCodeResourceNotFound, ErrCodeResourceGroupNotFound, ErrCodeSubscriptionNotFound:
+		return true
+	default:
+		return s.StatusCode == http.StatusNotFound
+	}
+}
+
+// IsForbidden reports whether the credential lacks permission to read the requested object
+func (s APIError) IsForbidden() bool {
+	switch s.Code {
+	case ErrCodeAuthorizationRequestDenied, ErrCodeAuthorizationFailed:
+		return true
+	default:
+		return s.StatusCode == http.StatusForbidden
+	}
+}
+
+// IsThrottled reports whether the request was rejected by Azure's rate limits
+func (s APIError) IsThrottled() bool {
+	switch s.Code {
+	case ErrCodeTooManyRequests, ErrCodeActivityLimitReached:
+		return true
+	default:
+		return s.StatusCode == http.StatusTooManyRequests
+	}
+}
+
+func IsNotFound(err error) bool {
+	var apiErr APIError
+	return errors.As(err, &apiErr) && apiErr.IsNotFound()
+}
+
+func IsForbidden(err error) bool {
+	var apiErr APIError
+	return errors.As(err, &apiErr) && apiErr.IsForbidden()
+}
+
+func IsThrottled(err error) bool {
+	var apiErr APIError
+	return errors.As(err, &apiErr) && apiErr.IsThrottled()
+}
+
+// newAPIError reads and closes the response body, returning the described error. Microsoft Graph and Azure Resource
+// Manager describe errors as OData errors while the Microsoft identity platform uses OAuth 2.0 error responses.
+func newAPIError(res *http.Response) error {
+	defer drain(res.Body)
+
+	apiErr := APIError{
+		StatusCode:      res.StatusCode,
+		RequestId:       firstHeader(res.Header, "request-id", "x-ms-request-id"),
+		ClientRequestId: firstHeader(res.Header, "client-request-id", "x-ms-client-request-id"),
+	}
+
+	var (
+		odataRes azure.ErrorResponse
+		oauthRes struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+			TraceId          string `json:"trace_id"`
+			CorrelationId    string `json:"correlation_id"`
+		}
+	)
+
+	if body, err := io.ReadAll(res.Body); err != nil || len(body) == 0 {
+		apiErr.Message = http.StatusText(res.StatusCode)
+	} else if err := json.Unmarshal(body, &odataRes); err == nil && odataRes.Error.Code != "" {
+		apiErr.Code = odataRes.Error.Code
+		apiErr.Message = odataRes.Error.Message
+		apiErr.InnerError = odataRes.Error.InnerError
+		apiErr.Details = odataRes.Error.Details
+	} else if err := json.Unmarshal(body, &oauthRes); err == nil && oauthRes.Error != "" {
+		apiErr.Code = oauthRes.Error
+		apiErr.Message = oauthRes.ErrorDescription
+		if apiErr.RequestId == "" {
+			apiErr.RequestId = oauthRes.TraceId
+		}
+		if apiErr.ClientRequestId == "" {
+			apiErr.ClientRequestId = oauthRes.CorrelationId
+		}
+	} else {
+		apiErr.Message = fmt.Sprintf("malformed error response: %s", http.StatusText(res.StatusCode))
+	}
+
+	return apiErr
+}
+
+func firstHeader(header http.Header, keys ...string) string {
+	for _, key := range keys {
+		if value := header.Get(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
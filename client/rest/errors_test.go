@@ -0,0 +1,97 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newErrorResponse(statusCode int, body string, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestNewAPIErrorOData(t *testing.T) {
+	header := http.Header{}
+	header.Set("request-id", "8a8e4b6c-8a6a-4b7e-9d3c-1ad4bc1ab1b9")
+	header.Set("client-request-id", "f3c6e6c2-5e7c-4ab1-b7a7-8e8d2d0f6f0e")
+	res := newErrorResponse(http.StatusForbidden, `{
+		"error": {
+			"code": "Authorization_RequestDenied",
+			"message": "Insufficient privileges to complete the operation.",
+			"innerError": {"code": "inner", "message": "inner message"}
+		}
+	}`, header)
+
+	err := fmt.Errorf("wrapped: %w", newAPIError(res))
+
+	var apiErr APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %T, want %T", err, APIError{})
+	} else if apiErr.Code != ErrCodeAuthorizationRequestDenied {
+		t.Errorf("got %v, want %v", apiErr.Code, ErrCodeAuthorizationRequestDenied)
+	} else if apiErr.RequestId != header.Get("request-id") {
+		t.Errorf("got %v, want %v", apiErr.RequestId, header.Get("request-id"))
+	} else if apiErr.ClientRequestId != header.Get("client-request-id") {
+		t.Errorf("got %v, want %v", apiErr.ClientRequestId, header.Get("client-request-id"))
+	} else if apiErr.InnerError == nil || apiErr.InnerError.Code != "inner" {
+		t.Errorf("got %v, want inner error", apiErr.InnerError)
+	}
+
+	if !IsForbidden(err) {
+		t.Errorf("expected forbidden error")
+	} else if IsNotFound(err) || IsThrottled(err) {
+		t.Errorf("unexpected error classification")
+	}
+}
+
+func TestNewAPIErrorOAuth(t *testing.T) {
+	res := newErrorResponse(http.StatusBadRequest, `{
+		"error": "invalid_grant",
+		"error_description": "AADSTS70000: The provided value for the 'code' parameter is not valid.",
+		"trace_id": "trace",
+		"correlation_id": "correlation"
+	}`, nil)
+
+	if apiErr, ok := newAPIError(res).(APIError); !ok {
+		t.Fatalf("got %T, want %T", apiErr, APIError{})
+	} else if apiErr.Code != "invalid_grant" {
+		t.Errorf("got %v, want %v", apiErr.Code, "invalid_grant")
+	} else if apiErr.RequestId != "trace" || apiErr.ClientRequestId != "correlation" {
+		t.Errorf("got %v and %v, want trace and correlation ids", apiErr.RequestId, apiErr.ClientRequestId)
+	}
+}
+
+func TestNewAPIErrorMalformed(t *testing.T) {
+	res := newErrorResponse(http.StatusNotFound, `<html>not found</html>`, nil)
+
+	if err := newAPIError(res); !IsNotFound(err) {
+		t.Errorf("got %v, want not found error", err)
+	}
+}
@@ -21,18 +21,24 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/bloodhoundad/azurehound/constants"
 )
 
-func NewHTTPClient(proxyUrl string) (*http.Client, error) {
+// NewHTTPClient creates a client for requests to Azure and BloodHound Enterprise. The root certificates in the caCerts
+// PEM files are trusted in addition to the system roots, e.g. those of an SSL inspecting proxy, and the client
+// certificate in the tlsCert and tlsKey PEM files is presented to proxies and servers that request one.
+func NewHTTPClient(proxyUrl string, caCerts []string, tlsCert, tlsKey string) (*http.Client, error) {
 	transport := http.DefaultTransport.(*http.Transport).Clone()
 	transport.MaxConnsPerHost = 200
 
@@ -41,6 +47,22 @@ func NewHTTPClient(proxyUrl string) (*http.Client, error) {
 		MinVersion: tls.VersionTLS12,
 	}
 
+	if len(caCerts) > 0 {
+		if pool, err := loadCertPool(caCerts); err != nil {
+			return nil, err
+		} else {
+			transport.TLSClientConfig.RootCAs = pool
+		}
+	}
+
+	if tlsCert != "" || tlsKey != "" {
+		if cert, err := tls.LoadX509KeyPair(tlsCert, tlsKey); err != nil {
+			return nil, fmt.Errorf("unable to load TLS client certificate: %w", err)
+		} else {
+			transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
 	// increasing timeout because tls handshakes can take longer when doing a lot of concurrent calls
 	transport.TLSHandshakeTimeout = 20 * time.Second
 	// increasing response header timeout to accout for WAF throttling rules
@@ -49,9 +71,9 @@ func NewHTTPClient(proxyUrl string) (*http.Client, error) {
 	// ignoring err; always nil
 	jar, _ := cookiejar.New(nil)
 
-	// setup forward proxy
+	// setup forward proxy; credentials in the url's userinfo are sent in the Proxy-Authorization header
 	if proxyUrl != "" {
-		if url, err := url.Parse(proxyUrl); err != nil {
+		if url, err := parseProxyUrl(proxyUrl); err != nil {
 			return nil, err
 		} else {
 			transport.Proxy = http.ProxyURL(url)
@@ -64,6 +86,40 @@ func NewHTTPClient(proxyUrl string) (*http.Client, error) {
 	}, nil
 }
 
+// loadCertPool returns the system root certificates along with those in the PEM files
+func loadCertPool(files []string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	for _, file := range files {
+		if content, err := os.ReadFile(file); err != nil {
+			return nil, fmt.Errorf("unable to read CA certificate: %w", err)
+		} else if !pool.AppendCertsFromPEM(content) {
+			return nil, fmt.Errorf("no PEM encoded certificates found in %s", file)
+		}
+	}
+	return pool, nil
+}
+
+// parseProxyUrl parses the proxy url, defaulting to the http scheme if the url has none, e.g. 'user:pass@proxy:8080'
+func parseProxyUrl(proxyUrl string) (*url.URL, error) {
+	if !strings.Contains(proxyUrl, "://") {
+		proxyUrl = "http://" + proxyUrl
+	}
+
+	if url, err := url.Parse(proxyUrl); err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %w", err)
+	} else if url.Scheme != "http" && url.Scheme != "https" && url.Scheme != "socks5" {
+		return nil, fmt.Errorf("unsupported proxy scheme: %s", url.Scheme)
+	} else if url.Host == "" {
+		return nil, fmt.Errorf("invalid proxy url: missing host")
+	} else {
+		return url, nil
+	}
+}
+
 func NewRequest(
 	ctx context.Context,
 	verb string,
@@ -0,0 +1,141 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self-signed certificate and its key to PEM files
+func writeTestCert(t *testing.T, dir string) (string, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "azurehound-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	var (
+		certFile = filepath.Join(dir, "client.pem")
+		keyFile  = filepath.Join(dir, "client.key")
+	)
+
+	if der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key); err != nil {
+		t.Fatal(err)
+	} else if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	} else if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestHTTPClientTLS(t *testing.T) {
+	var (
+		dir               = t.TempDir()
+		caFile            = filepath.Join(dir, "ca.pem")
+		certFile, keyFile = writeTestCert(t, dir)
+	)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			t.Errorf("expected a client certificate")
+		}
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the server's certificate is not trusted without the CA file
+	if client, err := NewHTTPClient("", nil, certFile, keyFile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := client.Get(server.URL); err == nil {
+		t.Errorf("expected an error for an untrusted certificate")
+	}
+
+	// the client certificate is required
+	if client, err := NewHTTPClient("", []string{caFile}, "", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := client.Get(server.URL); err == nil {
+		t.Errorf("expected an error without a client certificate")
+	}
+
+	if client, err := NewHTTPClient("", []string{caFile}, certFile, keyFile); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if res, err := client.Get(server.URL); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else {
+		res.Body.Close()
+	}
+
+	if _, err := NewHTTPClient("", []string{certFile + ".missing"}, "", ""); err == nil {
+		t.Errorf("expected an error for a missing CA file")
+	} else if _, err := NewHTTPClient("", []string{keyFile}, "", ""); err == nil {
+		t.Errorf("expected an error for a CA file without certificates")
+	}
+}
+
+func TestHTTPClientProxyAuthorization(t *testing.T) {
+	var authorization string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Proxy-Authorization")
+	}))
+	defer proxy.Close()
+
+	// the scheme defaults to http
+	proxyUrl := "us%40er:p%3Ass@" + strings.TrimPrefix(proxy.URL, "http://")
+	if client, err := NewHTTPClient(proxyUrl, nil, "", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if res, err := client.Get("http://graph.microsoft.com/v1.0/organization"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else {
+		res.Body.Close()
+	}
+
+	if want := "Basic " + base64.StdEncoding.EncodeToString([]byte("us@er:p:ss")); authorization != want {
+		t.Errorf("got %v, want %v", authorization, want)
+	}
+
+	if _, err := NewHTTPClient("ftp://proxy:21", nil, "", ""); err == nil {
+		t.Errorf("expected an error for an unsupported proxy scheme")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket that allows an average of rate requests per second with bursts of up to burst
+// requests.
+type RateLimiter struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewRateLimiter(rate int, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   float64(rate),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request may be sent or the context is done. Waiting requests are admitted in the order they
+// called Wait.
+func (s *RateLimiter) Wait(ctx context.Context) error {
+	if s == nil || s.rate <= 0 {
+		return nil
+	}
+
+	delay := s.reserve()
+	if err := Sleep(ctx, delay); err != nil {
+		s.cancel()
+		return err
+	} else {
+		return nil
+	}
+}
+
+// reserve takes a token from the bucket, allowing the bucket to go into debt, and returns how long the caller must
+// wait for the token to become available
+func (s *RateLimiter) reserve() time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.tokens += now.Sub(s.last).Seconds() * s.rate
+	if s.tokens > s.burst {
+		s.tokens = s.burst
+	}
+	s.last = now
+	s.tokens--
+
+	if s.tokens >= 0 {
+		return 0
+	} else {
+		return time.Duration(-s.tokens / s.rate * float64(time.Second))
+	}
+}
+
+// cancel returns a reserved token to the bucket
+func (s *RateLimiter) cancel() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.tokens++
+}
+
+// Limiters are shared by every client of the same host with the same rate so that, for example, the Microsoft Graph
+// and Azure Resource Manager clients share a single limit for the Microsoft identity platform, while a client
+// configured with a different rate is limited to that rate.
+var limiters = struct {
+	sync.Mutex
+	byHost map[hostRate]*RateLimiter
+}{byHost: make(map[hostRate]*RateLimiter)}
+
+type hostRate struct {
+	host string
+	rate int
+}
+
+// hostLimiter returns the limiter for host at the given rate, creating it if this is the first such client of the
+// host. A rate of zero or less disables rate limiting for the client.
+func hostLimiter(host string, rate int) *RateLimiter {
+	limiters.Lock()
+	defer limiters.Unlock()
+
+	key := hostRate{host, rate}
+	if rate <= 0 {
+		return nil
+	} else if limiter, ok := limiters.byHost[key]; ok {
+		return limiter
+	} else {
+		limiter := NewRateLimiter(rate, rate)
+		limiters.byHost[key] = limiter
+		return limiter
+	}
+}
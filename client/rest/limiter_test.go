@@ -0,0 +1,78 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	var (
+		limiter = NewRateLimiter(100, 10)
+		start   = time.Now()
+		wg      sync.WaitGroup
+	)
+
+	// 10 requests are admitted immediately and the remaining 20 at 100 per second
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond || elapsed > time.Second {
+		t.Errorf("got %v, want about %v", elapsed, 200*time.Millisecond)
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	limiter := NewRateLimiter(1, 1)
+	limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); err == nil {
+		t.Errorf("expected the wait to be cancelled")
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	var limiter *RateLimiter
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if hostLimiter("disabled.example.com", 0) != nil {
+		t.Errorf("expected no limiter for a rate of 0")
+	} else if hostLimiter("shared.example.com", 5) != hostLimiter("shared.example.com", 5) {
+		t.Errorf("expected clients of the same host and rate to share a limiter")
+	} else if limiter := hostLimiter("shared.example.com", 50); limiter == hostLimiter("shared.example.com", 5) {
+		t.Errorf("expected a client with a different rate to have its own limiter")
+	} else if limiter.rate != 50 {
+		t.Errorf("got rate %v, want %v", limiter.rate, 50)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+const (
+	// The Azure Instance Metadata Service token endpoint available to Azure VMs
+	DefaultIMDSEndpoint string = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+	imdsApiVersion       string = "2018-02-01"
+	appServiceApiVersion string = "2019-08-01"
+)
+
+// managedIdentity acquires tokens for the identity assigned to the Azure resource AzureHound runs on. Azure VMs are
+// served by the Instance Metadata Service while App Service and Container Apps set IDENTITY_ENDPOINT and
+// IDENTITY_HEADER.
+//
+// See https://docs.microsoft.com/en-us/azure/active-directory/managed-identities-azure-resources/how-to-use-vm-token
+// and https://docs.microsoft.com/en-us/azure/app-service/overview-managed-identity#rest-endpoint-reference
+type managedIdentity struct {
+	endpoint url.URL
+	header   string // The IDENTITY_HEADER secret, if the endpoint is an App Service identity endpoint
+}
+
+// newManagedIdentity resolves the identity endpoint, preferring the given endpoint, then IDENTITY_ENDPOINT and finally
+// the Instance Metadata Service
+func newManagedIdentity(endpoint string) (*managedIdentity, error) {
+	header := os.Getenv("IDENTITY_HEADER")
+	if endpoint == "" {
+		if endpoint = os.Getenv("IDENTITY_ENDPOINT"); endpoint == "" {
+			endpoint = DefaultIMDSEndpoint
+			header = ""
+		}
+	}
+
+	if parsed, err := url.Parse(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid managed identity endpoint: %w", err)
+	} else {
+		return &managedIdentity{endpoint: *parsed, header: header}, nil
+	}
+}
+
+// bypassProxy stops requests to the identity endpoint, which is only reachable from the host, from being forwarded to
+// a proxy
+func (s *managedIdentity) bypassProxy(client *http.Client) {
+	if transport, ok := client.Transport.(*http.Transport); ok && transport.Proxy != nil {
+		proxy := transport.Proxy
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if req.URL.Host == s.endpoint.Host {
+				return nil, nil
+			} else {
+				return proxy(req)
+			}
+		}
+	}
+}
+
+// authenticateManagedIdentity acquires a token for the system-assigned identity or, if the client has a client id,
+// the user-assigned identity with that client id
+func (s *restClient) authenticateManagedIdentity(key TokenCacheKey) error {
+	var (
+		endpoint = s.managedIdentity.endpoint
+		params   = map[string]string{"resource": s.api.String()}
+		headers  = map[string]string{}
+		token    Token
+	)
+
+	if s.clientId != "" {
+		params["client_id"] = s.clientId
+	}
+
+	if s.managedIdentity.header != "" {
+		params["api-version"] = appServiceApiVersion
+		headers["X-IDENTITY-HEADER"] = s.managedIdentity.header
+	} else {
+		params["api-version"] = imdsApiVersion
+		headers["Metadata"] = "true"
+	}
+
+	if req, err := NewRequest(context.Background(), http.MethodGet, &endpoint, nil, params, headers); err != nil {
+		return err
+	} else if res, err := s.send(req); err != nil {
+		return fmt.Errorf("unable to acquire managed identity token: %w", err)
+	} else if err := Decode(res.Body, &token); err != nil {
+		return err
+	} else {
+		return s.setToken(key, token)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+)
+
+func TestManagedIdentityIMDS(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		query := r.URL.Query()
+		if r.Header.Get("Metadata") != "true" {
+			t.Errorf("expected Metadata header")
+		} else if query.Get("api-version") != imdsApiVersion {
+			t.Errorf("got api-version %v, want %v", query.Get("api-version"), imdsApiVersion)
+		} else if query.Get("resource") != "https://graph.microsoft.com/" {
+			t.Errorf("got resource %v, want %v", query.Get("resource"), "https://graph.microsoft.com/")
+		} else if query.Get("client_id") != "user-assigned" {
+			t.Errorf("got client_id %v, want %v", query.Get("client_id"), "user-assigned")
+		}
+		fmt.Fprint(w, `{"access_token": "imds", "expires_in": "3599", "expires_on": "1506484173", "token_type": "Bearer"}`)
+	}))
+	defer server.Close()
+
+	t.Setenv("IDENTITY_ENDPOINT", "")
+	client, err := NewRestClient("https://graph.microsoft.com/", config.Config{
+		ApplicationId:           "user-assigned",
+		ManagedIdentity:         true,
+		ManagedIdentityEndpoint: server.URL,
+		ProxyUrl:                "http://127.0.0.1:1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the proxy is unreachable so the token request only succeeds if the proxy is bypassed
+	if token, err := client.(*restClient).getToken(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if token.accessToken != "imds" || time.Until(token.expires) < 59*time.Minute {
+		t.Errorf("got %+v, want a token valid for an hour", token)
+	} else if atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("got %d requests to the endpoint, want the token to be acquired rather than cached", requests)
+	}
+}
+
+func TestManagedIdentityAppService(t *testing.T) {
+	var (
+		expiresOn = time.Now().Add(time.Hour).Unix()
+		requests  int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("X-IDENTITY-HEADER") != "secret" {
+			t.Errorf("got identity header %v, want %v", r.Header.Get("X-IDENTITY-HEADER"), "secret")
+		} else if r.URL.Query().Get("api-version") != appServiceApiVersion {
+			t.Errorf("got api-version %v, want %v", r.URL.Query().Get("api-version"), appServiceApiVersion)
+		} else if r.URL.Query().Has("client_id") {
+			t.Errorf("unexpected client_id for a system-assigned identity")
+		}
+		fmt.Fprintf(w, `{"access_token": "app-service", "expires_on": "%d", "resource": "https://management.azure.com/"}`, expiresOn)
+	}))
+	defer server.Close()
+
+	t.Setenv("IDENTITY_ENDPOINT", server.URL)
+	t.Setenv("IDENTITY_HEADER", "secret")
+	client, err := NewRestClient("https://management.azure.com/", config.Config{ManagedIdentity: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token, err := client.(*restClient).getToken(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if token.accessToken != "app-service" || token.expires.Unix() != expiresOn {
+		t.Errorf("got %+v, want a token expiring at %v", token, expiresOn)
+	} else if atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("got %d requests to the endpoint, want the token to be acquired rather than cached", requests)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// MSALCache is a token cache written by the Microsoft Authentication Library, e.g. ~/.azure/msal_token_cache.json as
+// written by the Azure CLI. Caches that the Azure CLI and Azure PowerShell encrypt on Windows and macOS are not
+// supported.
+type MSALCache struct {
+	Accounts      map[string]MSALAccount      `json:"Account"`
+	RefreshTokens map[string]MSALRefreshToken `json:"RefreshToken"`
+}
+
+type MSALAccount struct {
+	HomeAccountId  string `json:"home_account_id"`  // The user's object id and home tenant id, e.g. '<oid>.<tid>'
+	Environment    string `json:"environment"`      // The host of the authority that signed the user in
+	Realm          string `json:"realm"`            // The tenant the user signed in to
+	LocalAccountId string `json:"local_account_id"` // The user's object id in the realm
+	Username       string `json:"username"`         // The user principal name
+}
+
+func (s MSALAccount) String() string {
+	return fmt.Sprintf("%s (%s)", s.Username, s.Realm)
+}
+
+type MSALRefreshToken struct {
+	HomeAccountId string `json:"home_account_id"`
+	Environment   string `json:"environment"`
+	ClientId      string `json:"client_id"` // The client the refresh token was issued to
+	FamilyId      string `json:"family_id"` // Set if the token may be redeemed by any client in the family of Microsoft first party clients
+	Secret        string `json:"secret"`
+}
+
+func ReadMSALCache(path string) (MSALCache, error) {
+	var cache MSALCache
+	if content, err := os.ReadFile(path); err != nil {
+		return cache, fmt.Errorf("unable to read MSAL token cache: %w", err)
+	} else if err := json.Unmarshal(content, &cache); err != nil {
+		return cache, fmt.Errorf("unable to parse MSAL token cache; encrypted caches are not supported: %w", err)
+	} else {
+		return cache, nil
+	}
+}
+
+// ListAccounts returns the cached accounts sorted by username and tenant
+func (s MSALCache) ListAccounts() []MSALAccount {
+	accounts := make([]MSALAccount, 0, len(s.Accounts))
+	for _, account := range s.Accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].String() < accounts[j].String()
+	})
+	return accounts
+}
+
+// SelectAccount returns the single account matching the username and tenant. Empty criteria match any account, and an
+// account signed in to another tenant matches if the user has no account in the given tenant since refresh tokens
+// can be redeemed for any tenant the user is a member of.
+func (s MSALCache) SelectAccount(environment, tenant, username string) (MSALAccount, error) {
+	var candidates, inTenant []MSALAccount
+	for _, account := range s.ListAccounts() {
+		if environment != "" && !strings.EqualFold(account.Environment, environment) {
+			continue
+		} else if username != "" && !strings.EqualFold(account.Username, username) {
+			continue
+		}
+
+		candidates = append(candidates, account)
+		if strings.EqualFold(account.Realm, tenant) {
+			inTenant = append(inTenant, account)
+		}
+	}
+
+	if len(inTenant) > 0 {
+		candidates = inTenant
+	}
+
+	users := map[string]MSALAccount{}
+	for _, account := range candidates {
+		users[account.HomeAccountId] = account
+	}
+
+	if len(users) == 0 {
+		return MSALAccount{}, fmt.Errorf("no matching account found in MSAL token cache")
+	} else if len(users) > 1 {
+		usernames := []string{}
+		for _, account := range users {
+			usernames = append(usernames, account.Username)
+		}
+		sort.Strings(usernames)
+		return MSALAccount{}, fmt.Errorf("multiple accounts found in MSAL token cache, select one of: %s", strings.Join(usernames, ", "))
+	} else {
+		return candidates[0], nil
+	}
+}
+
+// GetRefreshToken returns the client id and refresh token issued to the account
+func (s MSALCache) GetRefreshToken(account MSALAccount) (string, string, error) {
+	var tokens []MSALRefreshToken
+	for _, token := range s.RefreshTokens {
+		if token.HomeAccountId == account.HomeAccountId && strings.EqualFold(token.Environment, account.Environment) && token.Secret != "" {
+			tokens = append(tokens, token)
+		}
+	}
+
+	if len(tokens) == 0 {
+		return "", "", fmt.Errorf("no refresh token found in MSAL token cache for %s", account.Username)
+	}
+
+	// prefer family refresh tokens which are the most widely accepted
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].FamilyId != tokens[j].FamilyId {
+			return tokens[i].FamilyId > tokens[j].FamilyId
+		}
+		return tokens[i].ClientId < tokens[j].ClientId
+	})
+	return tokens[0].ClientId, tokens[0].Secret, nil
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+)
+
+const (
+	azureCliClientId = "04b07795-8ddb-461a-bbee-02f9e1bf7b46"
+	contosoTenant    = "aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa"
+	fabrikamTenant   = "bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb"
+)
+
+func TestMSALCacheSelectAccount(t *testing.T) {
+	cache, err := ReadMSALCache("testdata/msal_token_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if accounts := cache.ListAccounts(); len(accounts) != 3 {
+		t.Errorf("got %v accounts, want %v", len(accounts), 3)
+	}
+
+	// alice is the only account in her home tenant
+	if account, err := cache.SelectAccount("login.microsoftonline.com", contosoTenant, ""); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if account.Username != "alice@contoso.com" {
+		t.Errorf("got %v, want %v", account.Username, "alice@contoso.com")
+	}
+
+	// alice is a guest in bob's tenant
+	if _, err := cache.SelectAccount("login.microsoftonline.com", fabrikamTenant, ""); err == nil {
+		t.Errorf("expected an error for an ambiguous account")
+	} else if account, err := cache.SelectAccount("login.microsoftonline.com", fabrikamTenant, "Alice@contoso.com"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if account.Realm != fabrikamTenant {
+		t.Errorf("got realm %v, want %v", account.Realm, fabrikamTenant)
+	}
+
+	if _, err := cache.SelectAccount("login.chinacloudapi.cn", contosoTenant, ""); err == nil {
+		t.Errorf("expected an error for an account in another cloud")
+	}
+}
+
+func TestMSALCacheRefreshToken(t *testing.T) {
+	cache, err := ReadMSALCache("testdata/msal_token_cache.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bob, _ := cache.SelectAccount("", "", "bob@fabrikam.com")
+	if clientId, refreshToken, err := cache.GetRefreshToken(bob); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if clientId != azureCliClientId || refreshToken != "bob-family-refresh-token" {
+		t.Errorf("got %v issued to %v, want the family refresh token", refreshToken, clientId)
+	}
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "alice-refresh-token" {
+			t.Errorf("got %v grant with %v", r.Form.Get("grant_type"), r.Form.Get("refresh_token"))
+		} else if r.Form.Get("client_id") != azureCliClientId {
+			t.Errorf("got client_id %v, want %v", r.Form.Get("client_id"), azureCliClientId)
+		}
+		fmt.Fprint(w, `{"access_token": "token", "refresh_token": "rotated", "expires_in": 3600}`)
+	}))
+	defer server.Close()
+
+	alice, _ := cache.SelectAccount("", contosoTenant, "")
+	clientId, refreshToken, _ := cache.GetRefreshToken(alice)
+	if client, err := NewRestClient(server.URL+"/msal", config.Config{
+		Authority:            server.URL,
+		RefreshToken:         refreshToken,
+		RefreshTokenClientId: clientId,
+		Tenant:               contosoTenant,
+	}); err != nil {
+		t.Fatal(err)
+	} else if err := client.Authenticate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if requests != 1 {
+		t.Errorf("got %v token requests, want %v", requests, 1)
+	}
+}
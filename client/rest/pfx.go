@@ -0,0 +1,88 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// IsPKCS12 reports whether the content begins like a PKCS#12 (PFX) bundle, i.e. with a SEQUENCE whose first element is
+// the version INTEGER 3, rather than being a PEM encoded file or anything else
+func IsPKCS12(content []byte) bool {
+	if len(content) < 2 || content[0] != 0x30 {
+		return false
+	}
+
+	// skip the SEQUENCE's length, which may be indefinite when BER encoded
+	header := 2
+	if content[1] > 0x80 {
+		header += int(content[1] & 0x7f)
+	}
+
+	return len(content) >= header+3 && bytes.Equal(content[header:header+3], []byte{0x02, 0x01, 0x03})
+}
+
+// ReadPFX decodes a PKCS#12 (PFX) bundle, e.g. a certificate exported from Key Vault, into a PEM encoded certificate
+// chain, with the certificate of the private key first, and an unencrypted PEM encoded PKCS#8 private key. Bundles
+// encrypted with either the legacy SHA1/3DES algorithms or the PBES2/AES algorithms that OpenSSL 3 exports by default
+// are supported.
+func ReadPFX(content []byte, password string) (string, string, error) {
+	privateKey, cert, caCerts, err := pkcs12.DecodeChain(content, password)
+	if err != nil {
+		return "", "", fmt.Errorf("Unable to decode PKCS#12 bundle: %w", err)
+	}
+
+	key, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return "", "", fmt.Errorf("Unable to use %T private key; only RSA keys are supported", privateKey)
+	}
+
+	// the certificate of the private key must come first so that its thumbprint identifies the key, however the
+	// bundle orders them
+	var (
+		certs = append([]*x509.Certificate{cert}, caCerts...)
+		chain bytes.Buffer
+	)
+	for _, cert := range certs {
+		if publicKey, ok := cert.PublicKey.(*rsa.PublicKey); ok && publicKey.Equal(&key.PublicKey) {
+			pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+		}
+	}
+
+	if chain.Len() == 0 {
+		return "", "", fmt.Errorf("PKCS#12 bundle does not contain the certificate of its private key")
+	}
+
+	for _, cert := range certs {
+		if publicKey, ok := cert.PublicKey.(*rsa.PublicKey); !ok || !publicKey.Equal(&key.PublicKey) {
+			pem.Encode(&chain, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+		}
+	}
+
+	if der, err := x509.MarshalPKCS8PrivateKey(key); err != nil {
+		return "", "", fmt.Errorf("Unable to encode private key: %w", err)
+	} else {
+		return chain.String(), string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func TestIsPKCS12(t *testing.T) {
+	tests := map[string]struct {
+		content  []byte
+		expected bool
+	}{
+		"empty":             {content: nil},
+		"pem":               {content: []byte("-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n")},
+		"binary":            {content: []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05}},
+		"other sequence":    {content: []byte{0x30, 0x05, 0x02, 0x01, 0x01, 0x05, 0x00}},
+		"definite length":   {content: []byte{0x30, 0x82, 0x01, 0x00, 0x02, 0x01, 0x03}, expected: true},
+		"indefinite length": {content: []byte{0x30, 0x80, 0x02, 0x01, 0x03}, expected: true},
+	}
+
+	for name, test := range tests {
+		if actual := IsPKCS12(test.content); actual != test.expected {
+			t.Errorf("%s: got %v, want %v", name, actual, test.expected)
+		}
+	}
+}
+
+func TestReadPFX(t *testing.T) {
+	// exported with OpenSSL's legacy SHA1/3DES algorithms and with its PBES2/AES defaults
+	for _, path := range []string{"testdata/cert.pfx", "testdata/cert-aes.pfx"} {
+		t.Run(path, func(t *testing.T) {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			} else if !IsPKCS12(content) {
+				t.Fatalf("expected a PKCS#12 bundle")
+			}
+
+			if _, _, err := ReadPFX(content, "wrong"); err == nil {
+				t.Errorf("expected an error for an incorrect password")
+			}
+
+			cert, key, err := ReadPFX(content, "password")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if IsPKCS12([]byte(cert)) || IsPKCS12([]byte(key)) {
+				t.Fatalf("expected PEM encoded certificate and key")
+			}
+
+			chain, err := parseCertificates(cert)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if len(chain) != 2 {
+				t.Fatalf("got %v certificates, want %v", len(chain), 2)
+			} else if chain[0].Subject.CommonName != "azurehound-test" {
+				t.Errorf("got %v first, want the certificate of the private key", chain[0].Subject.CommonName)
+			}
+
+			for _, sendCertChain := range []bool{false, true} {
+				assertion, err := NewClientAssertion("https://login.microsoftonline.com/contoso/oauth2/v2.0/token", "client", cert, key, "", sendCertChain)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				token, _, err := new(jwt.Parser).ParseUnverified(assertion, jwt.MapClaims{})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				} else if token.Header["x5t"] != x5t(chain[0]) {
+					t.Errorf("got x5t %v, want %v", token.Header["x5t"], x5t(chain[0]))
+				}
+
+				if x5c, ok := token.Header["x5c"].([]interface{}); ok != sendCertChain {
+					t.Errorf("got x5c %v when sendCertChain is %v", token.Header["x5c"], sendCertChain)
+				} else if ok && len(x5c) != len(chain) {
+					t.Errorf("got %v certificates in x5c, want %v", len(x5c), len(chain))
+				}
+			}
+		})
+	}
+}
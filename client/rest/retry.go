@@ -0,0 +1,176 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/constants"
+)
+
+const (
+	DefaultMinBackoff time.Duration = 1 * time.Second
+	DefaultMaxBackoff time.Duration = 2 * time.Minute
+)
+
+// RetryPolicy describes how failed requests are retried. Requests are retried when Azure responds with 429 (Too Many
+// Requests), 503 (Service Unavailable) or 504 (Gateway Timeout), or when the request fails with a transient network
+// error.
+type RetryPolicy struct {
+	MaxRetries int           // The maximum number of times a request is retried; zero disables retries
+	MinBackoff time.Duration // The backoff before the first retry; doubles with each subsequent retry
+	MaxBackoff time.Duration // The upper bound of any single backoff, including those requested by Azure
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: constants.DefaultMaxRetries,
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+	}
+}
+
+// ShouldRetry reports whether a request that produced the given response and error should be retried
+func (s RetryPolicy) ShouldRetry(retry int, res *http.Response, err error) bool {
+	if retry >= s.MaxRetries {
+		return false
+	} else if err != nil {
+		return isTransientError(err)
+	} else {
+		return IsRetryableStatus(res.StatusCode)
+	}
+}
+
+// Backoff returns how long to wait before sending the given retry. Azure's own guidance, via the 'Retry-After' family
+// of headers, is preferred over exponential backoff; if Azure reports that a rate limit has been exhausted without
+// saying when to retry then the maximum backoff is used.
+func (s RetryPolicy) Backoff(retry int, res *http.Response) time.Duration {
+	if res != nil {
+		if delay, ok := retryAfter(res.Header); ok {
+			return s.clamp(delay)
+		} else if rateLimitExhausted(res.Header) {
+			return s.MaxBackoff
+		}
+	}
+
+	// exponential backoff with equal jitter
+	ceiling := float64(s.MinBackoff) * math.Pow(2, float64(retry))
+	if ceiling > float64(s.MaxBackoff) {
+		ceiling = float64(s.MaxBackoff)
+	}
+	return s.clamp(time.Duration(rand.Int63n(int64(ceiling)/2+1)) + time.Duration(ceiling/2))
+}
+
+func (s RetryPolicy) clamp(delay time.Duration) time.Duration {
+	if delay < 0 {
+		return 0
+	} else if s.MaxBackoff > 0 && delay > s.MaxBackoff {
+		return s.MaxBackoff
+	} else {
+		return delay
+	}
+}
+
+func IsRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Sleep waits for the given duration or until the context is done, whichever happens first
+func Sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// drain discards any unread content and closes the body so that the underlying connection may be reused
+func drain(body io.ReadCloser) {
+	io.Copy(io.Discard, body)
+	body.Close()
+}
+
+func isTransientError(err error) bool {
+	var netErr net.Error
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	} else if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	} else if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	} else if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	} else {
+		return false
+	}
+}
+
+// retryAfter parses the delay requested by Azure. ARM and Microsoft Graph use the standard 'Retry-After' header, in
+// either delay-seconds or HTTP-date form, while some services use millisecond variants.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	for _, key := range []string{"Retry-After-Ms", "X-Ms-Retry-After-Ms"} {
+		if value := header.Get(key); value != "" {
+			if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return time.Duration(ms) * time.Millisecond, true
+			}
+		}
+	}
+
+	if value := header.Get("Retry-After"); value == "" {
+		return 0, false
+	} else if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	} else if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date), true
+	} else {
+		return 0, false
+	}
+}
+
+// rateLimitExhausted reports whether any of ARM's 'x-ms-ratelimit-remaining-*' headers show that no requests remain
+func rateLimitExhausted(header http.Header) bool {
+	for key, values := range header {
+		if strings.HasPrefix(strings.ToLower(key), "x-ms-ratelimit-remaining-") {
+			for _, value := range values {
+				if remaining, err := strconv.Atoi(value); err == nil && remaining <= 0 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	header := http.Header{}
+	if _, ok := retryAfter(header); ok {
+		t.Errorf("got retry-after for empty header")
+	}
+
+	header.Set("Retry-After", "7")
+	if delay, ok := retryAfter(header); !ok || delay != 7*time.Second {
+		t.Errorf("got %v, want %v", delay, 7*time.Second)
+	}
+
+	header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if delay, ok := retryAfter(header); !ok || delay <= 0 || delay > time.Minute {
+		t.Errorf("got %v, want a delay of at most %v", delay, time.Minute)
+	}
+
+	header.Set("x-ms-retry-after-ms", "250")
+	if delay, ok := retryAfter(header); !ok || delay != 250*time.Millisecond {
+		t.Errorf("got %v, want %v", delay, 250*time.Millisecond)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, MinBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	for retry := 0; retry < 10; retry++ {
+		if delay := policy.Backoff(retry, nil); delay < 0 || delay > policy.MaxBackoff {
+			t.Errorf("got %v, want a delay between 0 and %v", delay, policy.MaxBackoff)
+		}
+	}
+
+	res := &http.Response{Header: http.Header{}}
+	res.Header.Set("x-ms-ratelimit-remaining-subscription-reads", "0")
+	if delay := policy.Backoff(0, res); delay != policy.MaxBackoff {
+		t.Errorf("got %v, want %v", delay, policy.MaxBackoff)
+	}
+
+	res.Header.Set("Retry-After", "3600")
+	if delay := policy.Backoff(0, res); delay != policy.MaxBackoff {
+		t.Errorf("got %v, want %v", delay, policy.MaxBackoff)
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	var (
+		requests int
+		bodies   []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		switch requests {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"value": []}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &restClient{
+		http:  server.Client(),
+		retry: RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond},
+	}
+
+	endpoint, _ := url.Parse(server.URL)
+	if req, err := NewRequest(context.Background(), http.MethodPost, endpoint, map[string]string{"foo": "bar"}, nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res, err := client.send(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else {
+		drain(res.Body)
+	}
+
+	if requests != 3 {
+		t.Errorf("got %v requests, want %v", requests, 3)
+	}
+
+	for _, body := range bodies {
+		if !strings.Contains(body, "bar") {
+			t.Errorf("got body %q, want the original body on every attempt", body)
+		}
+	}
+}
+
+func TestSendRetryBudget(t *testing.T) {
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusGatewayTimeout)
+		w.Write([]byte(`{"error": {"code": "GatewayTimeout", "message": "timed out"}}`))
+	}))
+	defer server.Close()
+
+	client := &restClient{
+		http:  server.Client(),
+		retry: RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	}
+
+	endpoint, _ := url.Parse(server.URL)
+	if req, err := NewRequest(context.Background(), http.MethodGet, endpoint, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	} else if _, err := client.send(req); err == nil {
+		t.Errorf("expected an error once the retry budget was spent")
+	}
+
+	if requests != 3 {
+		t.Errorf("got %v requests, want %v", requests, 3)
+	}
+}
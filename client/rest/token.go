@@ -20,11 +20,17 @@ package rest
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// How long before expiry a token is refreshed in the background
+const TokenRefreshWindow time.Duration = 5 * time.Minute
+
 type Token struct {
 	accessToken  string
+	refreshToken string
 	expiresIn    int
 	extExpiresIn int
 	expires      time.Time
@@ -34,6 +40,11 @@ func (s Token) IsExpired() bool {
 	return time.Now().After(s.expires.Add(-10 * time.Second))
 }
 
+// NeedsRefresh reports whether the token expires within the TokenRefreshWindow
+func (s Token) NeedsRefresh() bool {
+	return time.Now().After(s.expires.Add(-TokenRefreshWindow))
+}
+
 func (s Token) String() string {
 	return fmt.Sprintf("Bearer %s", s.accessToken)
 }
@@ -41,8 +52,10 @@ func (s Token) String() string {
 func (s *Token) UnmarshalJSON(data []byte) error {
 	var res struct {
 		AccessToken  string `json:"access_token"`   // The token to use in calls to Microsoft Graph API
-		ExpiresIn    int    `json:"expires_in"`     // How long the access token is valid in seconds
-		ExtExpiresIn int    `json:"ext_expires_in"` // How long the access token is valid in seconds
+		RefreshToken string `json:"refresh_token"`  // The token to use to acquire new access tokens, if issued
+		ExpiresIn    number `json:"expires_in"`     // How long the access token is valid in seconds
+		ExpiresOn    number `json:"expires_on"`     // When the access token expires in seconds since the Unix epoch
+		ExtExpiresIn number `json:"ext_expires_in"` // How long the access token is valid in seconds
 		TokenType    string `json:"token_type"`     // Indicates the token type value. The only type currently supported by Azure AD is `bearer`
 	}
 
@@ -50,9 +63,30 @@ func (s *Token) UnmarshalJSON(data []byte) error {
 		return err
 	} else {
 		s.accessToken = res.AccessToken
-		s.expiresIn = res.ExpiresIn
-		s.extExpiresIn = res.ExtExpiresIn
-		s.expires = time.Now().Add(time.Duration(res.ExpiresIn) * time.Second)
+		s.refreshToken = res.RefreshToken
+		s.expiresIn = int(res.ExpiresIn)
+		s.extExpiresIn = int(res.ExtExpiresIn)
+		if res.ExpiresIn == 0 && res.ExpiresOn > 0 {
+			s.expires = time.Unix(int64(res.ExpiresOn), 0)
+		} else {
+			s.expires = time.Now().Add(time.Duration(res.ExpiresIn) * time.Second)
+		}
+		return nil
+	}
+}
+
+// number is an integer that is encoded as either a JSON number or a string. Managed identity endpoints encode
+// expires_in and expires_on as strings.
+type number int64
+
+func (s *number) UnmarshalJSON(data []byte) error {
+	if value := strings.Trim(string(data), `"`); value == "" || value == "null" {
+		*s = 0
+		return nil
+	} else if parsed, err := strconv.ParseInt(value, 10, 64); err != nil {
+		return fmt.Errorf("invalid number %s: %w", data, err)
+	} else {
+		*s = number(parsed)
 		return nil
 	}
 }
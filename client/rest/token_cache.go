@@ -0,0 +1,288 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// The size of the random salt from which, along with the secret, the key of a persisted token cache is derived
+const tokenCacheSaltSize int = 16
+
+// TokenCache holds acquired tokens keyed by tenant, client id and audience. When given a path the cache is persisted to
+// disk, encrypted with AES-256-GCM using a key derived with scrypt from a secret supplied by the user. Neither the
+// secret nor the key is stored; the file begins with the salt that the key is derived with, followed by the nonce and
+// the sealed tokens.
+type TokenCache struct {
+	path   string
+	secret string
+	salt   []byte
+	key    []byte
+	mutex  sync.Mutex
+	loaded bool
+	tokens map[string]cachedToken
+}
+
+type cachedToken struct {
+	Tenant       string    `json:"tenant"`
+	ClientId     string    `json:"clientId"`
+	Audience     string    `json:"audience"`
+	Account      string    `json:"account,omitempty"`
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	ExpiresOn    time.Time `json:"expiresOn"`
+}
+
+func NewTokenCache(path string, secret string) *TokenCache {
+	return &TokenCache{
+		path:   path,
+		secret: secret,
+		tokens: make(map[string]cachedToken),
+	}
+}
+
+// Persisted caches are shared by every client using the same path so that tokens, and in particular refresh tokens,
+// acquired by one client are visible to the others. Clients without a path each have their own in-memory cache.
+var tokenCaches = struct {
+	sync.Mutex
+	byPath map[string]*TokenCache
+}{byPath: make(map[string]*TokenCache)}
+
+func sharedTokenCache(path string, secret string) *TokenCache {
+	if path == "" {
+		return NewTokenCache(path, secret)
+	}
+
+	tokenCaches.Lock()
+	defer tokenCaches.Unlock()
+
+	if cache, ok := tokenCaches.byPath[path]; ok {
+		return cache
+	} else {
+		cache := NewTokenCache(path, secret)
+		tokenCaches.byPath[path] = cache
+		return cache
+	}
+}
+
+// TokenCacheKey identifies the tokens acquired for an audience by a client on behalf of a tenant. Account
+// distinguishes the users that authenticated with their username, if any.
+type TokenCacheKey struct {
+	Tenant   string
+	ClientId string
+	Audience string
+	Account  string
+}
+
+func (s TokenCacheKey) String() string {
+	return strings.ToLower(fmt.Sprintf("%s|%s|%s|%s", s.Tenant, s.ClientId, s.Audience, s.Account))
+}
+
+// Get returns the cached token for the key
+func (s *TokenCache) Get(key TokenCacheKey) (Token, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.load(); err != nil {
+		return Token{}, false, err
+	} else if cached, ok := s.tokens[key.String()]; !ok {
+		return Token{}, false, nil
+	} else {
+		return Token{
+			accessToken:  cached.AccessToken,
+			refreshToken: cached.RefreshToken,
+			expires:      cached.ExpiresOn,
+		}, true, nil
+	}
+}
+
+// RefreshToken returns the most recently issued refresh token for the key's tenant, client and account. Refresh tokens
+// are not bound to an audience so a refresh token acquired for Microsoft Graph can be redeemed for Azure Resource
+// Manager.
+func (s *TokenCache) RefreshToken(key TokenCacheKey) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.load(); err != nil {
+		return "", err
+	}
+
+	var latest cachedToken
+	for _, cached := range s.tokens {
+		if cached.RefreshToken == "" || !cached.ExpiresOn.After(latest.ExpiresOn) {
+			continue
+		} else if strings.EqualFold(cached.Tenant, key.Tenant) && strings.EqualFold(cached.ClientId, key.ClientId) && strings.EqualFold(cached.Account, key.Account) {
+			latest = cached
+		}
+	}
+	return latest.RefreshToken, nil
+}
+
+// Put caches the token and, if the cache is persisted, writes the cache to disk
+func (s *TokenCache) Put(key TokenCacheKey, token Token) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	s.tokens[key.String()] = cachedToken{
+		Tenant:       key.Tenant,
+		ClientId:     key.ClientId,
+		Audience:     key.Audience,
+		Account:      key.Account,
+		AccessToken:  token.accessToken,
+		RefreshToken: token.refreshToken,
+		ExpiresOn:    token.expires,
+	}
+	return s.save()
+}
+
+func (s *TokenCache) load() error {
+	if s.loaded || s.path == "" {
+		return nil
+	} else if data, err := os.ReadFile(s.path); errors.Is(err, os.ErrNotExist) {
+		s.loaded = true
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to read token cache: %w", err)
+	} else if len(data) < tokenCacheSaltSize {
+		return fmt.Errorf("invalid token cache %s", s.path)
+	} else if key, err := s.deriveKey(data[:tokenCacheSaltSize]); err != nil {
+		return err
+	} else if plaintext, err := decrypt(key, data[tokenCacheSaltSize:]); err != nil {
+		return fmt.Errorf("unable to decrypt token cache; was it encrypted with another secret?: %w", err)
+	} else if err := json.Unmarshal(plaintext, &s.tokens); err != nil {
+		return fmt.Errorf("unable to decode token cache: %w", err)
+	} else {
+		s.loaded = true
+		return nil
+	}
+}
+
+func (s *TokenCache) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	// drop expired tokens that can't be refreshed
+	for key, cached := range s.tokens {
+		if cached.RefreshToken == "" && time.Now().After(cached.ExpiresOn) {
+			delete(s.tokens, key)
+		}
+	}
+
+	salt := s.salt
+	if salt == nil {
+		salt = make([]byte, tokenCacheSaltSize)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return err
+		}
+	}
+
+	if plaintext, err := json.Marshal(s.tokens); err != nil {
+		return err
+	} else if key, err := s.deriveKey(salt); err != nil {
+		return err
+	} else if ciphertext, err := encrypt(key, plaintext); err != nil {
+		return err
+	} else {
+		return writeFileAtomic(s.path, append(append([]byte{}, salt...), ciphertext...), 0600)
+	}
+}
+
+// deriveKey derives the AES-256 key from the secret and salt, reusing the key derived for the same salt since scrypt is
+// deliberately slow
+func (s *TokenCache) deriveKey(salt []byte) ([]byte, error) {
+	if s.secret == "" {
+		return nil, fmt.Errorf("a secret is required to encrypt the token cache")
+	} else if s.key != nil && bytes.Equal(salt, s.salt) {
+		return s.key, nil
+	} else if key, err := scrypt.Key([]byte(s.secret), salt, 1<<15, 8, 1, 32); err != nil {
+		return nil, err
+	} else {
+		s.salt, s.key = salt, key
+		return key, nil
+	}
+}
+
+func encrypt(key, plaintext []byte) ([]byte, error) {
+	if block, err := aes.NewCipher(key); err != nil {
+		return nil, err
+	} else if gcm, err := cipher.NewGCM(block); err != nil {
+		return nil, err
+	} else {
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return nil, err
+		}
+		return gcm.Seal(nonce, nonce, plaintext, nil), nil
+	}
+}
+
+func decrypt(key, ciphertext []byte) ([]byte, error) {
+	if block, err := aes.NewCipher(key); err != nil {
+		return nil, err
+	} else if gcm, err := cipher.NewGCM(block); err != nil {
+		return nil, err
+	} else if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	} else {
+		nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+		return gcm.Open(nil, nonce, sealed, nil)
+	}
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and renames it over path so that readers
+// never observe a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	} else if file, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp"); err != nil {
+		return err
+	} else {
+		defer os.Remove(file.Name())
+		if _, err := file.Write(data); err != nil {
+			file.Close()
+			return err
+		} else if err := file.Chmod(perm); err != nil {
+			file.Close()
+			return err
+		} else if err := file.Close(); err != nil {
+			return err
+		} else {
+			return os.Rename(file.Name(), path)
+		}
+	}
+}
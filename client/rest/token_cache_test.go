@@ -0,0 +1,134 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+)
+
+func TestTokenCachePersistence(t *testing.T) {
+	var (
+		path  = filepath.Join(t.TempDir(), "tokens")
+		graph = TokenCacheKey{Tenant: "contoso", ClientId: "client", Audience: "https://graph.microsoft.com"}
+		arm   = TokenCacheKey{Tenant: "contoso", ClientId: "client", Audience: "https://management.azure.com"}
+		token = Token{accessToken: "secret-access-token", refreshToken: "secret-refresh-token", expires: time.Now().Add(time.Hour)}
+	)
+
+	if err := NewTokenCache(path, "passphrase").Put(graph, token); err != nil {
+		t.Fatalf("unable to cache token: %v", err)
+	}
+
+	if data, err := os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	} else if bytes.Contains(data, []byte("secret")) {
+		t.Errorf("token cache is not encrypted")
+	}
+
+	// the key is derived from the secret rather than stored alongside the cache
+	if files, err := os.ReadDir(filepath.Dir(path)); err != nil {
+		t.Fatal(err)
+	} else if len(files) != 1 {
+		t.Errorf("got %v files, want only the token cache", len(files))
+	} else if info, err := files[0].Info(); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm()&0077 != 0 {
+		t.Errorf("got token cache mode %v, want it readable only by its owner", info.Mode())
+	}
+
+	if _, _, err := NewTokenCache(path, "another passphrase").Get(graph); err == nil {
+		t.Errorf("expected an error decrypting the token cache with another secret")
+	} else if _, _, err := NewTokenCache(path, "").Get(graph); err == nil {
+		t.Errorf("expected an error reading the token cache without a secret")
+	}
+
+	cache := NewTokenCache(path, "passphrase")
+	if cached, ok, err := cache.Get(graph); err != nil {
+		t.Fatalf("unable to read token cache: %v", err)
+	} else if !ok || cached.accessToken != token.accessToken || !cached.expires.Equal(token.expires) {
+		t.Errorf("got %+v, want %+v", cached, token)
+	}
+
+	if _, ok, _ := cache.Get(arm); ok {
+		t.Errorf("got a token for another audience")
+	} else if refreshToken, err := cache.RefreshToken(arm); err != nil || refreshToken != token.refreshToken {
+		t.Errorf("got %v, want the refresh token issued for another audience", refreshToken)
+	}
+
+	arm.Account = "someone@contoso.com"
+	if refreshToken, _ := cache.RefreshToken(arm); refreshToken != "" {
+		t.Errorf("got a refresh token issued to another account")
+	}
+}
+
+func TestGetTokenSingleFlight(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/contoso/oauth2/v2.0/token" {
+			atomic.AddInt32(&requests, 1)
+			time.Sleep(20 * time.Millisecond)
+			fmt.Fprint(w, `{"access_token": "token", "expires_in": 3600}`)
+		} else if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewRestClient(server.URL, config.Config{
+		ApplicationId: "client",
+		Authority:     server.URL,
+		ClientSecret:  "secret",
+		Graph:         server.URL,
+		Tenant:        "contoso",
+		TokenCache:    filepath.Join(t.TempDir(), "tokens"),
+		TokenCacheKey: "passphrase",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 25; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res, err := client.Get(context.Background(), "/v1.0/organization", nil, nil); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else {
+				drain(res.Body)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if requests != 1 {
+		t.Errorf("got %v token requests, want %v", requests, 1)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"encoding/pem"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 	"time"
 
@@ -33,17 +34,21 @@ import (
 	"github.com/youmark/pkcs8"
 )
 
+// Decode decodes the JSON body into v. The body is drained and closed so that the underlying connection may be reused.
 func Decode(body io.ReadCloser, v interface{}) error {
-	defer body.Close()
+	defer drain(body)
 	return json.NewDecoder(body).Decode(v)
 }
 
-func NewClientAssertion(tokenUrl string, clientId string, clientCert string, signingKey string, keyPassphrase string) (string, error) {
+// NewClientAssertion signs a client assertion with the private key of the first certificate in clientCert. If
+// sendCertChain is set, the certificate chain is sent in the 'x5c' header to support subject name and issuer
+// authentication.
+func NewClientAssertion(tokenUrl string, clientId string, clientCert string, signingKey string, keyPassphrase string, sendCertChain bool) (string, error) {
 	if key, err := parseRSAPrivateKey(signingKey, keyPassphrase); err != nil {
 		return "", fmt.Errorf("Unable to parse private key: %w", err)
 	} else if jti, err := uuid.NewV4(); err != nil {
 		return "", fmt.Errorf("Unable to generate JWT ID: %w", err)
-	} else if thumbprint, err := x5t(clientCert); err != nil {
+	} else if chain, err := parseCertificates(clientCert); err != nil {
 		return "", fmt.Errorf("Unable to create X.509 certificate thumbprint: %w", err)
 	} else {
 		iat := time.Now()
@@ -61,7 +66,15 @@ func NewClientAssertion(tokenUrl string, clientId string, clientCert string, sig
 		token.Header = map[string]interface{}{
 			"alg": "RS256",
 			"typ": "JWT",
-			"x5t": thumbprint,
+			"x5t": x5t(chain[0]),
+		}
+
+		if sendCertChain {
+			x5c := make([]string, len(chain))
+			for i, cert := range chain {
+				x5c[i] = base64.StdEncoding.EncodeToString(cert.Raw)
+			}
+			token.Header["x5c"] = x5c
 		}
 
 		if signedToken, err := token.SignedString(key); err != nil {
@@ -72,6 +85,18 @@ func NewClientAssertion(tokenUrl string, clientId string, clientCert string, sig
 	}
 }
 
+// ReadFederatedToken reads the OIDC token that an external identity provider, e.g. a Kubernetes projected service
+// account token, issued to a workload whose federated credential the app registration trusts
+func ReadFederatedToken(path string) (string, error) {
+	if content, err := os.ReadFile(path); err != nil {
+		return "", fmt.Errorf("Unable to read federated token: %w", err)
+	} else if token := strings.TrimSpace(string(content)); token == "" {
+		return "", fmt.Errorf("Federated token file %s is empty", path)
+	} else {
+		return token, nil
+	}
+}
+
 func ParseBody(accessToken string) (map[string]interface{}, error) {
 	var (
 		body  = make(map[string]interface{})
@@ -80,7 +105,7 @@ func ParseBody(accessToken string) (map[string]interface{}, error) {
 
 	if len(parts) != 3 {
 		return body, fmt.Errorf("invalid access token")
-	} else if bytes, err := base64.RawStdEncoding.DecodeString(parts[1]); err != nil {
+	} else if bytes, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "=")); err != nil {
 		return body, err
 	} else if err := json.Unmarshal(bytes, &body); err != nil {
 		return body, err
@@ -99,6 +124,44 @@ func ParseAud(accessToken string) (string, error) {
 	}
 }
 
+// ParseExp returns when the access token expires, if it has an 'exp' claim
+func ParseExp(accessToken string) (time.Time, bool, error) {
+	if body, err := ParseBody(accessToken); err != nil {
+		return time.Time{}, false, err
+	} else if exp, ok := body["exp"]; !ok {
+		return time.Time{}, false, nil
+	} else if seconds, ok := exp.(float64); !ok {
+		return time.Time{}, false, fmt.Errorf("invalid 'exp' type: %T", exp)
+	} else {
+		return time.Unix(int64(seconds), 0), true, nil
+	}
+}
+
+// ValidateJWT returns an error if the access token is malformed or has expired
+func ValidateJWT(accessToken string) error {
+	if aud, err := ParseAud(accessToken); err != nil {
+		return err
+	} else if exp, ok, err := ParseExp(accessToken); err != nil {
+		return err
+	} else if ok && time.Now().After(exp) {
+		return fmt.Errorf("the JWT for %s expired at %s", aud, exp.Format(time.RFC3339))
+	} else {
+		return nil
+	}
+}
+
+// SelectJWT returns the access token whose audience is the API, or an empty string if there is none
+func SelectJWT(accessTokens []string, apiUrl string) (string, error) {
+	for _, accessToken := range accessTokens {
+		if aud, err := ParseAud(accessToken); err != nil {
+			return "", err
+		} else if strings.TrimSuffix(aud, "/") == strings.TrimSuffix(apiUrl, "/") {
+			return accessToken, nil
+		}
+	}
+	return "", nil
+}
+
 func parseRSAPrivateKey(signingKey string, password string) (interface{}, error) {
 	if decodedBlock, _ := pem.Decode([]byte(signingKey)); decodedBlock == nil {
 		return nil, fmt.Errorf("Unable to decode private key")
@@ -109,13 +172,34 @@ func parseRSAPrivateKey(signingKey string, password string) (interface{}, error)
 	}
 }
 
-func x5t(certificate string) (string, error) {
-	if decoded, _ := pem.Decode([]byte(certificate)); decoded == nil {
-		return "", fmt.Errorf("Unable to decode certificate")
-	} else if cert, err := x509.ParseCertificate(decoded.Bytes); err != nil {
-		return "", fmt.Errorf("Unable to parse certificate: %w", err)
+// parseCertificates parses the PEM encoded certificates, of which there must be at least one
+func parseCertificates(certificates string) ([]*x509.Certificate, error) {
+	var (
+		rest  = []byte(certificates)
+		chain []*x509.Certificate
+	)
+
+	for {
+		var decoded *pem.Block
+		if decoded, rest = pem.Decode(rest); decoded == nil {
+			break
+		} else if decoded.Type != "CERTIFICATE" {
+			continue
+		} else if cert, err := x509.ParseCertificate(decoded.Bytes); err != nil {
+			return nil, fmt.Errorf("Unable to parse certificate: %w", err)
+		} else {
+			chain = append(chain, cert)
+		}
+	}
+
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("Unable to decode certificate")
 	} else {
-		checksum := sha1.Sum(cert.Raw)
-		return base64.StdEncoding.EncodeToString(checksum[:]), nil
+		return chain, nil
 	}
 }
+
+func x5t(cert *x509.Certificate) string {
+	checksum := sha1.Sum(cert.Raw)
+	return base64.StdEncoding.EncodeToString(checksum[:])
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func testJWT(claims map[string]interface{}) string {
+	body, _ := json.Marshal(claims)
+	return "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString(body) + ".signature"
+}
+
+func TestSelectJWT(t *testing.T) {
+	var (
+		graph = testJWT(map[string]interface{}{"aud": "https://graph.microsoft.com"})
+		rm    = testJWT(map[string]interface{}{"aud": "https://management.azure.com/"})
+		jwts  = []string{graph, rm}
+	)
+
+	if jwt, err := SelectJWT(jwts, "https://graph.microsoft.com/"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if jwt != graph {
+		t.Errorf("got %v, want the Microsoft Graph JWT", jwt)
+	}
+
+	if jwt, err := SelectJWT(jwts, "https://management.azure.com"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if jwt != rm {
+		t.Errorf("got %v, want the Azure Resource Manager JWT", jwt)
+	}
+
+	if jwt, err := SelectJWT([]string{graph}, "https://management.azure.com"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if jwt != "" {
+		t.Errorf("got %v, want no JWT", jwt)
+	}
+
+	if _, err := SelectJWT([]string{"malformed"}, "https://graph.microsoft.com"); err == nil {
+		t.Errorf("expected an error for a malformed JWT")
+	}
+}
+
+func TestValidateJWT(t *testing.T) {
+	var (
+		valid   = testJWT(map[string]interface{}{"aud": "https://graph.microsoft.com", "exp": time.Now().Add(time.Hour).Unix()})
+		expired = testJWT(map[string]interface{}{"aud": "https://graph.microsoft.com", "exp": time.Now().Add(-time.Hour).Unix()})
+	)
+
+	if err := ValidateJWT(valid); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := ValidateJWT(expired); err == nil {
+		t.Errorf("expected an error for an expired JWT")
+	}
+}
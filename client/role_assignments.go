@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 	"strings"
 
 	"github.com/bloodhoundad/azurehound/client/query"
@@ -67,53 +66,19 @@ func (s *azureClient) GetAzureADRoleAssignments(ctx context.Context, filter, sea
 }
 
 func (s *azureClient) ListAzureADRoleAssignments(ctx context.Context, filter, search, orderBy, expand string, selectCols []string) <-chan azure.UnifiedRoleAssignmentResult {
-	out := make(chan azure.UnifiedRoleAssignmentResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.UnifiedRoleAssignmentResult{}
-			nextLink  string
-		)
-
-		if list, err := s.GetAzureADRoleAssignments(ctx, filter, search, orderBy, expand, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.UnifiedRoleAssignmentResult{Ok: u}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.UnifiedRoleAssignmentList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.UnifiedRoleAssignmentResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
+	return paginate(ctx, s.msgraph,
+		func() ([]azure.UnifiedRoleAssignment, string, error) {
+			list, err := s.GetAzureADRoleAssignments(ctx, filter, search, orderBy, expand, selectCols, 999, false)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.UnifiedRoleAssignment]) azure.UnifiedRoleAssignmentResult {
+			return azure.UnifiedRoleAssignmentResult{
+				Error: result.Error,
+				Ok:    result.Ok,
+				Page:  result.Page,
 			}
-		}
-	}()
-	return out
+		},
+	)
 }
 
 func (s *azureClient) GetRoleAssignmentsForResource(ctx context.Context, resourceId string, filter string) (azure.RoleAssignmentList, error) {
@@ -135,59 +100,20 @@ func (s *azureClient) GetRoleAssignmentsForResource(ctx context.Context, resourc
 }
 
 func (s *azureClient) ListRoleAssignmentsForResource(ctx context.Context, resourceId string, filter string) <-chan azure.RoleAssignmentResult {
-	out := make(chan azure.RoleAssignmentResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.RoleAssignmentResult{ParentId: resourceId}
-			nextLink  string
-		)
-
-		if result, err := s.GetRoleAssignmentsForResource(ctx, resourceId, filter); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range result.Value {
-				out <- azure.RoleAssignmentResult{
-					ParentId: resourceId,
-					Ok:       u,
-				}
+	return paginate(ctx, s.resourceManager,
+		func() ([]azure.RoleAssignment, string, error) {
+			list, err := s.GetRoleAssignmentsForResource(ctx, resourceId, filter)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.RoleAssignment]) azure.RoleAssignmentResult {
+			return azure.RoleAssignmentResult{
+				ParentId: resourceId,
+				Error:    result.Error,
+				Ok:       result.Ok,
+				Page:     result.Page,
 			}
-
-			nextLink = result.NextLink
-			for nextLink != "" {
-				var list azure.RoleAssignmentList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.resourceManager.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.RoleAssignmentResult{
-							ParentId: resourceId,
-							Ok:       u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
 
 func (s *azureClient) GetResourceRoleAssignments(ctx context.Context, subscriptionId string, filter string, expand string) (azure.RoleAssignmentList, error) {
@@ -208,57 +134,18 @@ func (s *azureClient) GetResourceRoleAssignments(ctx context.Context, subscripti
 }
 
 func (s *azureClient) ListResourceRoleAssignments(ctx context.Context, subscriptionId string, filter string, expand string) <-chan azure.RoleAssignmentResult {
-	out := make(chan azure.RoleAssignmentResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.RoleAssignmentResult{ParentId: subscriptionId}
-			nextLink  string
-		)
-
-		if result, err := s.GetResourceRoleAssignments(ctx, subscriptionId, filter, expand); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range result.Value {
-				out <- azure.RoleAssignmentResult{
-					ParentId: subscriptionId,
-					Ok:       u,
-				}
-			}
-
-			nextLink = result.NextLink
-			for nextLink != "" {
-				var list azure.RoleAssignmentList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.resourceManager.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.RoleAssignmentResult{
-							ParentId: subscriptionId,
-							Ok:       u,
-						}
-					}
-					nextLink = list.NextLink
-				}
+	return paginate(ctx, s.resourceManager,
+		func() ([]azure.RoleAssignment, string, error) {
+			list, err := s.GetResourceRoleAssignments(ctx, subscriptionId, filter, expand)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.RoleAssignment]) azure.RoleAssignmentResult {
+			return azure.RoleAssignmentResult{
+				ParentId: subscriptionId,
+				Error:    result.Error,
+				Ok:       result.Ok,
+				Page:     result.Page,
 			}
-		}
-	}()
-	return out
+		},
+	)
 }
@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/bloodhoundad/azurehound/client/query"
 	"github.com/bloodhoundad/azurehound/client/rest"
@@ -61,51 +60,17 @@ func (s *azureClient) GetAzureADRoles(ctx context.Context, filter, expand string
 }
 
 func (s *azureClient) ListAzureADRoles(ctx context.Context, filter, expand string) <-chan azure.RoleResult {
-	out := make(chan azure.RoleResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.RoleResult{}
-			nextLink  string
-		)
-
-		if users, err := s.GetAzureADRoles(ctx, filter, expand); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range users.Value {
-				out <- azure.RoleResult{Ok: u}
+	return paginate(ctx, s.msgraph,
+		func() ([]azure.Role, string, error) {
+			list, err := s.GetAzureADRoles(ctx, filter, expand)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.Role]) azure.RoleResult {
+			return azure.RoleResult{
+				Error: result.Error,
+				Ok:    result.Ok,
+				Page:  result.Page,
 			}
-
-			nextLink = users.NextLink
-			for nextLink != "" {
-				var users azure.RoleList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &users); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range users.Value {
-						out <- azure.RoleResult{Ok: u}
-					}
-					nextLink = users.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
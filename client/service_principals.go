@@ -19,8 +19,8 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"net/url"
 	"strings"
 
 	"github.com/bloodhoundad/azurehound/client/query"
@@ -82,109 +82,34 @@ func (s *azureClient) GetAzureADServicePrincipals(ctx context.Context, filter, s
 }
 
 func (s *azureClient) ListAzureADServicePrincipals(ctx context.Context, filter, search, orderBy, expand string, selectCols []string) <-chan azure.ServicePrincipalResult {
-	out := make(chan azure.ServicePrincipalResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.ServicePrincipalResult{}
-			nextLink  string
-		)
-
-		if list, err := s.GetAzureADServicePrincipals(ctx, filter, search, orderBy, expand, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.ServicePrincipalResult{Ok: u}
+	return paginate(ctx, s.msgraph,
+		func() ([]azure.ServicePrincipal, string, error) {
+			list, err := s.GetAzureADServicePrincipals(ctx, filter, search, orderBy, expand, selectCols, 999, false)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.ServicePrincipal]) azure.ServicePrincipalResult {
+			return azure.ServicePrincipalResult{
+				Error: result.Error,
+				Ok:    result.Ok,
+				Page:  result.Page,
 			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.ServicePrincipalList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.ServicePrincipalResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
 
 func (s *azureClient) ListAzureADServicePrincipalOwners(ctx context.Context, objectId string, filter, search, orderBy string, selectCols []string) <-chan azure.ServicePrincipalOwnerResult {
-	out := make(chan azure.ServicePrincipalOwnerResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.ServicePrincipalOwnerResult{
+	return paginate(ctx, s.msgraph,
+		func() ([]json.RawMessage, string, error) {
+			list, err := s.GetAzureADServicePrincipalOwners(ctx, objectId, filter, search, orderBy, selectCols, 999, false)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[json.RawMessage]) azure.ServicePrincipalOwnerResult {
+			return azure.ServicePrincipalOwnerResult{
 				ServicePrincipalId: objectId,
+				Error:              result.Error,
+				Ok:                 result.Ok,
+				Page:               result.Page,
 			}
-			nextLink string
-		)
-
-		if list, err := s.GetAzureADServicePrincipalOwners(ctx, objectId, filter, search, orderBy, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.ServicePrincipalOwnerResult{
-					ServicePrincipalId: objectId,
-					Ok:                 u,
-				}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.DirectoryObjectList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.ServicePrincipalOwnerResult{
-							ServicePrincipalId: objectId,
-							Ok:                 u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
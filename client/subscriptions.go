@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/bloodhoundad/azurehound/client/query"
 	"github.com/bloodhoundad/azurehound/client/rest"
@@ -61,51 +60,17 @@ func (s *azureClient) GetAzureSubscriptions(ctx context.Context) (azure.Subscrip
 }
 
 func (s *azureClient) ListAzureSubscriptions(ctx context.Context) <-chan azure.SubscriptionResult {
-	out := make(chan azure.SubscriptionResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.SubscriptionResult{}
-			nextLink  string
-		)
-
-		if result, err := s.GetAzureSubscriptions(ctx); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range result.Value {
-				out <- azure.SubscriptionResult{Ok: u}
+	return paginate(ctx, s.resourceManager,
+		func() ([]azure.Subscription, string, error) {
+			list, err := s.GetAzureSubscriptions(ctx)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.Subscription]) azure.SubscriptionResult {
+			return azure.SubscriptionResult{
+				Error: result.Error,
+				Ok:    result.Ok,
+				Page:  result.Page,
 			}
-
-			nextLink = result.NextLink
-			for nextLink != "" {
-				var list azure.SubscriptionList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.resourceManager.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.SubscriptionResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/bloodhoundad/azurehound/client/query"
 	"github.com/bloodhoundad/azurehound/client/rest"
@@ -61,51 +60,17 @@ func (s *azureClient) GetAzureADTenants(ctx context.Context, includeAllTenantCat
 }
 
 func (s *azureClient) ListAzureADTenants(ctx context.Context, includeAllTenantCategories bool) <-chan azure.TenantResult {
-	out := make(chan azure.TenantResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.TenantResult{}
-			nextLink  string
-		)
-
-		if result, err := s.GetAzureADTenants(ctx, includeAllTenantCategories); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range result.Value {
-				out <- azure.TenantResult{Ok: u}
+	return paginate(ctx, s.resourceManager,
+		func() ([]azure.Tenant, string, error) {
+			list, err := s.GetAzureADTenants(ctx, includeAllTenantCategories)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.Tenant]) azure.TenantResult {
+			return azure.TenantResult{
+				Error: result.Error,
+				Ok:    result.Ok,
+				Page:  result.Page,
 			}
-
-			nextLink = result.NextLink
-			for nextLink != "" {
-				var list azure.TenantList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.resourceManager.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.TenantResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
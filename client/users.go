@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 	"strings"
 
 	"github.com/bloodhoundad/azurehound/client/query"
@@ -67,51 +66,17 @@ func (s *azureClient) GetAzureADUsers(ctx context.Context, filter string, search
 }
 
 func (s *azureClient) ListAzureADUsers(ctx context.Context, filter string, search string, orderBy string, selectCols []string) <-chan azure.UserResult {
-	out := make(chan azure.UserResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.UserResult{}
-			nextLink  string
-		)
-
-		if users, err := s.GetAzureADUsers(ctx, filter, search, orderBy, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range users.Value {
-				out <- azure.UserResult{Ok: u}
+	return paginate(ctx, s.msgraph,
+		func() ([]azure.User, string, error) {
+			list, err := s.GetAzureADUsers(ctx, filter, search, orderBy, selectCols, 999, false)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.User]) azure.UserResult {
+			return azure.UserResult{
+				Error: result.Error,
+				Ok:    result.Ok,
+				Page:  result.Page,
 			}
-
-			nextLink = users.NextLink
-			for nextLink != "" {
-				var users azure.UserList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &users); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range users.Value {
-						out <- azure.UserResult{Ok: u}
-					}
-					nextLink = users.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/bloodhoundad/azurehound/client/query"
 	"github.com/bloodhoundad/azurehound/client/rest"
@@ -61,56 +60,18 @@ func (s *azureClient) GetAzureVirtualMachines(ctx context.Context, subscriptionI
 }
 
 func (s *azureClient) ListAzureVirtualMachines(ctx context.Context, subscriptionId string, statusOnly bool) <-chan azure.VirtualMachineResult {
-	out := make(chan azure.VirtualMachineResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.VirtualMachineResult{
+	return paginate(ctx, s.resourceManager,
+		func() ([]azure.VirtualMachine, string, error) {
+			list, err := s.GetAzureVirtualMachines(ctx, subscriptionId, statusOnly)
+			return list.Value, list.NextLink, err
+		},
+		func(result PageResult[azure.VirtualMachine]) azure.VirtualMachineResult {
+			return azure.VirtualMachineResult{
 				SubscriptionId: subscriptionId,
+				Error:          result.Error,
+				Ok:             result.Ok,
+				Page:           result.Page,
 			}
-			nextLink string
-		)
-
-		if result, err := s.GetAzureVirtualMachines(ctx, subscriptionId, statusOnly); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range result.Value {
-				out <- azure.VirtualMachineResult{SubscriptionId: subscriptionId, Ok: u}
-			}
-
-			nextLink = result.NextLink
-			for nextLink != "" {
-				var list azure.VirtualMachineList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.resourceManager.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.VirtualMachineResult{
-							SubscriptionId: "/subscriptions/" + subscriptionId,
-							Ok:             u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+		},
+	)
 }
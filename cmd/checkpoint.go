@@ -0,0 +1,102 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/bloodhoundad/azurehound/checkpoint"
+	"github.com/bloodhoundad/azurehound/config"
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/sinks"
+)
+
+// checkpoints records the progress of the collection written to the output file, if any
+var checkpoints *checkpoint.Store
+
+func checkpointFile(output string) string {
+	return output + ".checkpoint"
+}
+
+// openCheckpoints opens the checkpoint store kept alongside the output file, resuming it if requested
+func openCheckpoints() error {
+	var (
+		output = config.OutputFile.Value().(string)
+		format = config.OutputFormat.Value().(string)
+		resume = config.Resume.Value().(bool)
+		err    error
+	)
+
+	if output == "" || !sinks.Appendable(output, format) || config.SplitOutput.Value().(bool) || config.ZipOutput.Value().(bool) {
+		if resume {
+			return fmt.Errorf("only collections to an uncompressed output file may be resumed")
+		}
+		return nil
+	} else if !resume {
+		checkpoints, err = checkpoint.Create(checkpointFile(output))
+		return err
+	} else if checkpoints, err = checkpoint.Open(checkpointFile(output)); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("no interrupted collection to resume: %w", err)
+	} else if err != nil {
+		return err
+	} else {
+		log.Info("resuming collection", "output", output, "completed", checkpoints.Resumed())
+		return nil
+	}
+}
+
+// closeCheckpoints closes the checkpoint store, removing it unless the collection was interrupted
+func closeCheckpoints(ctx context.Context) {
+	if err := checkpoints.Close(); err != nil {
+		log.Error(err, "unable to close checkpoint file")
+	} else if checkpoints != nil && ctx.Err() == nil {
+		if err := os.Remove(checkpointFile(config.OutputFile.Value().(string))); err != nil {
+			log.Error(err, "unable to remove checkpoint file")
+		}
+	}
+	checkpoints = nil
+}
+
+// completed returns true if the run being resumed finished enumerating the children of kind for the parent
+func completed(kind enums.Kind, parentId string) bool {
+	return checkpoints.Done(string(kind), parentId)
+}
+
+// checkpointWritten records an object's completion once it has been written to the output, unless it has no key
+func checkpointWritten[T any](item AzureWrapper[T]) {
+	if item.id == "" {
+		return
+	} else if err := checkpoints.Complete(string(item.Kind), item.id); err != nil {
+		log.Error(err, "unable to record checkpoint", "kind", item.Kind)
+	}
+}
+
+// checkpointKey returns the key under which a parent is checkpointed once its children have been written. A parent
+// whose children could not all be listed has no key, so that a resumed collection lists them again. A parent whose
+// listing was cut short because the collection was interrupted is not written at all.
+func checkpointKey(parentId string, failed bool) string {
+	if failed {
+		return ""
+	} else {
+		return parentId
+	}
+}
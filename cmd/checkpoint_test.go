@@ -0,0 +1,186 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/checkpoint"
+	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/client/fake"
+	"github.com/bloodhoundad/azurehound/config"
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/bloodhoundad/azurehound/sinks"
+)
+
+func TestResumeCollection(t *testing.T) {
+	tenant, err := fake.ReadTenant("../client/fake/testdata/tenant.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := fake.NewServer(tenant)
+	defer server.Close()
+
+	azClient, err := client.NewClient(server.Config())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	selection, err := newKindSelection([]string{"AZGroup", "AZGroupMember"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := filepath.Join(t.TempDir(), "output.json")
+	config.OutputFile.Set(output)
+	config.Resume.Set(true)
+	defer config.OutputFile.Set("")
+	defer config.Resume.Set(false)
+
+	// a collection interrupted once the members of the first group were written
+	interrupted := AzureWrapper[models.GroupMembers]{
+		Kind: enums.KindAZGroupMember,
+		Data: models.GroupMembers{GroupId: tenant.Groups[0].Id},
+	}
+	if bytes, err := json.Marshal(interrupted); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := os.WriteFile(output, []byte(fmt.Sprintf("{\n\t\"data\": [\n\t\t%s", bytes)), 0666); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if store, err := checkpoint.Create(checkpointFile(output)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := store.Complete(string(enums.KindAZGroupMember), tenant.Groups[0].Id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else {
+		store.Close()
+	}
+
+	if err := openCheckpoints(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+	outputStream(ctx, listAll(ctx, azClient, selection))
+
+	var document struct {
+		Data []AzureWrapper[json.RawMessage] `json:"data"`
+		Meta models.Meta                     `json:"meta"`
+	}
+	kinds := map[enums.Kind]int{}
+	if content, err := os.ReadFile(output); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := json.Unmarshal(content, &document); err != nil {
+		t.Fatalf("output is not valid json: %v", err)
+	} else {
+		for _, item := range document.Data {
+			kinds[item.Kind]++
+		}
+	}
+
+	if kinds[enums.KindAZGroup] != len(tenant.Groups) {
+		t.Errorf("got %v %v, want %v", kinds[enums.KindAZGroup], enums.KindAZGroup, len(tenant.Groups))
+	}
+	if kinds[enums.KindAZGroupMember] != len(tenant.Groups) {
+		t.Errorf("got %v %v, want %v", kinds[enums.KindAZGroupMember], enums.KindAZGroupMember, len(tenant.Groups))
+	}
+	if document.Meta.Count != len(document.Data) {
+		t.Errorf("got count %v, want %v", document.Meta.Count, len(document.Data))
+	}
+	if requests := server.Requests(fmt.Sprintf("/v1.0/groups/%s/members", tenant.Groups[0].Id)); requests != 0 {
+		t.Errorf("got %v requests for the members of the completed group, want 0", requests)
+	}
+	if _, err := os.Stat(checkpointFile(output)); !os.IsNotExist(err) {
+		t.Error("expected the checkpoint file to be removed once the collection completed")
+	}
+}
+
+func TestResumeFailedChildren(t *testing.T) {
+	tenant, err := fake.ReadTenant("../client/fake/testdata/tenant.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenant.Groups = append(tenant.Groups, azure.Group{DirectoryObject: azure.DirectoryObject{Id: "0e5a7c1d-4b3f-4d2e-9a8b-1c2d3e4f5a6b"}, DisplayName: "Operations"})
+
+	server := fake.NewServer(tenant)
+	defer server.Close()
+
+	azClient, err := client.NewClient(server.Config())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	selection, err := newKindSelection([]string{"AZGroup", "AZGroupMember"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := filepath.Join(t.TempDir(), "output.json")
+	config.OutputFile.Set(output)
+	defer config.OutputFile.Set("")
+	defer config.Resume.Set(false)
+
+	// the members of the first group fail to list, with an error that is not retried
+	var (
+		failed      = fmt.Sprintf("/v1.0/groups/%s/members", tenant.Groups[0].Id)
+		listed      = fmt.Sprintf("/v1.0/groups/%s/members", tenant.Groups[1].Id)
+		ctx         = context.Background()
+		failedGroup = tenant.Groups[0].Id
+	)
+	server.Inject(fake.Fault{Path: regexp.MustCompile("^" + regexp.QuoteMeta(failed) + "$"), StatusCode: http.StatusBadRequest, Code: "BadRequest", Times: 1})
+
+	config.Resume.Set(false)
+	if err := openCheckpoints(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// written as outputStream does, but interrupted before the checkpoint file is removed
+	if err := sinks.WriteToFile(ctx, output, enums.OutputFormatJson, false, listAll(ctx, azClient, selection), checkpointWritten[interface{}]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkpoints.Close()
+	if store, err := checkpoint.Open(checkpointFile(output)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if store.Done(string(enums.KindAZGroupMember), failedGroup) {
+		t.Error("expected the group whose members failed to list not to be checkpointed")
+	} else if !store.Done(string(enums.KindAZGroupMember), tenant.Groups[1].Id) {
+		t.Error("expected the group whose members were listed to be checkpointed")
+	} else {
+		store.Close()
+	}
+
+	config.Resume.Set(true)
+	if err := openCheckpoints(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	outputStream(ctx, listAll(ctx, azClient, selection))
+	closeCheckpoints(ctx)
+
+	if requests := server.Requests(failed); requests != 2 {
+		t.Errorf("got %v requests for the members of the failed group, want 2", requests)
+	}
+	if requests := server.Requests(listed); requests != 1 {
+		t.Errorf("got %v requests for the members of the completed group, want 1", requests)
+	}
+}
@@ -36,6 +36,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/bloodhoundad/azurehound/client/rest"
 	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/gofrs/uuid"
@@ -76,10 +77,10 @@ func configure() error {
 		return err
 	} else if tenantId, err := prompt("Directory (tenant) ID", validateGuid, false); err != nil {
 		return err
-	} else if appId, err := prompt("Application (client) ID", validateGuid, false); err != nil {
-		return err
 	} else if _, authMethod, err := choose("Authentication Method", enums.AuthMethods(), 0); err != nil {
 		return err
+	} else if appId, err := promptAppId(authMethod); err != nil {
+		return err
 	} else {
 		config.AzRegion.Set(region)
 		config.AzTenant.Set(tenantId)
@@ -94,16 +95,15 @@ func configure() error {
 					config.AzKey.Set(genKeyPath)
 					config.AzKeyPass.Set(keyPass)
 				}
-			} else if certPath, err := prompt("Public Certificate Path", validatePem, false); err != nil {
-				return err
-			} else if keyPath, err := prompt("Private Key Path", validatePem, false); err != nil {
+			} else if certPath, err := prompt("Certificate Path (PEM or PFX)", validateCertificate, false); err != nil {
 				return err
-			} else if keyPass, err := prompt("Private Key Passphrase (optional)", nil, true); err != nil {
+			} else if keyPath, keyPass, err := promptCertificateKey(certPath); err != nil {
 				return err
 			} else {
 				config.AzCert.Set(certPath)
 				config.AzKey.Set(keyPath)
 				config.AzKeyPass.Set(keyPass)
+				config.AzSendCertChain.Set(confirm("Send Certificate Chain for Subject Name and Issuer Authentication", false))
 			}
 		} else if authMethod == enums.UsernamePassword {
 			if upn, err := prompt("Input the User Principal Name", validateUserPrincipalName, false); err != nil {
@@ -114,6 +114,20 @@ func configure() error {
 				config.AzUsername.Set(upn)
 				config.AzPassword.Set(password)
 			}
+		} else if authMethod == enums.DeviceCode {
+			config.AzDeviceCode.Set(true)
+		} else if authMethod == enums.ManagedIdentity {
+			config.AzManagedIdentity.Set(true)
+		} else if authMethod == enums.MSALTokenCache {
+			if cachePath, err := prompt("MSAL Token Cache Path", validateMSALCache, false); err != nil {
+				return err
+			} else if account, err := chooseMSALAccount(cachePath); err != nil {
+				return err
+			} else {
+				config.AzMsalCache.Set(cachePath)
+				config.AzMsalAccount.Set(account.Username)
+				config.AzTenant.Set(account.Realm)
+			}
 		} else if secret, err := prompt("Client Secret", nil, true); err != nil {
 			return err
 		} else {
@@ -193,6 +207,57 @@ func configure() error {
 	return nil
 }
 
+func promptAppId(authMethod string) (string, error) {
+	if authMethod == enums.ManagedIdentity {
+		// only user-assigned identities are identified by a client id
+		return prompt("User-assigned Identity Client ID (optional)", validateOptionalGuid, false)
+	} else if authMethod == enums.MSALTokenCache {
+		// the refresh token is redeemed by the client it was issued to
+		return "", nil
+	} else {
+		return prompt("Application (client) ID", validateGuid, false)
+	}
+}
+
+// promptCertificateKey prompts for the private key of a PEM certificate, or for the password of a PFX certificate
+// which contains its own key
+func promptCertificateKey(certPath string) (string, string, error) {
+	if content, err := ioutil.ReadFile(certPath); err != nil {
+		return "", "", err
+	} else if rest.IsPKCS12(content) {
+		password, err := prompt("PFX Password (optional)", func(input string) error {
+			_, _, err := rest.ReadPFX(content, input)
+			return err
+		}, true)
+		return "", password, err
+	} else if keyPath, err := prompt("Private Key Path", validatePem, false); err != nil {
+		return "", "", err
+	} else if keyPass, err := prompt("Private Key Passphrase (optional)", nil, true); err != nil {
+		return "", "", err
+	} else {
+		return keyPath, keyPass, nil
+	}
+}
+
+func chooseMSALAccount(cachePath string) (rest.MSALAccount, error) {
+	if cache, err := rest.ReadMSALCache(cachePath); err != nil {
+		return rest.MSALAccount{}, err
+	} else if accounts := cache.ListAccounts(); len(accounts) == 0 {
+		return rest.MSALAccount{}, fmt.Errorf("no accounts found in MSAL token cache")
+	} else {
+		items := make([]string, len(accounts))
+		for i, account := range accounts {
+			items[i] = account.String()
+		}
+
+		if idx, _, err := choose("Account", items, 0); err != nil {
+			return rest.MSALAccount{}, err
+		} else {
+			return accounts[idx], nil
+		}
+	}
+}
+
 func prompt(label string, validator func(string) error, isSensitive bool) (string, error) {
 	p := promptui.Prompt{
 		Label:    label,
@@ -235,6 +300,19 @@ func validateGuid(input string) error {
 	return err
 }
 
+func validateOptionalGuid(input string) error {
+	if input == "" {
+		return nil
+	} else {
+		return validateGuid(input)
+	}
+}
+
+func validateMSALCache(input string) error {
+	_, err := rest.ReadMSALCache(input)
+	return err
+}
+
 func validatePem(input string) error {
 	if content, err := ioutil.ReadFile(input); err != nil {
 		return err
@@ -245,6 +323,17 @@ func validatePem(input string) error {
 	}
 }
 
+func validateCertificate(input string) error {
+	if content, err := ioutil.ReadFile(input); err != nil {
+		return err
+	} else if rest.IsPKCS12(content) {
+		// PFX bundles are validated once their password is known
+		return nil
+	} else {
+		return validatePem(input)
+	}
+}
+
 func validateUserPrincipalName(input string) error {
 	_, err := mail.ParseAddress(input)
 	return err
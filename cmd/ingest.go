@@ -0,0 +1,384 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client/rest"
+	"github.com/bloodhoundad/azurehound/config"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/pipeline"
+	"github.com/bloodhoundad/azurehound/spool"
+)
+
+const (
+	maxBatchItems int = 999
+	maxBatchBytes int = 10 << 20
+)
+
+// ingestResult counts the batches, and the objects within them, that BloodHound Enterprise accepted or did not
+type ingestResult struct {
+	AcceptedBatches int
+	AcceptedObjects int
+	FailedBatches   int
+	FailedObjects   int
+	Bytes           int64         // The compressed size of the accepted batches
+	Elapsed         time.Duration // How long requests were in flight for, excluding backoff between retries
+}
+
+func (s *ingestResult) add(other ingestResult) {
+	s.AcceptedBatches += other.AcceptedBatches
+	s.AcceptedObjects += other.AcceptedObjects
+	s.FailedBatches += other.FailedBatches
+	s.FailedObjects += other.FailedObjects
+	s.Bytes += other.Bytes
+	s.Elapsed += other.Elapsed
+}
+
+// throughput describes the rate at which the accepted batches were uploaded
+func (s ingestResult) throughput() string {
+	if seconds := s.Elapsed.Seconds(); seconds == 0 {
+		return "n/a"
+	} else {
+		return fmt.Sprintf("%.2f MiB/s, %.0f objects/s", float64(s.Bytes)/(1<<20)/seconds, float64(s.AcceptedObjects)/seconds)
+	}
+}
+
+// ingestError is returned when BloodHound Enterprise responds to an ingest request with an unsuccessful status
+type ingestError struct {
+	StatusCode int
+	Message    string
+}
+
+func (s ingestError) Error() string {
+	return fmt.Sprintf("bloodhound enterprise responded with %d %s: %s", s.StatusCode, http.StatusText(s.StatusCode), s.Message)
+}
+
+// batchForIngest encodes the stream and groups it into batches limited by both count and size
+func batchForIngest(ctx context.Context, stream <-chan AzureWrapper[interface{}]) <-chan []json.RawMessage {
+	items := pipeline.Map(ctx.Done(), stream, func(item AzureWrapper[interface{}]) json.RawMessage {
+		if encoded, err := json.Marshal(item); err != nil {
+			panic(err)
+		} else {
+			return encoded
+		}
+	})
+	return pipeline.BatchBySize(ctx.Done(), items, maxBatchItems, maxBatchBytes, func(item json.RawMessage) int { return len(item) }, 10*time.Second)
+}
+
+// spoolBatches writes each batch to the spool for the uploader, blocking while the spool is full
+func spoolBatches(ctx context.Context, bheSpool *spool.Spool, in <-chan []json.RawMessage) error {
+	for data := range pipeline.OrDone(ctx.Done(), in) {
+		body := models.IngestRequest{
+			Meta: models.Meta{
+				Type: "azure",
+			},
+			Data: data,
+		}
+
+		if encoded, err := json.Marshal(body); err != nil {
+			return err
+		} else if err := bheSpool.Put(ctx, encoded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uploader drains the spool to BloodHound Enterprise with a number of workers. Batches that cannot be delivered while
+// BloodHound Enterprise is unavailable are kept in the spool until they can be.
+type uploader struct {
+	spool    *spool.Spool
+	endpoint *url.URL
+	client   *http.Client
+	policy   rest.RetryPolicy
+	workers  int
+	mutex    sync.Mutex
+	result   ingestResult
+	active   int       // The number of requests in flight
+	started  time.Time // When a request was last sent with none in flight, or the result was last taken
+}
+
+func newUploader(bheUrl url.URL, bheClient *http.Client, policy rest.RetryPolicy, workers int, bheSpool *spool.Spool) *uploader {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &uploader{
+		spool:    bheSpool,
+		endpoint: bheUrl.ResolveReference(&url.URL{Path: "/api/v1/ingest"}),
+		client:   bheClient,
+		policy:   policy,
+		workers:  workers,
+	}
+}
+
+// run uploads spooled batches until the context is done or the spool is closed
+func (s *uploader) run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(s.workers)
+	for i := 0; i < s.workers; i++ {
+		go func() {
+			defer wg.Done()
+			s.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// work uploads spooled batches one at a time, retrying each until it has been uploaded or rejected
+func (s *uploader) work(ctx context.Context) {
+	for {
+		record, err := s.spool.Next(ctx)
+		if err != nil {
+			return
+		}
+
+		for retries := 0; ; retries++ {
+			if result, err := s.upload(ctx, record.Data, retries); err != nil {
+				log.Error(err, "unable to upload spooled batch to bloodhound enterprise; retrying later", "retryIn", s.policy.MaxBackoff.String())
+				if rest.Sleep(ctx, s.policy.MaxBackoff) != nil {
+					return
+				}
+			} else {
+				// counted before the batch leaves the spool so that the count is complete once the spool is drained
+				s.count(result)
+				if err := s.spool.Ack(record); err != nil {
+					log.Error(err, "unable to remove uploaded batch from spool")
+				}
+				break
+			}
+		}
+	}
+}
+
+// begin marks a request as in flight so that the time spent waiting between retries is not counted as uploading
+func (s *uploader) begin() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.active == 0 {
+		s.started = time.Now()
+	}
+	s.active++
+}
+
+func (s *uploader) end() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.active--; s.active == 0 {
+		s.result.Elapsed += time.Since(s.started)
+	}
+}
+
+func (s *uploader) count(result ingestResult) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.result.add(result)
+}
+
+// take returns the batches uploaded since it was last called
+func (s *uploader) take() ingestResult {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.active > 0 {
+		s.result.Elapsed += time.Since(s.started)
+		s.started = time.Now()
+	}
+
+	result := s.result
+	s.result = ingestResult{}
+	return result
+}
+
+// upload sends a spooled batch, halving it if BloodHound Enterprise reports that it is too large. Batches that
+// BloodHound Enterprise rejects outright, or that are still unauthorized after being retried later as often as the
+// retry policy allows, are set aside rather than retried.
+func (s *uploader) upload(ctx context.Context, record []byte, retries int) (ingestResult, error) {
+	var body struct {
+		Meta models.Meta       `json:"meta"`
+		Data []json.RawMessage `json:"data"`
+	}
+
+	if err := json.Unmarshal(record, &body); err != nil {
+		return s.reject(record, 0, err), nil
+	} else if sent, err := s.send(ctx, record); err == nil {
+		return ingestResult{AcceptedBatches: 1, AcceptedObjects: len(body.Data), Bytes: sent}, nil
+	} else if statusErr, ok := err.(ingestError); !ok {
+		return ingestResult{}, err
+	} else if statusErr.StatusCode == http.StatusRequestEntityTooLarge && len(body.Data) > 1 {
+		log.V(1).Info("batch too large for bloodhound enterprise; splitting", "objects", len(body.Data))
+		var result ingestResult
+		half := len(body.Data) / 2
+		for _, data := range [][]json.RawMessage{body.Data[:half], body.Data[half:]} {
+			if encoded, err := json.Marshal(models.IngestRequest{Meta: body.Meta, Data: data}); err != nil {
+				return ingestResult{}, err
+			} else if partResult, err := s.upload(ctx, encoded, retries); err != nil {
+				return ingestResult{}, err
+			} else {
+				result.add(partResult)
+			}
+		}
+		return result, nil
+	} else if isRejected(statusErr.StatusCode, retries, s.policy.MaxRetries) {
+		return s.reject(record, len(body.Data), err), nil
+	} else {
+		return ingestResult{}, err
+	}
+}
+
+// reject sets the batch aside in the spool's rejected directory so that its data is not lost
+func (s *uploader) reject(record []byte, objects int, reason error) ingestResult {
+	log.Error(reason, "bloodhound enterprise rejected batch", "objects", objects)
+	if path, err := rejectBatch(record); err != nil {
+		log.Error(err, "unable to keep rejected batch; data has been lost", "objects", objects)
+	} else {
+		log.Info("kept rejected batch", "path", path, "objects", objects)
+	}
+	return ingestResult{FailedBatches: 1, FailedObjects: objects}
+}
+
+// isRejected returns true if the status shows that the request will not succeed however often it is retried. An
+// unauthorized request may succeed once the credentials are fixed, so it is only rejected once it has been retried
+// maxRetries times.
+func isRejected(statusCode int, retries int, maxRetries int) bool {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return retries >= maxRetries
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	default:
+		return statusCode >= 400 && statusCode < 500
+	}
+}
+
+// send posts the body to the ingest endpoint, gzip compressed, retrying transient errors and retryable statuses.
+// It returns the number of bytes sent.
+func (s *uploader) send(ctx context.Context, body []byte) (int64, error) {
+	compressed := &bytes.Buffer{}
+	writer := gzip.NewWriter(compressed)
+	if _, err := writer.Write(body); err != nil {
+		return 0, err
+	} else if err := writer.Close(); err != nil {
+		return 0, err
+	}
+
+	for retry := 0; ; retry++ {
+		req, err := http.NewRequestWithContext(ctx, "POST", s.endpoint.String(), bytes.NewReader(compressed.Bytes()))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+
+		s.begin()
+		res, err := s.client.Do(req)
+		if err == nil {
+			if res.StatusCode >= 200 && res.StatusCode < 300 {
+				io.Copy(io.Discard, res.Body)
+				res.Body.Close()
+				s.end()
+				return int64(compressed.Len()), nil
+			} else {
+				message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+				io.Copy(io.Discard, res.Body)
+				res.Body.Close()
+				err = ingestError{StatusCode: res.StatusCode, Message: strings.TrimSpace(string(message))}
+			}
+		}
+		s.end()
+
+		if !shouldRetryIngest(s.policy, retry, res, err) {
+			return 0, err
+		} else {
+			backoff := s.policy.Backoff(retry, res)
+			log.V(1).Info("retrying ingest request", "error", err.Error(), "retry", retry+1, "backoff", backoff.String())
+			if rest.Sleep(ctx, backoff) != nil {
+				return 0, err
+			}
+		}
+	}
+}
+
+// shouldRetryIngest extends the retry policy to the server errors BloodHound Enterprise returns while it is unhealthy
+func shouldRetryIngest(policy rest.RetryPolicy, retry int, res *http.Response, err error) bool {
+	if statusErr, ok := err.(ingestError); ok {
+		switch statusErr.StatusCode {
+		case http.StatusInternalServerError, http.StatusBadGateway:
+			return retry < policy.MaxRetries
+		default:
+			return policy.ShouldRetry(retry, res, nil)
+		}
+	} else {
+		return policy.ShouldRetry(retry, nil, err)
+	}
+}
+
+// spoolDir returns the directory that collected data is spooled in until it is uploaded
+func spoolDir() string {
+	if dir := config.BHESpoolDir.Value().(string); dir != "" {
+		return dir
+	} else {
+		return filepath.Join(filepath.Dir(config.DefaultConfigFile), "spool")
+	}
+}
+
+func openSpool() (*spool.Spool, error) {
+	return spool.Open(spoolDir(), spool.Options{
+		MaxSize:   int64(config.BHESpoolMaxSize.Value().(int)) << 20,
+		Retention: time.Duration(config.BHESpoolRetention.Value().(int)) * time.Hour,
+		Expired: func(batches int) {
+			log.Info("dropped spooled batches that were not uploaded within the retention period", "batches", batches)
+		},
+	})
+}
+
+// rejectBatch writes the ingest request to a new file in the spool's rejected directory, returning its path
+func rejectBatch(record []byte) (string, error) {
+	dir := filepath.Join(spoolDir(), "rejected")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	} else if file, err := os.CreateTemp(dir, fmt.Sprintf("ingest_%s_*.json", time.Now().Format("20060102150405"))); err != nil {
+		return "", err
+	} else if _, err := file.Write(record); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", err
+	} else if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	} else {
+		return file.Name(), nil
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client/rest"
+	"github.com/bloodhoundad/azurehound/config"
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models"
+)
+
+func TestUpload(t *testing.T) {
+	policy := rest.RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+	tests := map[string]struct {
+		respond  func(request int, objects int) int
+		batch    int
+		requests int
+		expected ingestResult
+		rejected int
+	}{
+		"accepted": {
+			respond:  func(int, int) int { return http.StatusAccepted },
+			batch:    3,
+			requests: 1,
+			expected: ingestResult{AcceptedBatches: 1, AcceptedObjects: 3},
+		},
+		"retried": {
+			respond: func(request, _ int) int {
+				if request < 2 {
+					return http.StatusServiceUnavailable
+				}
+				return http.StatusOK
+			},
+			batch:    3,
+			requests: 3,
+			expected: ingestResult{AcceptedBatches: 1, AcceptedObjects: 3},
+		},
+		"kept through an outage": {
+			respond: func(request, _ int) int {
+				if request < 4 {
+					return http.StatusInternalServerError
+				}
+				return http.StatusOK
+			},
+			batch:    3,
+			requests: 5,
+			expected: ingestResult{AcceptedBatches: 1, AcceptedObjects: 3},
+		},
+		"unauthorized is retried later": {
+			respond: func(request, _ int) int {
+				if request < 1 {
+					return http.StatusUnauthorized
+				}
+				return http.StatusOK
+			},
+			batch:    3,
+			requests: 2,
+			expected: ingestResult{AcceptedBatches: 1, AcceptedObjects: 3},
+		},
+		"unauthorized is rejected once retries are exhausted": {
+			respond:  func(int, int) int { return http.StatusUnauthorized },
+			batch:    3,
+			requests: 3,
+			expected: ingestResult{FailedBatches: 1, FailedObjects: 3},
+			rejected: 1,
+		},
+		"bad request is rejected": {
+			respond:  func(int, int) int { return http.StatusBadRequest },
+			batch:    3,
+			requests: 1,
+			expected: ingestResult{FailedBatches: 1, FailedObjects: 3},
+			rejected: 1,
+		},
+		"too large is split": {
+			respond: func(_, objects int) int {
+				if objects > 2 {
+					return http.StatusRequestEntityTooLarge
+				}
+				return http.StatusOK
+			},
+			batch:    5,
+			requests: 5,
+			expected: ingestResult{AcceptedBatches: 3, AcceptedObjects: 5},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var (
+				mutex    sync.Mutex
+				requests int
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body := decodeIngest(t, r)
+				mutex.Lock()
+				status := test.respond(requests, len(body.Data.([]interface{})))
+				requests++
+				mutex.Unlock()
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			dir := t.TempDir()
+			config.BHESpoolDir.Set(dir)
+			defer config.BHESpoolDir.Set("")
+
+			bheSpool, err := openSpool()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer bheSpool.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			stream := make(chan AzureWrapper[interface{}], test.batch)
+			for i := 0; i < test.batch; i++ {
+				stream <- AzureWrapper[interface{}]{Kind: enums.KindAZUser, Data: i}
+			}
+			close(stream)
+			if err := spoolBatches(ctx, bheSpool, batchForIngest(ctx, stream)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			bheUrl, _ := url.Parse(server.URL)
+			uploader := newUploader(*bheUrl, server.Client(), policy, 1, bheSpool)
+			go uploader.run(ctx)
+
+			if err := bheSpool.Drain(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result := uploader.take()
+			if result.AcceptedBatches > 0 && result.Bytes == 0 {
+				t.Error("got no uploaded bytes for accepted batches")
+			}
+			result.Bytes, result.Elapsed = 0, 0
+			if result != test.expected {
+				t.Errorf("got %+v, want %+v", result, test.expected)
+			}
+			mutex.Lock()
+			if requests != test.requests {
+				t.Errorf("got %d requests, want %d", requests, test.requests)
+			}
+			mutex.Unlock()
+
+			if files, err := filepath.Glob(filepath.Join(dir, "rejected", "*.json")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if len(files) != test.rejected {
+				t.Errorf("got %d rejected batches, want %d", len(files), test.rejected)
+			} else {
+				for _, file := range files {
+					var body models.IngestRequest
+					if content, err := os.ReadFile(file); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					} else if err := json.Unmarshal(content, &body); err != nil {
+						t.Errorf("rejected batch is not an ingest request: %v", err)
+					} else if len(body.Data.([]interface{})) != test.batch {
+						t.Errorf("got %d rejected objects, want %d", len(body.Data.([]interface{})), test.batch)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUploadParallel(t *testing.T) {
+	const (
+		batches   = 8
+		tokenId   = "token-id"
+		token     = "token"
+		signature = "bhesignature"
+	)
+
+	var (
+		mutex     sync.Mutex
+		active    int
+		maxActive int
+		objects   int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		// the signature covers the body as sent, i.e. compressed
+		digester := hmac.New(sha256.New, []byte(token))
+		digester.Write([]byte(r.Method + r.URL.Path))
+		digester = hmac.New(sha256.New, digester.Sum(nil))
+		digester.Write([]byte(r.Header.Get("RequestDate")[:13]))
+		digester = hmac.New(sha256.New, digester.Sum(nil))
+		digester.Write(raw)
+		if r.Header.Get("Signature") != base64.StdEncoding.EncodeToString(digester.Sum(nil)) {
+			t.Error("got an ingest request with an invalid signature")
+		} else if r.Header.Get("Authorization") != signature+" "+tokenId {
+			t.Errorf("got authorization %s", r.Header.Get("Authorization"))
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(raw))
+		body := decodeIngest(t, r)
+
+		mutex.Lock()
+		if active++; active > maxActive {
+			maxActive = active
+		}
+		mutex.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mutex.Lock()
+		active--
+		objects += len(body.Data.([]interface{}))
+		mutex.Unlock()
+	}))
+	defer server.Close()
+
+	config.BHESpoolDir.Set(t.TempDir())
+	defer config.BHESpoolDir.Set("")
+
+	bheSpool, err := openSpool()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer bheSpool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	in := make(chan []json.RawMessage, batches)
+	for i := 0; i < batches; i++ {
+		in <- []json.RawMessage{json.RawMessage(fmt.Sprintf(`{"kind":"AZUser","data":%d}`, i))}
+	}
+	close(in)
+	if err := spoolBatches(ctx, bheSpool, in); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bheClient, err := newSigningHttpClient(signature, tokenId, token, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bheUrl, _ := url.Parse(server.URL)
+	uploader := newUploader(*bheUrl, bheClient, rest.DefaultRetryPolicy(), 4, bheSpool)
+	go uploader.run(ctx)
+
+	if err := bheSpool.Drain(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := uploader.take()
+	mutex.Lock()
+	defer mutex.Unlock()
+	if result.AcceptedBatches != batches || objects != batches {
+		t.Errorf("got %d accepted batches and %d objects, want %d", result.AcceptedBatches, objects, batches)
+	} else if maxActive < 2 {
+		t.Errorf("got at most %d concurrent uploads, want several", maxActive)
+	} else if result.Elapsed <= 0 || result.throughput() == "n/a" {
+		t.Errorf("got no upload throughput for %+v", result)
+	}
+}
+
+func TestUploadElapsed(t *testing.T) {
+	const backoff = 200 * time.Millisecond
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	config.BHESpoolDir.Set(t.TempDir())
+	defer config.BHESpoolDir.Set("")
+
+	bheSpool, err := openSpool()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer bheSpool.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	in := make(chan []json.RawMessage, 1)
+	in <- []json.RawMessage{json.RawMessage(`{"kind":"AZUser","data":0}`)}
+	close(in)
+	if err := spoolBatches(ctx, bheSpool, in); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bheUrl, _ := url.Parse(server.URL)
+	policy := rest.RetryPolicy{MaxRetries: 1, MinBackoff: backoff, MaxBackoff: backoff}
+	uploader := newUploader(*bheUrl, server.Client(), policy, 1, bheSpool)
+	go uploader.run(ctx)
+
+	if err := bheSpool.Drain(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the backoff between the two requests is not time spent uploading
+	if result := uploader.take(); result.AcceptedBatches != 1 {
+		t.Errorf("got %d accepted batches, want 1", result.AcceptedBatches)
+	} else if result.Elapsed <= 0 || result.Elapsed >= backoff {
+		t.Errorf("got %s elapsed, want less than the %s backoff", result.Elapsed, backoff)
+	}
+}
+
+// decodeIngest decodes the gzip compressed ingest request
+func decodeIngest(t *testing.T, r *http.Request) models.IngestRequest {
+	var body models.IngestRequest
+	if r.Header.Get("Content-Encoding") != "gzip" {
+		t.Errorf("got content encoding %q, want gzip", r.Header.Get("Content-Encoding"))
+	} else if reader, err := gzip.NewReader(r.Body); err != nil {
+		t.Errorf("unable to decompress ingest request: %v", err)
+	} else if err := json.NewDecoder(reader).Decode(&body); err != nil {
+		t.Errorf("unable to decode ingest request: %v", err)
+	}
+	return body
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bloodhoundad/azurehound/config"
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/pipeline"
+)
+
+// kindSelection is the set of kinds to collect
+type kindSelection map[enums.Kind]bool
+
+// newKindSelection selects the included kinds, or every kind if none are included, less the excluded kinds
+func newKindSelection(include, exclude []string) (kindSelection, error) {
+	selection := kindSelection{}
+
+	if len(include) == 0 {
+		for _, kind := range enums.Kinds() {
+			selection[kind] = true
+		}
+	} else {
+		for _, name := range include {
+			if kind, err := parseKind(name); err != nil {
+				return nil, err
+			} else {
+				selection[kind] = true
+			}
+		}
+	}
+
+	for _, name := range exclude {
+		if kind, err := parseKind(name); err != nil {
+			return nil, err
+		} else {
+			delete(selection, kind)
+		}
+	}
+
+	if len(selection) == 0 {
+		return nil, fmt.Errorf("no kinds are selected for collection")
+	} else {
+		return selection, nil
+	}
+}
+
+// configuredKinds returns the selection made with the --include-kinds and --exclude-kinds options
+func configuredKinds() (kindSelection, error) {
+	return newKindSelection(config.IncludeKinds.Value().([]string), config.ExcludeKinds.Value().([]string))
+}
+
+// taskKinds returns the selection made by a BloodHound Enterprise task, or the configured selection if it makes none
+func taskKinds(task models.ClientTask) (kindSelection, error) {
+	if len(task.IncludeKinds) > 0 || len(task.ExcludeKinds) > 0 {
+		return newKindSelection(task.IncludeKinds, task.ExcludeKinds)
+	} else {
+		return configuredKinds()
+	}
+}
+
+func parseKind(name string) (enums.Kind, error) {
+	for _, kind := range enums.Kinds() {
+		if strings.EqualFold(string(kind), strings.TrimSpace(name)) {
+			return kind, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported kind: %s", name)
+}
+
+// Has returns true if any of the kinds are selected
+func (s kindSelection) Has(kinds ...enums.Kind) bool {
+	for _, kind := range kinds {
+		if s[kind] {
+			return true
+		}
+	}
+	return false
+}
+
+// fanOut copies a collector's stream to each stage that branches from it. The collector is only run if at least one
+// stage does, which prunes the stages that would only have fed unselected kinds.
+type fanOut[T any] struct {
+	outputs []chan<- T
+}
+
+func (s *fanOut[T]) branch() <-chan T {
+	out := make(chan T)
+	s.outputs = append(s.outputs, out)
+	return out
+}
+
+// start runs the collector once every stage consuming it has branched
+func (s *fanOut[T]) start(ctx context.Context, collect func() <-chan T) {
+	if len(s.outputs) > 0 {
+		pipeline.Tee(ctx.Done(), collect(), s.outputs...)
+	}
+}
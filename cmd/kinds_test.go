@@ -0,0 +1,52 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/enums"
+)
+
+func TestNewKindSelection(t *testing.T) {
+	if selection, err := newKindSelection(nil, []string{"AZVMAdminLogin"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(selection) != len(enums.Kinds())-1 || selection.Has(enums.KindAZVMAdminLogin) {
+		t.Errorf("got %v, want every kind but %v", selection, enums.KindAZVMAdminLogin)
+	}
+
+	if selection, err := newKindSelection([]string{"azgroupmember", "AZUser"}, []string{"AZUser"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if len(selection) != 1 || !selection.Has(enums.KindAZGroupMember) {
+		t.Errorf("got %v, want %v", selection, enums.KindAZGroupMember)
+	}
+
+	if _, err := newKindSelection([]string{"AZGroupMembers"}, nil); err == nil {
+		t.Error("expected an error for an unsupported kind")
+	}
+
+	for _, kind := range []string{"AZAppMember", "AZVMRoleAssignment"} {
+		if _, err := newKindSelection([]string{"AZUser", kind}, nil); err == nil {
+			t.Errorf("expected an error for %s, which no collection stage produces", kind)
+		}
+	}
+
+	if _, err := newKindSelection([]string{"AZUser"}, []string{"AZUser"}); err == nil {
+		t.Error("expected an error when no kinds are selected")
+	}
+}
@@ -19,13 +19,13 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"sync"
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
 	"github.com/bloodhoundad/azurehound/pipeline"
@@ -62,23 +62,20 @@ func listAppOwnersCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listAppOwners(ctx context.Context, client client.AzureClient, apps <-chan interface{}) <-chan interface{} {
+func listAppOwners(ctx context.Context, client client.AzureClient, apps <-chan AzureWrapper[models.App]) <-chan AzureWrapper[models.AppOwners] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.AppOwners])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), apps) {
-			if app, ok := result.(AzureWrapper).Data.(models.App); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating app owners", "result", result)
-				return
-			} else {
-				ids <- app.Id
+		for app := range pipeline.OrDone(ctx.Done(), apps) {
+			if !completed(enums.KindAZAppOwner, app.Data.Id) {
+				ids <- app.Data.Id
 			}
 		}
 	}()
@@ -91,13 +88,15 @@ func listAppOwners(ctx context.Context, client client.AzureClient, apps <-chan i
 			for id := range stream {
 				var (
 					data = models.AppOwners{
-						AppId: id.(string),
+						AppId: id,
 					}
-					count = 0
+					count  = 0
+					failed = false
 				)
-				for item := range client.ListAzureADAppOwners(ctx, id.(string), "", "", "", nil) {
+				for item := range client.ListAzureADAppOwners(ctx, id, "", "", "", nil) {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing owners for this app", "appId", id)
+						logChildError(item.Error, "unable to continue processing owners for this app", "appId", id)
+						failed = true
 					} else {
 						appOwner := models.AppOwner{
 							Owner: item.Ok,
@@ -109,8 +108,12 @@ func listAppOwners(ctx context.Context, client client.AzureClient, apps <-chan i
 					}
 				}
 
-				out <- AzureWrapper{
+				if ctx.Err() != nil {
+					return
+				}
+				out <- AzureWrapper[models.AppOwners]{
 					Kind: enums.KindAZAppOwner,
+					id:   checkpointKey(id, failed),
 					Data: data,
 				}
 				log.V(1).Info("finished listing app owners", "appId", id, "count", count)
@@ -40,7 +40,7 @@ func TestListAppOwners(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockAppsChannel := make(chan interface{})
+	mockAppsChannel := make(chan AzureWrapper[models.App])
 	mockAppOwnerChannel := make(chan azure.AppOwnerResult)
 	mockAppOwnerChannel2 := make(chan azure.AppOwnerResult)
 
@@ -53,10 +53,10 @@ func TestListAppOwners(t *testing.T) {
 
 	go func() {
 		defer close(mockAppsChannel)
-		mockAppsChannel <- AzureWrapper{
+		mockAppsChannel <- AzureWrapper[models.App]{
 			Data: models.App{},
 		}
-		mockAppsChannel <- AzureWrapper{
+		mockAppsChannel <- AzureWrapper[models.App]{
 			Data: models.App{},
 		}
 	}()
@@ -81,21 +81,13 @@ func TestListAppOwners(t *testing.T) {
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.AppOwners); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.AppOwners{})
-	} else if len(data.Owners) != 2 {
-		t.Errorf("got %v, want %v", len(data.Owners), 2)
+	} else if len(result.Data.Owners) != 2 {
+		t.Errorf("got %v, want %v", len(result.Data.Owners), 2)
 	}
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.AppOwners); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.AppOwners{})
-	} else if len(data.Owners) != 1 {
-		t.Errorf("got %v, want %v", len(data.Owners), 2)
+	} else if len(result.Data.Owners) != 1 {
+		t.Errorf("got %v, want %v", len(result.Data.Owners), 2)
 	}
 }
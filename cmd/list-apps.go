@@ -59,8 +59,8 @@ func listAppsCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listApps(ctx context.Context, client client.AzureClient) <-chan interface{} {
-	out := make(chan interface{})
+func listApps(ctx context.Context, client client.AzureClient) <-chan AzureWrapper[models.App] {
+	out := make(chan AzureWrapper[models.App])
 
 	go func() {
 		defer close(out)
@@ -72,8 +72,9 @@ func listApps(ctx context.Context, client client.AzureClient) <-chan interface{}
 			} else {
 				log.V(2).Info("found application", "app", item)
 				count++
-				out <- AzureWrapper{
+				out <- AzureWrapper[models.App]{
 					Kind: enums.KindAZApp,
+					id:   item.Ok.Id,
 					Data: models.App{
 						Application: item.Ok,
 						TenantId:    client.TenantInfo().TenantId,
@@ -57,9 +57,8 @@ func TestListApps(t *testing.T) {
 	}()
 
 	channel := listApps(ctx, mockClient)
-	result := <-channel
-	if _, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
+	if _, ok := <-channel; !ok {
+		t.Fatalf("failed to receive from channel")
 	}
 
 	if _, ok := <-channel; ok {
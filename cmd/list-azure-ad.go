@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/pipeline"
 	"github.com/spf13/cobra"
 )
@@ -36,7 +37,7 @@ func init() {
 var listAzureADCmd = &cobra.Command{
 	Use:               "az-ad",
 	Long:              "Lists All Azure AD Entities",
-	PersistentPreRunE: persistentPreRunE,
+	PersistentPreRunE: listPersistentPreRunE,
 	Run:               listAzureADCmdImpl,
 	SilenceUsage:      true,
 }
@@ -54,77 +55,27 @@ func listAzureADCmdImpl(cmd *cobra.Command, args []string) {
 		exit(err)
 	} else if azClient, err := newAzureClient(); err != nil {
 		exit(err)
+	} else if kinds, err := configuredKinds(); err != nil {
+		exit(err)
+	} else if !azClient.HasGraphAccess() {
+		exit(fmt.Errorf("no token or credential for Microsoft Graph was provided"))
 	} else {
 		log.Info("collecting azure ad objects...")
 		start := time.Now()
-		stream := listAllAD(ctx, azClient)
+		stream := listAllAD(ctx, azClient, kinds)
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listAllAD(ctx context.Context, client client.AzureClient) <-chan interface{} {
-	var (
-		apps  = make(chan interface{})
-		apps2 = make(chan interface{})
-
-		devices  = make(chan interface{})
-		devices2 = make(chan interface{})
-
-		groups  = make(chan interface{})
-		groups2 = make(chan interface{})
-		groups3 = make(chan interface{})
-
-		roles  = make(chan interface{})
-		roles2 = make(chan interface{})
-
-		servicePrincipals  = make(chan interface{})
-		servicePrincipals2 = make(chan interface{})
-
-		tenants = make(chan interface{})
-	)
-
-	// Enumerate Apps, AppOwners and AppMembers
-	pipeline.Tee(ctx.Done(), listApps(ctx, client), apps, apps2)
-	appOwners := listAppOwners(ctx, client, apps2)
-
-	// Enumerate Devices and DeviceOwners
-	pipeline.Tee(ctx.Done(), listDevices(ctx, client), devices, devices2)
-	deviceOwners := listDeviceOwners(ctx, client, devices2)
-
-	// Enumerate Groups, GroupOwners and GroupMembers
-	pipeline.Tee(ctx.Done(), listGroups(ctx, client), groups, groups2, groups3)
-	groupOwners := listGroupOwners(ctx, client, groups2)
-	groupMembers := listGroupMembers(ctx, client, groups3)
-
-	// Enumerate ServicePrincipals and ServicePrincipalOwners
-	pipeline.Tee(ctx.Done(), listServicePrincipals(ctx, client), servicePrincipals, servicePrincipals2)
-	servicePrincipalOwners := listServicePrincipalOwners(ctx, client, servicePrincipals2)
+func listAllAD(ctx context.Context, client client.AzureClient, kinds kindSelection) <-chan AzureWrapper[interface{}] {
+	streams := listAllGraphObjects(ctx, client, kinds)
 
 	// Enumerate Tenants
-	pipeline.Tee(ctx.Done(), listTenants(ctx, client), tenants)
-
-	// Enumerate Users
-	users := listUsers(ctx, client)
-
-	// Enumerate Roles and RoleAssignments
-	pipeline.Tee(ctx.Done(), listRoles(ctx, client), roles, roles2)
-	roleAssignments := listRoleAssignments(ctx, client, roles2)
+	if kinds.Has(enums.KindAZTenant) {
+		streams = append(streams, untyped(ctx, listTenants(ctx, client)))
+	}
 
-	return pipeline.Mux(ctx.Done(),
-		appOwners,
-		apps,
-		deviceOwners,
-		devices,
-		groupMembers,
-		groupOwners,
-		groups,
-		roleAssignments,
-		roles,
-		servicePrincipalOwners,
-		servicePrincipals,
-		tenants,
-		users,
-	)
+	return pipeline.Mux(ctx.Done(), streams...)
 }
@@ -19,13 +19,13 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"sync"
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
 	"github.com/bloodhoundad/azurehound/pipeline"
@@ -62,22 +62,20 @@ func listDeviceOwnersCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listDeviceOwners(ctx context.Context, client client.AzureClient, devices <-chan interface{}) <-chan interface{} {
+func listDeviceOwners(ctx context.Context, client client.AzureClient, devices <-chan AzureWrapper[models.Device]) <-chan AzureWrapper[models.DeviceOwners] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.DeviceOwners])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), devices) {
-			if device, ok := result.(AzureWrapper).Data.(models.Device); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating device owners", "result", result)
-			} else {
-				ids <- device.Id
+		for device := range pipeline.OrDone(ctx.Done(), devices) {
+			if !completed(enums.KindAZDeviceOwner, device.Data.Id) {
+				ids <- device.Data.Id
 			}
 		}
 	}()
@@ -90,13 +88,15 @@ func listDeviceOwners(ctx context.Context, client client.AzureClient, devices <-
 			for id := range stream {
 				var (
 					data = models.DeviceOwners{
-						DeviceId: id.(string),
+						DeviceId: id,
 					}
-					count = 0
+					count  = 0
+					failed = false
 				)
-				for item := range client.ListAzureDeviceRegisteredOwners(ctx, id.(string), false) {
+				for item := range client.ListAzureDeviceRegisteredOwners(ctx, id, false) {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing owners for this device", "deviceId", id)
+						logChildError(item.Error, "unable to continue processing owners for this device", "deviceId", id)
+						failed = true
 					} else {
 						deviceOwner := models.DeviceOwner{
 							Owner:    item.Ok,
@@ -107,8 +107,12 @@ func listDeviceOwners(ctx context.Context, client client.AzureClient, devices <-
 						data.Owners = append(data.Owners, deviceOwner)
 					}
 				}
-				out <- AzureWrapper{
+				if ctx.Err() != nil {
+					return
+				}
+				out <- AzureWrapper[models.DeviceOwners]{
 					Kind: enums.KindAZDeviceOwner,
+					id:   checkpointKey(id, failed),
 					Data: data,
 				}
 				log.V(1).Info("finished listing device owners", "deviceId", id, "count", count)
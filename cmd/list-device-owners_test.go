@@ -40,7 +40,7 @@ func TestListDeviceOwners(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockDevicesChannel := make(chan interface{})
+	mockDevicesChannel := make(chan AzureWrapper[models.Device])
 	mockDeviceOwnerChannel := make(chan azure.DeviceRegisteredOwnerResult)
 	mockDeviceOwnerChannel2 := make(chan azure.DeviceRegisteredOwnerResult)
 
@@ -53,10 +53,10 @@ func TestListDeviceOwners(t *testing.T) {
 
 	go func() {
 		defer close(mockDevicesChannel)
-		mockDevicesChannel <- AzureWrapper{
+		mockDevicesChannel <- AzureWrapper[models.Device]{
 			Data: models.Device{},
 		}
-		mockDevicesChannel <- AzureWrapper{
+		mockDevicesChannel <- AzureWrapper[models.Device]{
 			Data: models.Device{},
 		}
 	}()
@@ -81,21 +81,13 @@ func TestListDeviceOwners(t *testing.T) {
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.DeviceOwners); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.DeviceOwners{})
-	} else if len(data.Owners) != 2 {
-		t.Errorf("got %v, want %v", len(data.Owners), 2)
+	} else if len(result.Data.Owners) != 2 {
+		t.Errorf("got %v, want %v", len(result.Data.Owners), 2)
 	}
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.DeviceOwners); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.DeviceOwners{})
-	} else if len(data.Owners) != 1 {
-		t.Errorf("got %v, want %v", len(data.Owners), 2)
+	} else if len(result.Data.Owners) != 1 {
+		t.Errorf("got %v, want %v", len(result.Data.Owners), 2)
 	}
 }
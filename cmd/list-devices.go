@@ -59,8 +59,8 @@ func listDevicesCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listDevices(ctx context.Context, client client.AzureClient) <-chan interface{} {
-	out := make(chan interface{})
+func listDevices(ctx context.Context, client client.AzureClient) <-chan AzureWrapper[models.Device] {
+	out := make(chan AzureWrapper[models.Device])
 
 	go func() {
 		defer close(out)
@@ -72,8 +72,9 @@ func listDevices(ctx context.Context, client client.AzureClient) <-chan interfac
 			} else {
 				log.V(2).Info("found device", "device", item)
 				count++
-				out <- AzureWrapper{
+				out <- AzureWrapper[models.Device]{
 					Kind: enums.KindAZDevice,
+					id:   item.Ok.Id,
 					Data: models.Device{
 						Device:     item.Ok,
 						TenantId:   client.TenantInfo().TenantId,
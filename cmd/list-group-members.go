@@ -19,13 +19,13 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"sync"
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
 	"github.com/bloodhoundad/azurehound/pipeline"
@@ -62,23 +62,20 @@ func listGroupMembersCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listGroupMembers(ctx context.Context, client client.AzureClient, groups <-chan interface{}) <-chan interface{} {
+func listGroupMembers(ctx context.Context, client client.AzureClient, groups <-chan AzureWrapper[models.Group]) <-chan AzureWrapper[models.GroupMembers] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.GroupMembers])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), groups) {
-			if group, ok := result.(AzureWrapper).Data.(models.Group); !ok {
-				log.Error(fmt.Errorf("failed group type assertion"), "unable to continue enumerating group members", "result", result)
-				return
-			} else {
-				ids <- group.Id
+		for group := range pipeline.OrDone(ctx.Done(), groups) {
+			if !completed(enums.KindAZGroupMember, group.Data.Id) {
+				ids <- group.Data.Id
 			}
 		}
 	}()
@@ -91,13 +88,15 @@ func listGroupMembers(ctx context.Context, client client.AzureClient, groups <-c
 			for id := range stream {
 				var (
 					data = models.GroupMembers{
-						GroupId: id.(string),
+						GroupId: id,
 					}
-					count = 0
+					count  = 0
+					failed = false
 				)
-				for item := range client.ListAzureADGroupMembers(ctx, id.(string), "", "", "", nil) {
+				for item := range client.ListAzureADGroupMembers(ctx, id, "", "", "", nil) {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing members for this group", "groupId", id)
+						logChildError(item.Error, "unable to continue processing members for this group", "groupId", id)
+						failed = true
 					} else {
 						groupMember := models.GroupMember{
 							Member:  item.Ok,
@@ -108,8 +107,12 @@ func listGroupMembers(ctx context.Context, client client.AzureClient, groups <-c
 						data.Members = append(data.Members, groupMember)
 					}
 				}
-				out <- AzureWrapper{
+				if ctx.Err() != nil {
+					return
+				}
+				out <- AzureWrapper[models.GroupMembers]{
 					Kind: enums.KindAZGroupMember,
+					id:   checkpointKey(id, failed),
 					Data: data,
 				}
 				log.V(1).Info("finished listing group memberships", "groupId", id, "count", count)
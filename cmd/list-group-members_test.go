@@ -40,7 +40,7 @@ func TestListGroupMembers(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockGroupsChannel := make(chan interface{})
+	mockGroupsChannel := make(chan AzureWrapper[models.Group])
 	mockGroupMemberChannel := make(chan azure.MemberObjectResult)
 	mockGroupMemberChannel2 := make(chan azure.MemberObjectResult)
 
@@ -53,10 +53,10 @@ func TestListGroupMembers(t *testing.T) {
 
 	go func() {
 		defer close(mockGroupsChannel)
-		mockGroupsChannel <- AzureWrapper{
+		mockGroupsChannel <- AzureWrapper[models.Group]{
 			Data: models.Group{},
 		}
-		mockGroupsChannel <- AzureWrapper{
+		mockGroupsChannel <- AzureWrapper[models.Group]{
 			Data: models.Group{},
 		}
 	}()
@@ -81,21 +81,44 @@ func TestListGroupMembers(t *testing.T) {
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.GroupMembers); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.GroupMembers{})
-	} else if len(data.Members) != 2 {
-		t.Errorf("got %v, want %v", len(data.Members), 2)
+	} else if len(result.Data.Members) != 2 {
+		t.Errorf("got %v, want %v", len(result.Data.Members), 2)
 	}
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.GroupMembers); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.GroupMembers{})
-	} else if len(data.Members) != 1 {
-		t.Errorf("got %v, want %v", len(data.Members), 2)
+	} else if len(result.Data.Members) != 1 {
+		t.Errorf("got %v, want %v", len(result.Data.Members), 2)
+	}
+}
+
+func TestListGroupMembersCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockClient := mocks.NewMockAzureClient(ctrl)
+
+	mockGroupsChannel := make(chan AzureWrapper[models.Group], 1)
+	mockGroupMemberChannel := make(chan azure.MemberObjectResult)
+
+	mockClient.EXPECT().ListAzureADGroupMembers(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mockGroupMemberChannel).Times(1)
+	channel := listGroupMembers(ctx, mockClient, mockGroupsChannel)
+
+	mockGroupsChannel <- AzureWrapper[models.Group]{
+		Data: models.Group{Group: azure.Group{DirectoryObject: azure.DirectoryObject{Id: "group"}}},
+	}
+	close(mockGroupsChannel)
+
+	// the collection is interrupted after the first of the group's members has been listed
+	mockGroupMemberChannel <- azure.MemberObjectResult{
+		Ok: json.RawMessage{},
+	}
+	cancel()
+	close(mockGroupMemberChannel)
+
+	if result, ok := <-channel; ok {
+		t.Errorf("got %+v, want a group whose members were cut short not to be written", result)
 	}
 }
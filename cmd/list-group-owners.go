@@ -19,13 +19,13 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"sync"
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
 	"github.com/bloodhoundad/azurehound/pipeline"
@@ -62,23 +62,20 @@ func listGroupOwnersCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listGroupOwners(ctx context.Context, client client.AzureClient, groups <-chan interface{}) <-chan interface{} {
+func listGroupOwners(ctx context.Context, client client.AzureClient, groups <-chan AzureWrapper[models.Group]) <-chan AzureWrapper[models.GroupOwners] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.GroupOwners])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), groups) {
-			if group, ok := result.(AzureWrapper).Data.(models.Group); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating group owners", "result", result)
-				return
-			} else {
-				ids <- group.Id
+		for group := range pipeline.OrDone(ctx.Done(), groups) {
+			if !completed(enums.KindAZGroupOwner, group.Data.Id) {
+				ids <- group.Data.Id
 			}
 		}
 	}()
@@ -91,13 +88,15 @@ func listGroupOwners(ctx context.Context, client client.AzureClient, groups <-ch
 			for id := range stream {
 				var (
 					groupOwners = models.GroupOwners{
-						GroupId: id.(string),
+						GroupId: id,
 					}
-					count = 0
+					count  = 0
+					failed = false
 				)
-				for item := range client.ListAzureADGroupOwners(ctx, id.(string), "", "", "", nil) {
+				for item := range client.ListAzureADGroupOwners(ctx, id, "", "", "", nil) {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing owners for this group", "groupId", id)
+						logChildError(item.Error, "unable to continue processing owners for this group", "groupId", id)
+						failed = true
 					} else {
 						groupOwner := models.GroupOwner{
 							Owner:   item.Ok,
@@ -108,8 +107,12 @@ func listGroupOwners(ctx context.Context, client client.AzureClient, groups <-ch
 						groupOwners.Owners = append(groupOwners.Owners, groupOwner)
 					}
 				}
-				out <- AzureWrapper{
+				if ctx.Err() != nil {
+					return
+				}
+				out <- AzureWrapper[models.GroupOwners]{
 					Kind: enums.KindAZGroupOwner,
+					id:   checkpointKey(id, failed),
 					Data: groupOwners,
 				}
 				log.V(1).Info("finished listing group owners", "groupId", id, "count", count)
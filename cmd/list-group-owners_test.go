@@ -40,7 +40,7 @@ func TestListGroupOwners(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockGroupsChannel := make(chan interface{})
+	mockGroupsChannel := make(chan AzureWrapper[models.Group])
 	mockGroupOwnerChannel := make(chan azure.GroupOwnerResult)
 	mockGroupOwnerChannel2 := make(chan azure.GroupOwnerResult)
 
@@ -53,10 +53,10 @@ func TestListGroupOwners(t *testing.T) {
 
 	go func() {
 		defer close(mockGroupsChannel)
-		mockGroupsChannel <- AzureWrapper{
+		mockGroupsChannel <- AzureWrapper[models.Group]{
 			Data: models.Group{},
 		}
-		mockGroupsChannel <- AzureWrapper{
+		mockGroupsChannel <- AzureWrapper[models.Group]{
 			Data: models.Group{},
 		}
 	}()
@@ -81,21 +81,13 @@ func TestListGroupOwners(t *testing.T) {
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.GroupOwners); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.GroupOwners{})
-	} else if len(data.Owners) != 2 {
-		t.Errorf("got %v, want %v", len(data.Owners), 2)
+	} else if len(result.Data.Owners) != 2 {
+		t.Errorf("got %v, want %v", len(result.Data.Owners), 2)
 	}
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.GroupOwners); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.GroupOwners{})
-	} else if len(data.Owners) != 1 {
-		t.Errorf("got %v, want %v", len(data.Owners), 2)
+	} else if len(result.Data.Owners) != 1 {
+		t.Errorf("got %v, want %v", len(result.Data.Owners), 2)
 	}
 }
@@ -59,8 +59,8 @@ func listGroupsCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listGroups(ctx context.Context, client client.AzureClient) <-chan interface{} {
-	out := make(chan interface{})
+func listGroups(ctx context.Context, client client.AzureClient) <-chan AzureWrapper[models.Group] {
+	out := make(chan AzureWrapper[models.Group])
 
 	go func() {
 		defer close(out)
@@ -77,8 +77,9 @@ func listGroups(ctx context.Context, client client.AzureClient) <-chan interface
 					TenantId:   client.TenantInfo().TenantId,
 					TenantName: client.TenantInfo().DisplayName,
 				}
-				out <- AzureWrapper{
+				out <- AzureWrapper[models.Group]{
 					Kind: enums.KindAZGroup,
+					id:   item.Ok.Id,
 					Data: group,
 				}
 			}
@@ -58,9 +58,8 @@ func TestListGroups(t *testing.T) {
 	}()
 
 	channel := listGroups(ctx, mockClient)
-	result := <-channel
-	if _, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
+	if _, ok := <-channel; !ok {
+		t.Fatalf("failed to receive from channel")
 	}
 
 	if _, ok := <-channel; ok {
@@ -72,51 +72,49 @@ func listKeyVaultAccessPoliciesCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listKeyVaultAccessPolicies(ctx context.Context, client client.AzureClient, keyVaults <-chan interface{}, filters []enums.KeyVaultAccessType) <-chan interface{} {
-	out := make(chan interface{})
+func listKeyVaultAccessPolicies(ctx context.Context, client client.AzureClient, keyVaults <-chan AzureWrapper[models.KeyVault], filters []enums.KeyVaultAccessType) <-chan AzureWrapper[models.KeyVaultAccessPolicy] {
+	out := make(chan AzureWrapper[models.KeyVaultAccessPolicy])
 
 	go func() {
 		defer close(out)
 
 		for result := range pipeline.OrDone(ctx.Done(), keyVaults) {
-			if keyVault, ok := result.(AzureWrapper).Data.(models.KeyVault); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating key vault access policies", "result", result)
-				return
-			} else {
-				for _, policy := range keyVault.Properties.AccessPolicies {
-					if len(filters) == 0 {
-						out <- AzureWrapper{
-							Kind: kinds.KindAZKeyVaultAccessPolicy,
-							Data: models.KeyVaultAccessPolicy{
-								KeyVaultId:        keyVault.Id,
-								AccessPolicyEntry: policy,
-							},
-						}
-					} else {
-						for _, filter := range filters {
-							permissions := func() []string {
-								switch filter {
-								case enums.GetCerts:
-									return policy.Permissions.Certificates
-								case enums.GetKeys:
-									return policy.Permissions.Keys
-								case enums.GetSecrets:
-									return policy.Permissions.Secrets
-								default:
-									log.Error(fmt.Errorf("unsupported key vault access type: %s", filter), "unable to apply key vault access policy filter")
-									return []string{}
-								}
-							}()
-							if contains(permissions, "Get") {
-								out <- AzureWrapper{
-									Kind: kinds.KindAZKeyVaultAccessPolicy,
-									Data: models.KeyVaultAccessPolicy{
-										KeyVaultId:        keyVault.Id,
-										AccessPolicyEntry: policy,
-									},
-								}
-								break
+			keyVault := result.Data
+			for _, policy := range keyVault.Properties.AccessPolicies {
+				if len(filters) == 0 {
+					out <- AzureWrapper[models.KeyVaultAccessPolicy]{
+						Kind: kinds.KindAZKeyVaultAccessPolicy,
+						id:   keyVault.Id + "/" + policy.ObjectId,
+						Data: models.KeyVaultAccessPolicy{
+							KeyVaultId:        keyVault.Id,
+							AccessPolicyEntry: policy,
+						},
+					}
+				} else {
+					for _, filter := range filters {
+						permissions := func() []string {
+							switch filter {
+							case enums.GetCerts:
+								return policy.Permissions.Certificates
+							case enums.GetKeys:
+								return policy.Permissions.Keys
+							case enums.GetSecrets:
+								return policy.Permissions.Secrets
+							default:
+								log.Error(fmt.Errorf("unsupported key vault access type: %s", filter), "unable to apply key vault access policy filter")
+								return []string{}
+							}
+						}()
+						if contains(permissions, "Get") {
+							out <- AzureWrapper[models.KeyVaultAccessPolicy]{
+								Kind: kinds.KindAZKeyVaultAccessPolicy,
+								id:   keyVault.Id + "/" + policy.ObjectId,
+								Data: models.KeyVaultAccessPolicy{
+									KeyVaultId:        keyVault.Id,
+									AccessPolicyEntry: policy,
+								},
 							}
+							break
 						}
 					}
 				}
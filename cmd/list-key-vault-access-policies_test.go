@@ -38,14 +38,14 @@ func TestListKeyVaultAccessPolicies(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockKeyVaultsChannel := make(chan interface{})
+	mockKeyVaultsChannel := make(chan AzureWrapper[models.KeyVault])
 	mockTenant := azure.Tenant{}
 	mockClient.EXPECT().TenantInfo().Return(mockTenant).AnyTimes()
 	channel := listKeyVaultAccessPolicies(ctx, mockClient, mockKeyVaultsChannel, nil)
 
 	go func() {
 		defer close(mockKeyVaultsChannel)
-		mockKeyVaultsChannel <- AzureWrapper{
+		mockKeyVaultsChannel <- AzureWrapper[models.KeyVault]{
 			Data: models.KeyVault{
 				KeyVault: azure.KeyVault{
 					Properties: azure.VaultProperties{
@@ -60,17 +60,13 @@ func TestListKeyVaultAccessPolicies(t *testing.T) {
 				},
 			},
 		}
-		mockKeyVaultsChannel <- AzureWrapper{
+		mockKeyVaultsChannel <- AzureWrapper[models.KeyVault]{
 			Data: models.KeyVault{},
 		}
 	}()
 
-	if result, ok := <-channel; !ok {
+	if _, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if _, ok := wrapper.Data.(models.KeyVaultAccessPolicy); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.KeyVaultAccessPolicy{})
 	}
 
 	if _, ok := <-channel; ok {
@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"path"
@@ -27,6 +26,7 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/constants"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
@@ -65,23 +65,20 @@ func listKeyVaultContributorsCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listKeyVaultContributors(ctx context.Context, client client.AzureClient, KeyVaults <-chan interface{}) <-chan interface{} {
+func listKeyVaultContributors(ctx context.Context, client client.AzureClient, KeyVaults <-chan AzureWrapper[models.KeyVault]) <-chan AzureWrapper[models.KeyVaultContributors] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.KeyVaultContributors])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), KeyVaults) {
-			if keyVault, ok := result.(AzureWrapper).Data.(models.KeyVault); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating key vault contributors", "result", result)
-				return
-			} else {
-				ids <- keyVault.Id
+		for keyVault := range pipeline.OrDone(ctx.Done(), KeyVaults) {
+			if !completed(enums.KindAZKeyVaultContributor, keyVault.Data.Id) {
+				ids <- keyVault.Data.Id
 			}
 		}
 	}()
@@ -94,13 +91,15 @@ func listKeyVaultContributors(ctx context.Context, client client.AzureClient, Ke
 			for id := range stream {
 				var (
 					keyVaultContributors = models.KeyVaultContributors{
-						KeyVaultId: id.(string),
+						KeyVaultId: id,
 					}
-					count = 0
+					count  = 0
+					failed = false
 				)
-				for item := range client.ListRoleAssignmentsForResource(ctx, id.(string), "") {
+				for item := range client.ListRoleAssignmentsForResource(ctx, id, "") {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing contributors for this key vault", "keyVaultId", id)
+						logChildError(item.Error, "unable to continue processing contributors for this key vault", "keyVaultId", id)
+						failed = true
 					} else {
 						roleDefinitionId := path.Base(item.Ok.Properties.RoleDefinitionId)
 
@@ -115,8 +114,12 @@ func listKeyVaultContributors(ctx context.Context, client client.AzureClient, Ke
 						}
 					}
 				}
-				out <- AzureWrapper{
+				if ctx.Err() != nil {
+					return
+				}
+				out <- AzureWrapper[models.KeyVaultContributors]{
 					Kind: enums.KindAZKeyVaultContributor,
+					id:   checkpointKey(id, failed),
 					Data: keyVaultContributors,
 				}
 				log.V(1).Info("finished listing key vault contributors", "keyVaultId", id, "count", count)
@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"path"
@@ -27,6 +26,7 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/constants"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
@@ -65,23 +65,20 @@ func listKeyVaultOwnersCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listKeyVaultOwners(ctx context.Context, client client.AzureClient, keyVaults <-chan interface{}) <-chan interface{} {
+func listKeyVaultOwners(ctx context.Context, client client.AzureClient, keyVaults <-chan AzureWrapper[models.KeyVault]) <-chan AzureWrapper[models.KeyVaultOwners] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.KeyVaultOwners])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), keyVaults) {
-			if keyVault, ok := result.(AzureWrapper).Data.(models.KeyVault); !ok {
-				log.Error(fmt.Errorf("failed KeyVault type assertion"), "unable to continue enumerating key vault owners", "result", result)
-				return
-			} else {
-				ids <- keyVault.Id
+		for keyVault := range pipeline.OrDone(ctx.Done(), keyVaults) {
+			if !completed(enums.KindAZKeyVaultOwner, keyVault.Data.Id) {
+				ids <- keyVault.Data.Id
 			}
 		}
 	}()
@@ -94,13 +91,15 @@ func listKeyVaultOwners(ctx context.Context, client client.AzureClient, keyVault
 			for id := range stream {
 				var (
 					keyVaultOwners = models.KeyVaultOwners{
-						KeyVaultId: id.(string),
+						KeyVaultId: id,
 					}
-					count = 0
+					count  = 0
+					failed = false
 				)
-				for item := range client.ListRoleAssignmentsForResource(ctx, id.(string), "") {
+				for item := range client.ListRoleAssignmentsForResource(ctx, id, "") {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing owners for this key vault", "keyVaultId", id)
+						logChildError(item.Error, "unable to continue processing owners for this key vault", "keyVaultId", id)
+						failed = true
 					} else {
 						roleDefinitionId := path.Base(item.Ok.Properties.RoleDefinitionId)
 
@@ -115,8 +114,12 @@ func listKeyVaultOwners(ctx context.Context, client client.AzureClient, keyVault
 						}
 					}
 				}
-				out <- AzureWrapper{
+				if ctx.Err() != nil {
+					return
+				}
+				out <- AzureWrapper[models.KeyVaultOwners]{
 					Kind: enums.KindAZKeyVaultOwner,
+					id:   checkpointKey(id, failed),
 					Data: keyVaultOwners,
 				}
 				log.V(1).Info("finished listing key vault owners", "keyVaultId", id, "count", count)
@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"path"
@@ -27,6 +26,7 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/constants"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
@@ -65,23 +65,20 @@ func listKeyVaultUserAccessAdminsCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listKeyVaultUserAccessAdmins(ctx context.Context, client client.AzureClient, keyVaults <-chan interface{}) <-chan interface{} {
+func listKeyVaultUserAccessAdmins(ctx context.Context, client client.AzureClient, keyVaults <-chan AzureWrapper[models.KeyVault]) <-chan AzureWrapper[models.KeyVaultUserAccessAdmins] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.KeyVaultUserAccessAdmins])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), keyVaults) {
-			if keyVault, ok := result.(AzureWrapper).Data.(models.KeyVault); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating key vault user access admins", "result", result)
-				return
-			} else {
-				ids <- keyVault.Id
+		for keyVault := range pipeline.OrDone(ctx.Done(), keyVaults) {
+			if !completed(enums.KindAZKeyVaultUserAccessAdmin, keyVault.Data.Id) {
+				ids <- keyVault.Data.Id
 			}
 		}
 	}()
@@ -94,13 +91,15 @@ func listKeyVaultUserAccessAdmins(ctx context.Context, client client.AzureClient
 			for id := range stream {
 				var (
 					keyVaultUserAccessAdmins = models.KeyVaultUserAccessAdmins{
-						KeyVaultId: id.(string),
+						KeyVaultId: id,
 					}
-					count = 0
+					count  = 0
+					failed = false
 				)
-				for item := range client.ListRoleAssignmentsForResource(ctx, id.(string), "") {
+				for item := range client.ListRoleAssignmentsForResource(ctx, id, "") {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing user access admins for this key vault", "keyVaultId", id)
+						logChildError(item.Error, "unable to continue processing user access admins for this key vault", "keyVaultId", id)
+						failed = true
 					} else {
 						roleDefinitionId := path.Base(item.Ok.Properties.RoleDefinitionId)
 
@@ -115,8 +114,12 @@ func listKeyVaultUserAccessAdmins(ctx context.Context, client client.AzureClient
 						}
 					}
 				}
-				out <- AzureWrapper{
+				if ctx.Err() != nil {
+					return
+				}
+				out <- AzureWrapper[models.KeyVaultUserAccessAdmins]{
 					Kind: enums.KindAZKeyVaultUserAccessAdmin,
+					id:   checkpointKey(id, failed),
 					Data: keyVaultUserAccessAdmins,
 				}
 				log.V(1).Info("finished listing key vault user access admins", "keyVaultId", id, "count", count)
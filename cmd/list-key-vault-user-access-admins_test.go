@@ -40,7 +40,7 @@ func TestListKeyVaultUserAccessAdmins(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockKeyVaultsChannel := make(chan interface{})
+	mockKeyVaultsChannel := make(chan AzureWrapper[models.KeyVault])
 	mockKeyVaultUserAccessAdminChannel := make(chan azure.RoleAssignmentResult)
 	mockKeyVaultUserAccessAdminChannel2 := make(chan azure.RoleAssignmentResult)
 
@@ -53,10 +53,10 @@ func TestListKeyVaultUserAccessAdmins(t *testing.T) {
 
 	go func() {
 		defer close(mockKeyVaultsChannel)
-		mockKeyVaultsChannel <- AzureWrapper{
+		mockKeyVaultsChannel <- AzureWrapper[models.KeyVault]{
 			Data: models.KeyVault{},
 		}
-		mockKeyVaultsChannel <- AzureWrapper{
+		mockKeyVaultsChannel <- AzureWrapper[models.KeyVault]{
 			Data: models.KeyVault{},
 		}
 	}()
@@ -93,21 +93,13 @@ func TestListKeyVaultUserAccessAdmins(t *testing.T) {
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.KeyVaultUserAccessAdmins); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.KeyVaultUserAccessAdmins{})
-	} else if len(data.UserAccessAdmins) != 2 {
-		t.Errorf("got %v, want %v", len(data.UserAccessAdmins), 2)
+	} else if len(result.Data.UserAccessAdmins) != 2 {
+		t.Errorf("got %v, want %v", len(result.Data.UserAccessAdmins), 2)
 	}
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.KeyVaultUserAccessAdmins); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.KeyVaultUserAccessAdmins{})
-	} else if len(data.UserAccessAdmins) != 1 {
-		t.Errorf("got %v, want %v", len(data.UserAccessAdmins), 2)
+	} else if len(result.Data.UserAccessAdmins) != 1 {
+		t.Errorf("got %v, want %v", len(result.Data.UserAccessAdmins), 2)
 	}
 }
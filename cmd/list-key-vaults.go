@@ -19,13 +19,13 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"sync"
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
 	"github.com/bloodhoundad/azurehound/pipeline"
@@ -62,24 +62,19 @@ func listKeyVaultsCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listKeyVaults(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}) <-chan interface{} {
+func listKeyVaults(ctx context.Context, client client.AzureClient, subscriptions <-chan AzureWrapper[models.Subscription]) <-chan AzureWrapper[models.KeyVault] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.KeyVault])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), subscriptions) {
-			if subscription, ok := result.(AzureWrapper).Data.(models.Subscription); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating key vaults", "result", result)
-				return
-			} else {
-				ids <- subscription.SubscriptionId
-			}
+		for subscription := range pipeline.OrDone(ctx.Done(), subscriptions) {
+			ids <- subscription.Data.SubscriptionId
 		}
 	}()
 
@@ -90,9 +85,9 @@ func listKeyVaults(ctx context.Context, client client.AzureClient, subscriptions
 			defer wg.Done()
 			for id := range stream {
 				count := 0
-				for item := range client.ListAzureKeyVaults(ctx, id.(string), 999) {
+				for item := range client.ListAzureKeyVaults(ctx, id, 999) {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing key vaults for this subscription", "subscriptionId", id)
+						logChildError(item.Error, "unable to continue processing key vaults for this subscription", "subscriptionId", id)
 					} else {
 						resourceGroup := item.Ok.ResourceGroupId()
 						keyVault := models.KeyVault{
@@ -103,8 +98,9 @@ func listKeyVaults(ctx context.Context, client client.AzureClient, subscriptions
 						}
 						log.V(2).Info("found key vault", "keyVault", keyVault)
 						count++
-						out <- AzureWrapper{
+						out <- AzureWrapper[models.KeyVault]{
 							Kind: enums.KindAZKeyVault,
+							id:   item.Ok.Id,
 							Data: keyVault,
 						}
 					}
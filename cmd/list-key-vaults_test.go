@@ -39,7 +39,7 @@ func TestListKeyVaults(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockSubscriptionsChannel := make(chan interface{})
+	mockSubscriptionsChannel := make(chan AzureWrapper[models.Subscription])
 	mockKeyVaultChannel := make(chan azure.KeyVaultResult)
 	mockKeyVaultChannel2 := make(chan azure.KeyVaultResult)
 
@@ -52,10 +52,10 @@ func TestListKeyVaults(t *testing.T) {
 
 	go func() {
 		defer close(mockSubscriptionsChannel)
-		mockSubscriptionsChannel <- AzureWrapper{
+		mockSubscriptionsChannel <- AzureWrapper[models.Subscription]{
 			Data: models.Subscription{},
 		}
-		mockSubscriptionsChannel <- AzureWrapper{
+		mockSubscriptionsChannel <- AzureWrapper[models.Subscription]{
 			Data: models.Subscription{},
 		}
 	}()
@@ -78,28 +78,16 @@ func TestListKeyVaults(t *testing.T) {
 		}
 	}()
 
-	if result, ok := <-channel; !ok {
+	if _, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if _, ok := wrapper.Data.(models.KeyVault); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.KeyVault{})
 	}
 
-	if result, ok := <-channel; !ok {
+	if _, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if _, ok := wrapper.Data.(models.KeyVault); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.KeyVault{})
 	}
 
-	if result, ok := <-channel; !ok {
+	if _, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if _, ok := wrapper.Data.(models.KeyVault); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.KeyVault{})
 	}
 
 	if _, ok := <-channel; ok {
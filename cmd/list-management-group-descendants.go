@@ -19,15 +19,16 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"sync"
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
 	"github.com/bloodhoundad/azurehound/pipeline"
 	"github.com/spf13/cobra"
 )
@@ -62,24 +63,19 @@ func listManagementGroupDescendantsCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listManagementGroupDescendants(ctx context.Context, client client.AzureClient, managementGroups <-chan interface{}) <-chan interface{} {
+func listManagementGroupDescendants(ctx context.Context, client client.AzureClient, managementGroups <-chan AzureWrapper[models.ManagementGroup]) <-chan AzureWrapper[azure.DescendantInfo] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[azure.DescendantInfo])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), managementGroups) {
-			if managementGroup, ok := result.(AzureWrapper).Data.(models.ManagementGroup); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating management group descendants", "result", result)
-				return
-			} else {
-				ids <- managementGroup.Name
-			}
+		for managementGroup := range pipeline.OrDone(ctx.Done(), managementGroups) {
+			ids <- managementGroup.Data.Name
 		}
 	}()
 
@@ -90,14 +86,15 @@ func listManagementGroupDescendants(ctx context.Context, client client.AzureClie
 			defer wg.Done()
 			for id := range stream {
 				count := 0
-				for item := range client.ListAzureManagementGroupDescendants(ctx, id.(string)) {
+				for item := range client.ListAzureManagementGroupDescendants(ctx, id) {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing descendants for this management group", "managementGroupId", id)
+						logChildError(item.Error, "unable to continue processing descendants for this management group", "managementGroupId", id)
 					} else {
 						log.V(2).Info("found management group descendant", "type", item.Ok.Type, "id", item.Ok.Id, "parent", item.Ok.Properties.Parent.Id)
 						count++
-						out <- AzureWrapper{
+						out <- AzureWrapper[azure.DescendantInfo]{
 							Kind: enums.KindAZManagementGroupDescendant,
+							id:   id + "/" + item.Ok.Id,
 							Data: item.Ok,
 						}
 					}
@@ -39,7 +39,7 @@ func TestListManagementGroupDescendants(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockManagementGroupsChannel := make(chan interface{})
+	mockManagementGroupsChannel := make(chan AzureWrapper[models.ManagementGroup])
 	mockManagementGroupDescendantChannel := make(chan azure.DescendantInfoResult)
 	mockManagementGroupDescendantChannel2 := make(chan azure.DescendantInfoResult)
 
@@ -52,10 +52,10 @@ func TestListManagementGroupDescendants(t *testing.T) {
 
 	go func() {
 		defer close(mockManagementGroupsChannel)
-		mockManagementGroupsChannel <- AzureWrapper{
+		mockManagementGroupsChannel <- AzureWrapper[models.ManagementGroup]{
 			Data: models.ManagementGroup{},
 		}
-		mockManagementGroupsChannel <- AzureWrapper{
+		mockManagementGroupsChannel <- AzureWrapper[models.ManagementGroup]{
 			Data: models.ManagementGroup{},
 		}
 	}()
@@ -72,28 +72,16 @@ func TestListManagementGroupDescendants(t *testing.T) {
 		}
 	}()
 
-	if result, ok := <-channel; !ok {
+	if _, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if _, ok := wrapper.Data.(azure.DescendantInfo); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, azure.DescendantInfo{})
 	}
 
-	if result, ok := <-channel; !ok {
+	if _, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if _, ok := wrapper.Data.(azure.DescendantInfo); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, azure.DescendantInfo{})
 	}
 
-	if result, ok := <-channel; !ok {
+	if _, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if _, ok := wrapper.Data.(azure.DescendantInfo); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, azure.DescendantInfo{})
 	}
 
 	if _, ok := <-channel; ok {
@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"path"
@@ -27,6 +26,7 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/constants"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
@@ -64,23 +64,20 @@ func listManagementGroupOwnersCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listManagementGroupOwners(ctx context.Context, client client.AzureClient, managementGroups <-chan interface{}) <-chan interface{} {
+func listManagementGroupOwners(ctx context.Context, client client.AzureClient, managementGroups <-chan AzureWrapper[models.ManagementGroup]) <-chan AzureWrapper[models.ManagementGroupOwners] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.ManagementGroupOwners])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), managementGroups) {
-			if managementGroup, ok := result.(AzureWrapper).Data.(models.ManagementGroup); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating management group owners", "result", result)
-				return
-			} else {
-				ids <- managementGroup.Id
+		for managementGroup := range pipeline.OrDone(ctx.Done(), managementGroups) {
+			if !completed(enums.KindAZManagementGroupOwner, managementGroup.Data.Id) {
+				ids <- managementGroup.Data.Id
 			}
 		}
 	}()
@@ -93,13 +90,15 @@ func listManagementGroupOwners(ctx context.Context, client client.AzureClient, m
 			for id := range stream {
 				var (
 					managementGroupOwners = models.ManagementGroupOwners{
-						ManagementGroupId: id.(string),
+						ManagementGroupId: id,
 					}
-					count = 0
+					count  = 0
+					failed = false
 				)
-				for item := range client.ListRoleAssignmentsForResource(ctx, id.(string), "") {
+				for item := range client.ListRoleAssignmentsForResource(ctx, id, "") {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing owners for this management group", "managementGroupId", id)
+						logChildError(item.Error, "unable to continue processing owners for this management group", "managementGroupId", id)
+						failed = true
 					} else {
 						roleDefinitionId := path.Base(item.Ok.Properties.RoleDefinitionId)
 
@@ -114,8 +113,12 @@ func listManagementGroupOwners(ctx context.Context, client client.AzureClient, m
 						}
 					}
 				}
-				out <- AzureWrapper{
+				if ctx.Err() != nil {
+					return
+				}
+				out <- AzureWrapper[models.ManagementGroupOwners]{
 					Kind: enums.KindAZManagementGroupOwner,
+					id:   checkpointKey(id, failed),
 					Data: managementGroupOwners,
 				}
 				log.V(1).Info("finished listing management group owners", "managementGroupId", id, "count", count)
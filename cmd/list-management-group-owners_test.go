@@ -40,7 +40,7 @@ func TestListManagementGroupOwners(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockManagementGroupsChannel := make(chan interface{})
+	mockManagementGroupsChannel := make(chan AzureWrapper[models.ManagementGroup])
 	mockManagementGroupOwnerChannel := make(chan azure.RoleAssignmentResult)
 	mockManagementGroupOwnerChannel2 := make(chan azure.RoleAssignmentResult)
 
@@ -53,10 +53,10 @@ func TestListManagementGroupOwners(t *testing.T) {
 
 	go func() {
 		defer close(mockManagementGroupsChannel)
-		mockManagementGroupsChannel <- AzureWrapper{
+		mockManagementGroupsChannel <- AzureWrapper[models.ManagementGroup]{
 			Data: models.ManagementGroup{},
 		}
-		mockManagementGroupsChannel <- AzureWrapper{
+		mockManagementGroupsChannel <- AzureWrapper[models.ManagementGroup]{
 			Data: models.ManagementGroup{},
 		}
 	}()
@@ -93,21 +93,13 @@ func TestListManagementGroupOwners(t *testing.T) {
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.ManagementGroupOwners); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.ManagementGroupOwners{})
-	} else if len(data.Owners) != 2 {
-		t.Errorf("got %v, want %v", len(data.Owners), 2)
+	} else if len(result.Data.Owners) != 2 {
+		t.Errorf("got %v, want %v", len(result.Data.Owners), 2)
 	}
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.ManagementGroupOwners); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.ManagementGroupOwners{})
-	} else if len(data.Owners) != 1 {
-		t.Errorf("got %v, want %v", len(data.Owners), 2)
+	} else if len(result.Data.Owners) != 1 {
+		t.Errorf("got %v, want %v", len(result.Data.Owners), 2)
 	}
 }
@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"path"
@@ -27,6 +26,7 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/constants"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
@@ -65,23 +65,20 @@ func listManagementGroupUserAccessAdminsCmdImpl(cmd *cobra.Command, args []strin
 	}
 }
 
-func listManagementGroupUserAccessAdmins(ctx context.Context, client client.AzureClient, mgmtGroups <-chan interface{}) <-chan interface{} {
+func listManagementGroupUserAccessAdmins(ctx context.Context, client client.AzureClient, mgmtGroups <-chan AzureWrapper[models.ManagementGroup]) <-chan AzureWrapper[models.ManagementGroupUserAccessAdmins] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.ManagementGroupUserAccessAdmins])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), mgmtGroups) {
-			if mgmtGroup, ok := result.(AzureWrapper).Data.(models.ManagementGroup); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating management group user access admins", "result", result)
-				return
-			} else {
-				ids <- mgmtGroup.Id
+		for mgmtGroup := range pipeline.OrDone(ctx.Done(), mgmtGroups) {
+			if !completed(enums.KindAZManagementGroupUserAccessAdmin, mgmtGroup.Data.Id) {
+				ids <- mgmtGroup.Data.Id
 			}
 		}
 	}()
@@ -94,13 +91,15 @@ func listManagementGroupUserAccessAdmins(ctx context.Context, client client.Azur
 			for id := range stream {
 				var (
 					mgmtGroupUserAccessAdmins = models.ManagementGroupUserAccessAdmins{
-						ManagementGroupId: id.(string),
+						ManagementGroupId: id,
 					}
-					count = 0
+					count  = 0
+					failed = false
 				)
-				for item := range client.ListRoleAssignmentsForResource(ctx, id.(string), "") {
+				for item := range client.ListRoleAssignmentsForResource(ctx, id, "") {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing user access admins for this management group", "managementGroupId", id)
+						logChildError(item.Error, "unable to continue processing user access admins for this management group", "managementGroupId", id)
+						failed = true
 					} else {
 						roleDefinitionId := path.Base(item.Ok.Properties.RoleDefinitionId)
 
@@ -115,8 +114,12 @@ func listManagementGroupUserAccessAdmins(ctx context.Context, client client.Azur
 						}
 					}
 				}
-				out <- AzureWrapper{
+				if ctx.Err() != nil {
+					return
+				}
+				out <- AzureWrapper[models.ManagementGroupUserAccessAdmins]{
 					Kind: enums.KindAZManagementGroupUserAccessAdmin,
+					id:   checkpointKey(id, failed),
 					Data: mgmtGroupUserAccessAdmins,
 				}
 				log.V(1).Info("finished listing management group user access admins", "managementGroupId", id, "count", count)
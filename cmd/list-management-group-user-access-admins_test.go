@@ -40,7 +40,7 @@ func TestListManagementGroupUserAccessAdmins(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockManagementGroupsChannel := make(chan interface{})
+	mockManagementGroupsChannel := make(chan AzureWrapper[models.ManagementGroup])
 	mockManagementGroupUserAccessAdminChannel := make(chan azure.RoleAssignmentResult)
 	mockManagementGroupUserAccessAdminChannel2 := make(chan azure.RoleAssignmentResult)
 
@@ -53,10 +53,10 @@ func TestListManagementGroupUserAccessAdmins(t *testing.T) {
 
 	go func() {
 		defer close(mockManagementGroupsChannel)
-		mockManagementGroupsChannel <- AzureWrapper{
+		mockManagementGroupsChannel <- AzureWrapper[models.ManagementGroup]{
 			Data: models.ManagementGroup{},
 		}
-		mockManagementGroupsChannel <- AzureWrapper{
+		mockManagementGroupsChannel <- AzureWrapper[models.ManagementGroup]{
 			Data: models.ManagementGroup{},
 		}
 	}()
@@ -93,21 +93,13 @@ func TestListManagementGroupUserAccessAdmins(t *testing.T) {
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.ManagementGroupUserAccessAdmins); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.ManagementGroupUserAccessAdmins{})
-	} else if len(data.UserAccessAdmins) != 2 {
-		t.Errorf("got %v, want %v", len(data.UserAccessAdmins), 2)
+	} else if len(result.Data.UserAccessAdmins) != 2 {
+		t.Errorf("got %v, want %v", len(result.Data.UserAccessAdmins), 2)
 	}
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.ManagementGroupUserAccessAdmins); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.ManagementGroupUserAccessAdmins{})
-	} else if len(data.UserAccessAdmins) != 1 {
-		t.Errorf("got %v, want %v", len(data.UserAccessAdmins), 2)
+	} else if len(result.Data.UserAccessAdmins) != 1 {
+		t.Errorf("got %v, want %v", len(result.Data.UserAccessAdmins), 2)
 	}
 }
@@ -60,8 +60,8 @@ func listManagementGroupsCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listManagementGroups(ctx context.Context, client client.AzureClient) <-chan interface{} {
-	out := make(chan interface{})
+func listManagementGroups(ctx context.Context, client client.AzureClient) <-chan AzureWrapper[models.ManagementGroup] {
+	out := make(chan AzureWrapper[models.ManagementGroup])
 
 	go func() {
 		defer close(out)
@@ -79,8 +79,9 @@ func listManagementGroups(ctx context.Context, client client.AzureClient) <-chan
 					TenantName:      client.TenantInfo().DisplayName,
 				}
 
-				out <- AzureWrapper{
+				out <- AzureWrapper[models.ManagementGroup]{
 					Kind: enums.KindAZManagementGroup,
+					id:   item.Ok.Id,
 					Data: mgmtGroup,
 				}
 			}
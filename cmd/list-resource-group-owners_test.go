@@ -40,7 +40,7 @@ func TestListResourceGroupOwners(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockResourceGroupsChannel := make(chan interface{})
+	mockResourceGroupsChannel := make(chan AzureWrapper[models.ResourceGroup])
 	mockResourceGroupOwnerChannel := make(chan azure.RoleAssignmentResult)
 	mockResourceGroupOwnerChannel2 := make(chan azure.RoleAssignmentResult)
 
@@ -53,10 +53,10 @@ func TestListResourceGroupOwners(t *testing.T) {
 
 	go func() {
 		defer close(mockResourceGroupsChannel)
-		mockResourceGroupsChannel <- AzureWrapper{
+		mockResourceGroupsChannel <- AzureWrapper[models.ResourceGroup]{
 			Data: models.ResourceGroup{},
 		}
-		mockResourceGroupsChannel <- AzureWrapper{
+		mockResourceGroupsChannel <- AzureWrapper[models.ResourceGroup]{
 			Data: models.ResourceGroup{},
 		}
 	}()
@@ -93,21 +93,13 @@ func TestListResourceGroupOwners(t *testing.T) {
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.ResourceGroupOwners); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.ResourceGroupOwners{})
-	} else if len(data.Owners) != 2 {
-		t.Errorf("got %v, want %v", len(data.Owners), 2)
+	} else if len(result.Data.Owners) != 2 {
+		t.Errorf("got %v, want %v", len(result.Data.Owners), 2)
 	}
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.ResourceGroupOwners); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.ResourceGroupOwners{})
-	} else if len(data.Owners) != 1 {
-		t.Errorf("got %v, want %v", len(data.Owners), 2)
+	} else if len(result.Data.Owners) != 1 {
+		t.Errorf("got %v, want %v", len(result.Data.Owners), 2)
 	}
 }
@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"path"
@@ -27,6 +26,7 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/constants"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
@@ -65,23 +65,20 @@ func listResourceGroupUserAccessAdminsCmdImpl(cmd *cobra.Command, args []string)
 	}
 }
 
-func listResourceGroupUserAccessAdmins(ctx context.Context, client client.AzureClient, resourceGroups <-chan interface{}) <-chan interface{} {
+func listResourceGroupUserAccessAdmins(ctx context.Context, client client.AzureClient, resourceGroups <-chan AzureWrapper[models.ResourceGroup]) <-chan AzureWrapper[models.ResourceGroupUserAccessAdmins] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.ResourceGroupUserAccessAdmins])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), resourceGroups) {
-			if resourceGroup, ok := result.(AzureWrapper).Data.(models.ResourceGroup); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating resource group user access admins", "result", result)
-				return
-			} else {
-				ids <- resourceGroup.Id
+		for resourceGroup := range pipeline.OrDone(ctx.Done(), resourceGroups) {
+			if !completed(enums.KindAZResourceGroupUserAccessAdmin, resourceGroup.Data.Id) {
+				ids <- resourceGroup.Data.Id
 			}
 		}
 	}()
@@ -94,13 +91,15 @@ func listResourceGroupUserAccessAdmins(ctx context.Context, client client.AzureC
 			for id := range stream {
 				var (
 					resourceGroupUserAccessAdmins = models.ResourceGroupUserAccessAdmins{
-						ResourceGroupId: id.(string),
+						ResourceGroupId: id,
 					}
-					count = 0
+					count  = 0
+					failed = false
 				)
-				for item := range client.ListRoleAssignmentsForResource(ctx, id.(string), "") {
+				for item := range client.ListRoleAssignmentsForResource(ctx, id, "") {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing user access admins for this resource group", "resourceGroupId", id)
+						logChildError(item.Error, "unable to continue processing user access admins for this resource group", "resourceGroupId", id)
+						failed = true
 					} else {
 						roleDefinitionId := path.Base(item.Ok.Properties.RoleDefinitionId)
 
@@ -115,8 +114,12 @@ func listResourceGroupUserAccessAdmins(ctx context.Context, client client.AzureC
 						}
 					}
 				}
-				out <- AzureWrapper{
+				if ctx.Err() != nil {
+					return
+				}
+				out <- AzureWrapper[models.ResourceGroupUserAccessAdmins]{
 					Kind: enums.KindAZResourceGroupUserAccessAdmin,
+					id:   checkpointKey(id, failed),
 					Data: resourceGroupUserAccessAdmins,
 				}
 				log.V(1).Info("finished listing resource group user access admins", "resourceGroupId", id, "count", count)
@@ -40,7 +40,7 @@ func TestListResourceGroupUserAccessAdmins(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockResourceGroupsChannel := make(chan interface{})
+	mockResourceGroupsChannel := make(chan AzureWrapper[models.ResourceGroup])
 	mockResourceGroupUserAccessAdminChannel := make(chan azure.RoleAssignmentResult)
 	mockResourceGroupUserAccessAdminChannel2 := make(chan azure.RoleAssignmentResult)
 
@@ -53,10 +53,10 @@ func TestListResourceGroupUserAccessAdmins(t *testing.T) {
 
 	go func() {
 		defer close(mockResourceGroupsChannel)
-		mockResourceGroupsChannel <- AzureWrapper{
+		mockResourceGroupsChannel <- AzureWrapper[models.ResourceGroup]{
 			Data: models.ResourceGroup{},
 		}
-		mockResourceGroupsChannel <- AzureWrapper{
+		mockResourceGroupsChannel <- AzureWrapper[models.ResourceGroup]{
 			Data: models.ResourceGroup{},
 		}
 	}()
@@ -93,21 +93,13 @@ func TestListResourceGroupUserAccessAdmins(t *testing.T) {
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.ResourceGroupUserAccessAdmins); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.ResourceGroupUserAccessAdmins{})
-	} else if len(data.UserAccessAdmins) != 2 {
-		t.Errorf("got %v, want %v", len(data.UserAccessAdmins), 2)
+	} else if len(result.Data.UserAccessAdmins) != 2 {
+		t.Errorf("got %v, want %v", len(result.Data.UserAccessAdmins), 2)
 	}
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.ResourceGroupUserAccessAdmins); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.ResourceGroupUserAccessAdmins{})
-	} else if len(data.UserAccessAdmins) != 1 {
-		t.Errorf("got %v, want %v", len(data.UserAccessAdmins), 2)
+	} else if len(result.Data.UserAccessAdmins) != 1 {
+		t.Errorf("got %v, want %v", len(result.Data.UserAccessAdmins), 2)
 	}
 }
@@ -19,13 +19,13 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"sync"
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
 	"github.com/bloodhoundad/azurehound/pipeline"
@@ -62,24 +62,19 @@ func listResourceGroupsCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listResourceGroups(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}) <-chan interface{} {
+func listResourceGroups(ctx context.Context, client client.AzureClient, subscriptions <-chan AzureWrapper[models.Subscription]) <-chan AzureWrapper[models.ResourceGroup] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.ResourceGroup])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), subscriptions) {
-			if subscription, ok := result.(AzureWrapper).Data.(models.Subscription); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating resource groups", "result", result)
-				return
-			} else {
-				ids <- subscription.SubscriptionId
-			}
+		for subscription := range pipeline.OrDone(ctx.Done(), subscriptions) {
+			ids <- subscription.Data.SubscriptionId
 		}
 	}()
 
@@ -90,9 +85,9 @@ func listResourceGroups(ctx context.Context, client client.AzureClient, subscrip
 			defer wg.Done()
 			for id := range stream {
 				count := 0
-				for item := range client.ListAzureResourceGroups(ctx, id.(string), "") {
+				for item := range client.ListAzureResourceGroups(ctx, id, "") {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing resource groups for this subscription", "subscriptionId", id)
+						logChildError(item.Error, "unable to continue processing resource groups for this subscription", "subscriptionId", id)
 					} else {
 						resourceGroup := models.ResourceGroup{
 							ResourceGroup:  item.Ok,
@@ -100,8 +95,9 @@ func listResourceGroups(ctx context.Context, client client.AzureClient, subscrip
 						}
 						log.V(2).Info("found resource group", "resourceGroup", resourceGroup)
 						count++
-						out <- AzureWrapper{
+						out <- AzureWrapper[models.ResourceGroup]{
 							Kind: enums.KindAZResourceGroup,
+							id:   item.Ok.Id,
 							Data: resourceGroup,
 						}
 					}
@@ -39,7 +39,7 @@ func TestListResourceGroups(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockSubscriptionsChannel := make(chan interface{})
+	mockSubscriptionsChannel := make(chan AzureWrapper[models.Subscription])
 	mockResourceGroupChannel := make(chan azure.ResourceGroupResult)
 	mockResourceGroupChannel2 := make(chan azure.ResourceGroupResult)
 
@@ -52,10 +52,10 @@ func TestListResourceGroups(t *testing.T) {
 
 	go func() {
 		defer close(mockSubscriptionsChannel)
-		mockSubscriptionsChannel <- AzureWrapper{
+		mockSubscriptionsChannel <- AzureWrapper[models.Subscription]{
 			Data: models.Subscription{},
 		}
-		mockSubscriptionsChannel <- AzureWrapper{
+		mockSubscriptionsChannel <- AzureWrapper[models.Subscription]{
 			Data: models.Subscription{},
 		}
 	}()
@@ -78,28 +78,16 @@ func TestListResourceGroups(t *testing.T) {
 		}
 	}()
 
-	if result, ok := <-channel; !ok {
+	if _, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if _, ok := wrapper.Data.(models.ResourceGroup); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.ResourceGroup{})
 	}
 
-	if result, ok := <-channel; !ok {
+	if _, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if _, ok := wrapper.Data.(models.ResourceGroup); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.ResourceGroup{})
 	}
 
-	if result, ok := <-channel; !ok {
+	if _, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if _, ok := wrapper.Data.(models.ResourceGroup); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.ResourceGroup{})
 	}
 
 	if _, ok := <-channel; ok {
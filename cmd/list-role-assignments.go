@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
 	"github.com/bloodhoundad/azurehound/pipeline"
@@ -63,23 +64,20 @@ func listRoleAssignmentsCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listRoleAssignments(ctx context.Context, client client.AzureClient, roles <-chan interface{}) <-chan interface{} {
+func listRoleAssignments(ctx context.Context, client client.AzureClient, roles <-chan AzureWrapper[models.Role]) <-chan AzureWrapper[models.RoleAssignments] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.RoleAssignments])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), roles) {
-			if role, ok := result.(AzureWrapper).Data.(models.Role); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating role assignments", "result", result)
-				return
-			} else {
-				ids <- role.Id
+		for role := range pipeline.OrDone(ctx.Done(), roles) {
+			if !completed(enums.KindAZRoleAssignment, role.Data.Id) {
+				ids <- role.Data.Id
 			}
 		}
 	}()
@@ -92,23 +90,29 @@ func listRoleAssignments(ctx context.Context, client client.AzureClient, roles <
 			for id := range stream {
 				var (
 					roleAssignments = models.RoleAssignments{
-						RoleDefinitionId: id.(string),
+						RoleDefinitionId: id,
 						TenantId:         client.TenantInfo().TenantId,
 					}
 					count  = 0
-					filter = fmt.Sprintf("roleDefinitionId eq '%s'", id.(string))
+					failed = false
+					filter = fmt.Sprintf("roleDefinitionId eq '%s'", id)
 				)
 				for item := range client.ListAzureADRoleAssignments(ctx, filter, "", "", "", nil) {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing role assignments for this role", "roleDefinitionId", id)
+						logChildError(item.Error, "unable to continue processing role assignments for this role", "roleDefinitionId", id)
+						failed = true
 					} else {
 						log.V(2).Info("found role assignment", "roleAssignments", item)
 						count++
 						roleAssignments.RoleAssignments = append(roleAssignments.RoleAssignments, item.Ok)
 					}
 				}
-				out <- AzureWrapper{
+				if ctx.Err() != nil {
+					return
+				}
+				out <- AzureWrapper[models.RoleAssignments]{
 					Kind: enums.KindAZRoleAssignment,
+					id:   checkpointKey(id, failed),
 					Data: roleAssignments,
 				}
 				log.V(1).Info("finished listing role assignments", "roleDefinitionId", id, "count", count)
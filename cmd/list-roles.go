@@ -59,8 +59,8 @@ func listRolesCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listRoles(ctx context.Context, client client.AzureClient) <-chan interface{} {
-	out := make(chan interface{})
+func listRoles(ctx context.Context, client client.AzureClient) <-chan AzureWrapper[models.Role] {
+	out := make(chan AzureWrapper[models.Role])
 
 	go func() {
 		defer close(out)
@@ -72,8 +72,9 @@ func listRoles(ctx context.Context, client client.AzureClient) <-chan interface{
 			} else {
 				log.V(2).Info("found role", "role", item)
 				count++
-				out <- AzureWrapper{
+				out <- AzureWrapper[models.Role]{
 					Kind: enums.KindAZRole,
+					id:   item.Ok.Id,
 					Data: models.Role{
 						Role:       item.Ok,
 						TenantId:   client.TenantInfo().TenantId,
@@ -27,12 +27,13 @@ import (
 	"github.com/bloodhoundad/azurehound/client"
 	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models"
 	"github.com/bloodhoundad/azurehound/pipeline"
 	"github.com/spf13/cobra"
 )
 
 func init() {
-	config.Init(listRootCmd, append(config.AzureConfig, config.OutputFile))
+	config.Init(listRootCmd, append(config.AzureConfig, config.OutputFile, config.OutputFormat, config.SplitOutput, config.ZipOutput, config.Resume))
 	rootCmd.AddCommand(listRootCmd)
 }
 
@@ -40,10 +41,23 @@ var listRootCmd = &cobra.Command{
 	Use:               "list",
 	Short:             "Lists Azure Objects",
 	Run:               listCmdImpl,
-	PersistentPreRunE: persistentPreRunE,
+	PersistentPreRunE: listPersistentPreRunE,
 	SilenceUsage:      true,
 }
 
+// listPersistentPreRunE validates the output options and opens the checkpoint store before collection begins
+func listPersistentPreRunE(cmd *cobra.Command, args []string) error {
+	if err := persistentPreRunE(cmd, args); err != nil {
+		return err
+	} else if format := config.OutputFormat.Value().(string); !contains(enums.OutputFormats(), format) {
+		return fmt.Errorf("unsupported output format: %s", format)
+	} else if split := config.SplitOutput.Value().(bool) || config.ZipOutput.Value().(bool); split && !isDirectoryPath(config.OutputFile.Value().(string)) {
+		return fmt.Errorf("split output must be written to a directory")
+	} else {
+		return openCheckpoints()
+	}
+}
+
 func listCmdImpl(cmd *cobra.Command, args []string) {
 	if len(args) > 0 {
 		exit(fmt.Errorf("unsupported subcommand: %v", args))
@@ -57,165 +71,213 @@ func listCmdImpl(cmd *cobra.Command, args []string) {
 		exit(err)
 	} else if azClient, err := newAzureClient(); err != nil {
 		exit(err)
+	} else if kinds, err := configuredKinds(); err != nil {
+		exit(err)
 	} else {
 		log.Info("collecting azure objects...")
 		start := time.Now()
-		stream := listAll(ctx, azClient)
+		stream := listAll(ctx, azClient, kinds)
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listAll(ctx context.Context, client client.AzureClient) <-chan interface{} {
+func listAll(ctx context.Context, client client.AzureClient, kinds kindSelection) <-chan AzureWrapper[interface{}] {
+	var streams []<-chan AzureWrapper[interface{}]
+
+	if client.HasGraphAccess() {
+		streams = append(streams, listAllGraphObjects(ctx, client, kinds)...)
+	} else {
+		log.Info("skipping Azure AD collection; no token or credential for Microsoft Graph was provided")
+	}
+
+	if client.HasResourceManagerAccess() {
+		streams = append(streams, listAllResourceManagerObjects(ctx, client, kinds)...)
+	} else {
+		log.Info("skipping Azure Resource Manager collection; no token or credential for Azure Resource Manager was provided")
+	}
+
+	return pipeline.Mux(ctx.Done(), streams...)
+}
+
+// listAllGraphObjects enumerates the selected kinds of object collected from Microsoft Graph
+func listAllGraphObjects(ctx context.Context, client client.AzureClient, kinds kindSelection) []<-chan AzureWrapper[interface{}] {
 	var (
-		apps  = make(chan interface{})
-		apps2 = make(chan interface{})
-
-		devices  = make(chan interface{})
-		devices2 = make(chan interface{})
-
-		groups  = make(chan interface{})
-		groups2 = make(chan interface{})
-		groups3 = make(chan interface{})
-
-		keyVaults  = make(chan interface{})
-		keyVaults2 = make(chan interface{})
-		keyVaults3 = make(chan interface{})
-		keyVaults4 = make(chan interface{})
-		keyVaults5 = make(chan interface{})
-
-		mgmtGroups  = make(chan interface{})
-		mgmtGroups2 = make(chan interface{})
-		mgmtGroups3 = make(chan interface{})
-		mgmtGroups4 = make(chan interface{})
-
-		resourceGroups  = make(chan interface{})
-		resourceGroups2 = make(chan interface{})
-		resourceGroups3 = make(chan interface{})
-
-		roles  = make(chan interface{})
-		roles2 = make(chan interface{})
-
-		servicePrincipals  = make(chan interface{})
-		servicePrincipals2 = make(chan interface{})
-
-		subscriptions  = make(chan interface{})
-		subscriptions2 = make(chan interface{})
-		subscriptions3 = make(chan interface{})
-		subscriptions4 = make(chan interface{})
-		subscriptions5 = make(chan interface{})
-		subscriptions6 = make(chan interface{})
-
-		tenants = make(chan interface{})
-
-		virtualMachines  = make(chan interface{})
-		virtualMachines2 = make(chan interface{})
-
-		vmRoleAssignments1 = make(chan interface{})
-		vmRoleAssignments2 = make(chan interface{})
-		vmRoleAssignments3 = make(chan interface{})
-		vmRoleAssignments4 = make(chan interface{})
-		vmRoleAssignments5 = make(chan interface{})
-		vmRoleAssignments6 = make(chan interface{})
+		streams []<-chan AzureWrapper[interface{}]
+
+		apps              fanOut[AzureWrapper[models.App]]
+		devices           fanOut[AzureWrapper[models.Device]]
+		groups            fanOut[AzureWrapper[models.Group]]
+		roles             fanOut[AzureWrapper[models.Role]]
+		servicePrincipals fanOut[AzureWrapper[models.ServicePrincipal]]
 	)
 
-	// Enumerate Apps, AppOwners and AppMembers
-	pipeline.Tee(ctx.Done(), listApps(ctx, client), apps, apps2)
-	appOwners := listAppOwners(ctx, client, apps2)
+	// Enumerate Apps and AppOwners
+	if kinds.Has(enums.KindAZApp) {
+		streams = append(streams, untyped(ctx, apps.branch()))
+	}
+	if kinds.Has(enums.KindAZAppOwner) {
+		streams = append(streams, untyped(ctx, listAppOwners(ctx, client, apps.branch())))
+	}
+	apps.start(ctx, func() <-chan AzureWrapper[models.App] { return listApps(ctx, client) })
 
 	// Enumerate Devices and DeviceOwners
-	pipeline.Tee(ctx.Done(), listDevices(ctx, client), devices, devices2)
-	deviceOwners := listDeviceOwners(ctx, client, devices2)
+	if kinds.Has(enums.KindAZDevice) {
+		streams = append(streams, untyped(ctx, devices.branch()))
+	}
+	if kinds.Has(enums.KindAZDeviceOwner) {
+		streams = append(streams, untyped(ctx, listDeviceOwners(ctx, client, devices.branch())))
+	}
+	devices.start(ctx, func() <-chan AzureWrapper[models.Device] { return listDevices(ctx, client) })
 
 	// Enumerate Groups, GroupOwners and GroupMembers
-	pipeline.Tee(ctx.Done(), listGroups(ctx, client), groups, groups2, groups3)
-	groupOwners := listGroupOwners(ctx, client, groups2)
-	groupMembers := listGroupMembers(ctx, client, groups3)
-
-	// Enumerate Subscriptions, SubscriptionOwners and SubscriptionUserAccessAdmins
-	pipeline.Tee(ctx.Done(), listSubscriptions(ctx, client), subscriptions, subscriptions2, subscriptions3, subscriptions4, subscriptions5, subscriptions6)
-	subscriptionOwners := listSubscriptionOwners(ctx, client, subscriptions5)
-	subscriptionUserAccessAdmins := listSubscriptionUserAccessAdmins(ctx, client, subscriptions6)
-
-	// Enumerate KeyVaults, KeyVaultOwners, KeyVaultAccessPolicies and KeyVaultUserAccessAdmins
-	pipeline.Tee(ctx.Done(), listKeyVaults(ctx, client, subscriptions2), keyVaults, keyVaults2, keyVaults3, keyVaults4, keyVaults5)
-	keyVaultOwners := listKeyVaultOwners(ctx, client, keyVaults2)
-	keyVaultAccessPolicies := listKeyVaultAccessPolicies(ctx, client, keyVaults3, []enums.KeyVaultAccessType{enums.GetCerts, enums.GetKeys, enums.GetCerts})
-	keyVaultUserAccessAdmins := listKeyVaultUserAccessAdmins(ctx, client, keyVaults4)
-	keyVaultContributors := listKeyVaultContributors(ctx, client, keyVaults5)
-
-	// Enumerate ManagementGroups, ManagementGroupOwners and ManagementGroupDescendants
-	pipeline.Tee(ctx.Done(), listManagementGroups(ctx, client), mgmtGroups, mgmtGroups2, mgmtGroups3, mgmtGroups4)
-	mgmtGroupOwners := listManagementGroupOwners(ctx, client, mgmtGroups2)
-	mgmtGroupDescendants := listManagementGroupDescendants(ctx, client, mgmtGroups3)
-	mgmtGroupUserAccessAdmins := listManagementGroupUserAccessAdmins(ctx, client, mgmtGroups4)
-
-	// Enumerate ResourceGroups, ResourceGroupOwners and ResourceGroupUserAccessAdmins
-	pipeline.Tee(ctx.Done(), listResourceGroups(ctx, client, subscriptions3), resourceGroups, resourceGroups2, resourceGroups3)
-	resourceGroupOwners := listResourceGroupOwners(ctx, client, resourceGroups2)
-	resourceGroupUserAccessAdmins := listResourceGroupUserAccessAdmins(ctx, client, resourceGroups3)
+	if kinds.Has(enums.KindAZGroup) {
+		streams = append(streams, untyped(ctx, groups.branch()))
+	}
+	if kinds.Has(enums.KindAZGroupOwner) {
+		streams = append(streams, untyped(ctx, listGroupOwners(ctx, client, groups.branch())))
+	}
+	if kinds.Has(enums.KindAZGroupMember) {
+		streams = append(streams, untyped(ctx, listGroupMembers(ctx, client, groups.branch())))
+	}
+	groups.start(ctx, func() <-chan AzureWrapper[models.Group] { return listGroups(ctx, client) })
 
 	// Enumerate ServicePrincipals and ServicePrincipalOwners
-	pipeline.Tee(ctx.Done(), listServicePrincipals(ctx, client), servicePrincipals, servicePrincipals2)
-	servicePrincipalOwners := listServicePrincipalOwners(ctx, client, servicePrincipals2)
-
-	// Enumerate Tenants
-	pipeline.Tee(ctx.Done(), listTenants(ctx, client), tenants)
+	if kinds.Has(enums.KindAZServicePrincipal) {
+		streams = append(streams, untyped(ctx, servicePrincipals.branch()))
+	}
+	if kinds.Has(enums.KindAZServicePrincipalOwner) {
+		streams = append(streams, untyped(ctx, listServicePrincipalOwners(ctx, client, servicePrincipals.branch())))
+	}
+	servicePrincipals.start(ctx, func() <-chan AzureWrapper[models.ServicePrincipal] { return listServicePrincipals(ctx, client) })
 
 	// Enumerate Users
-	users := listUsers(ctx, client)
+	if kinds.Has(enums.KindAZUser) {
+		streams = append(streams, untyped(ctx, listUsers(ctx, client)))
+	}
 
 	// Enumerate Roles and RoleAssignments
-	pipeline.Tee(ctx.Done(), listRoles(ctx, client), roles, roles2)
-	roleAssignments := listRoleAssignments(ctx, client, roles2)
+	if kinds.Has(enums.KindAZRole) {
+		streams = append(streams, untyped(ctx, roles.branch()))
+	}
+	if kinds.Has(enums.KindAZRoleAssignment) {
+		streams = append(streams, untyped(ctx, listRoleAssignments(ctx, client, roles.branch())))
+	}
+	roles.start(ctx, func() <-chan AzureWrapper[models.Role] { return listRoles(ctx, client) })
 
-	// Enumerate VirtualMachines, VirtualMachineOwners, VirtualMachineAvereContributors, VirtualMachineContributors,
-	// VirtualMachineAdminLogins and VirtualMachineUserAccessAdmins
-	pipeline.Tee(ctx.Done(), listVirtualMachines(ctx, client, subscriptions4), virtualMachines, virtualMachines2)
-	pipeline.Tee(ctx.Done(), listVirtualMachineRoleAssignments(ctx, client, virtualMachines2), vmRoleAssignments1, vmRoleAssignments2, vmRoleAssignments3, vmRoleAssignments4, vmRoleAssignments5, vmRoleAssignments6)
-	virtualMachineOwners := listVirtualMachineOwners(ctx, client, vmRoleAssignments1)
-	virtualMachineAvereContributors := listVirtualMachineAvereContributors(ctx, client, vmRoleAssignments2)
-	virtualMachineContributors := listVirtualMachineContributors(ctx, client, vmRoleAssignments3)
-	virtualMachineAdminLogins := listVirtualMachineAdminLogins(ctx, client, vmRoleAssignments4)
-	virtualMachineUserAccessAdmins := listVirtualMachineUserAccessAdmins(ctx, client, vmRoleAssignments5)
-	virtualMachineVMContributors := listVirtualMachineVMContributors(ctx, client, vmRoleAssignments6)
-
-	return pipeline.Mux(ctx.Done(),
-		appOwners,
-		apps,
-		deviceOwners,
-		devices,
-		groupMembers,
-		groupOwners,
-		groups,
-		keyVaultAccessPolicies,
-		keyVaultContributors,
-		keyVaultOwners,
-		keyVaultUserAccessAdmins,
-		keyVaults,
-		mgmtGroupDescendants,
-		mgmtGroupOwners,
-		mgmtGroupUserAccessAdmins,
-		mgmtGroups,
-		resourceGroupOwners,
-		resourceGroupUserAccessAdmins,
-		resourceGroups,
-		roleAssignments,
-		roles,
-		servicePrincipalOwners,
-		servicePrincipals,
-		subscriptionOwners,
-		subscriptionUserAccessAdmins,
-		subscriptions,
-		tenants,
-		users,
-		virtualMachineAdminLogins,
-		virtualMachineAvereContributors,
-		virtualMachineContributors,
-		virtualMachineOwners,
-		virtualMachineUserAccessAdmins,
-		virtualMachineVMContributors,
-		virtualMachines,
+	return streams
+}
+
+// listAllResourceManagerObjects enumerates the selected kinds of object collected from Azure Resource Manager. Stages
+// are declared before the stages they consume so that a collector only runs if something downstream needs it.
+func listAllResourceManagerObjects(ctx context.Context, client client.AzureClient, kinds kindSelection) []<-chan AzureWrapper[interface{}] {
+	var (
+		streams []<-chan AzureWrapper[interface{}]
+
+		keyVaults         fanOut[AzureWrapper[models.KeyVault]]
+		mgmtGroups        fanOut[AzureWrapper[models.ManagementGroup]]
+		resourceGroups    fanOut[AzureWrapper[models.ResourceGroup]]
+		subscriptions     fanOut[AzureWrapper[models.Subscription]]
+		virtualMachines   fanOut[AzureWrapper[models.VirtualMachine]]
+		vmRoleAssignments fanOut[AzureWrapper[models.VirtualMachineRoleAssignments]]
 	)
+
+	// Enumerate VirtualMachines, VirtualMachineOwners, VirtualMachineAvereContributors, VirtualMachineContributors,
+	// VirtualMachineAdminLogins, VirtualMachineUserAccessAdmins and VirtualMachineVMContributors
+	if kinds.Has(enums.KindAZVMOwner) {
+		streams = append(streams, untyped(ctx, listVirtualMachineOwners(ctx, client, vmRoleAssignments.branch())))
+	}
+	if kinds.Has(enums.KindAZVMAvereContributor) {
+		streams = append(streams, untyped(ctx, listVirtualMachineAvereContributors(ctx, client, vmRoleAssignments.branch())))
+	}
+	if kinds.Has(enums.KindAZVMContributor) {
+		streams = append(streams, untyped(ctx, listVirtualMachineContributors(ctx, client, vmRoleAssignments.branch())))
+	}
+	if kinds.Has(enums.KindAZVMAdminLogin) {
+		streams = append(streams, untyped(ctx, listVirtualMachineAdminLogins(ctx, client, vmRoleAssignments.branch())))
+	}
+	if kinds.Has(enums.KindAZVMUserAccessAdmin) {
+		streams = append(streams, untyped(ctx, listVirtualMachineUserAccessAdmins(ctx, client, vmRoleAssignments.branch())))
+	}
+	if kinds.Has(enums.KindAZVMVMContributor) {
+		streams = append(streams, untyped(ctx, listVirtualMachineVMContributors(ctx, client, vmRoleAssignments.branch())))
+	}
+	vmRoleAssignments.start(ctx, func() <-chan AzureWrapper[models.VirtualMachineRoleAssignments] {
+		return listVirtualMachineRoleAssignments(ctx, client, virtualMachines.branch())
+	})
+	if kinds.Has(enums.KindAZVM) {
+		streams = append(streams, untyped(ctx, virtualMachines.branch()))
+	}
+	virtualMachines.start(ctx, func() <-chan AzureWrapper[models.VirtualMachine] {
+		return listVirtualMachines(ctx, client, subscriptions.branch())
+	})
+
+	// Enumerate KeyVaults, KeyVaultOwners, KeyVaultAccessPolicies, KeyVaultUserAccessAdmins and KeyVaultContributors
+	if kinds.Has(enums.KindAZKeyVault) {
+		streams = append(streams, untyped(ctx, keyVaults.branch()))
+	}
+	if kinds.Has(enums.KindAZKeyVaultOwner) {
+		streams = append(streams, untyped(ctx, listKeyVaultOwners(ctx, client, keyVaults.branch())))
+	}
+	if kinds.Has(enums.KindAZKeyVaultAccessPolicy) {
+		streams = append(streams, untyped(ctx, listKeyVaultAccessPolicies(ctx, client, keyVaults.branch(), []enums.KeyVaultAccessType{enums.GetCerts, enums.GetKeys, enums.GetCerts})))
+	}
+	if kinds.Has(enums.KindAZKeyVaultUserAccessAdmin) {
+		streams = append(streams, untyped(ctx, listKeyVaultUserAccessAdmins(ctx, client, keyVaults.branch())))
+	}
+	if kinds.Has(enums.KindAZKeyVaultContributor) {
+		streams = append(streams, untyped(ctx, listKeyVaultContributors(ctx, client, keyVaults.branch())))
+	}
+	keyVaults.start(ctx, func() <-chan AzureWrapper[models.KeyVault] {
+		return listKeyVaults(ctx, client, subscriptions.branch())
+	})
+
+	// Enumerate ResourceGroups, ResourceGroupOwners and ResourceGroupUserAccessAdmins
+	if kinds.Has(enums.KindAZResourceGroup) {
+		streams = append(streams, untyped(ctx, resourceGroups.branch()))
+	}
+	if kinds.Has(enums.KindAZResourceGroupOwner) {
+		streams = append(streams, untyped(ctx, listResourceGroupOwners(ctx, client, resourceGroups.branch())))
+	}
+	if kinds.Has(enums.KindAZResourceGroupUserAccessAdmin) {
+		streams = append(streams, untyped(ctx, listResourceGroupUserAccessAdmins(ctx, client, resourceGroups.branch())))
+	}
+	resourceGroups.start(ctx, func() <-chan AzureWrapper[models.ResourceGroup] {
+		return listResourceGroups(ctx, client, subscriptions.branch())
+	})
+
+	// Enumerate Subscriptions, SubscriptionOwners and SubscriptionUserAccessAdmins
+	if kinds.Has(enums.KindAZSubscription) {
+		streams = append(streams, untyped(ctx, subscriptions.branch()))
+	}
+	if kinds.Has(enums.KindAZSubscriptionOwner) {
+		streams = append(streams, untyped(ctx, listSubscriptionOwners(ctx, client, subscriptions.branch())))
+	}
+	if kinds.Has(enums.KindAZSubscriptionUserAccessAdmin) {
+		streams = append(streams, untyped(ctx, listSubscriptionUserAccessAdmins(ctx, client, subscriptions.branch())))
+	}
+	subscriptions.start(ctx, func() <-chan AzureWrapper[models.Subscription] { return listSubscriptions(ctx, client) })
+
+	// Enumerate ManagementGroups, ManagementGroupOwners, ManagementGroupDescendants and ManagementGroupUserAccessAdmins
+	if kinds.Has(enums.KindAZManagementGroup) {
+		streams = append(streams, untyped(ctx, mgmtGroups.branch()))
+	}
+	if kinds.Has(enums.KindAZManagementGroupOwner) {
+		streams = append(streams, untyped(ctx, listManagementGroupOwners(ctx, client, mgmtGroups.branch())))
+	}
+	if kinds.Has(enums.KindAZManagementGroupDescendant) {
+		streams = append(streams, untyped(ctx, listManagementGroupDescendants(ctx, client, mgmtGroups.branch())))
+	}
+	if kinds.Has(enums.KindAZManagementGroupUserAccessAdmin) {
+		streams = append(streams, untyped(ctx, listManagementGroupUserAccessAdmins(ctx, client, mgmtGroups.branch())))
+	}
+	mgmtGroups.start(ctx, func() <-chan AzureWrapper[models.ManagementGroup] { return listManagementGroups(ctx, client) })
+
+	// Enumerate Tenants
+	if kinds.Has(enums.KindAZTenant) {
+		streams = append(streams, untyped(ctx, listTenants(ctx, client)))
+	}
+
+	return streams
 }
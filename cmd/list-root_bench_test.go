@@ -0,0 +1,163 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/client/fake"
+	"github.com/go-logr/logr"
+)
+
+// e.g. go test ./cmd -run '^$' -bench ListAll -scale 10
+var benchScale = flag.Int("scale", 1, "multiplies the size of the synthetic tenants collected by benchmarks")
+
+var benchShapes = []struct {
+	name  string
+	shape fake.Shape
+}{
+	{"small", fake.Shape{
+		Users:                           100,
+		Groups:                          20,
+		GroupNestingDepth:               2,
+		MembersPerGroup:                 10,
+		ServicePrincipals:               20,
+		Subscriptions:                   1,
+		ResourceGroupsPerSubscription:   5,
+		VirtualMachinesPerResourceGroup: 2,
+		KeyVaultsPerResourceGroup:       1,
+		OwnersPerObject:                 1,
+		RoleAssignmentsPerScope:         1,
+	}},
+	{"large", fake.Shape{
+		Users:                           2000,
+		Groups:                          400,
+		GroupNestingDepth:               4,
+		MembersPerGroup:                 50,
+		ServicePrincipals:               400,
+		Subscriptions:                   4,
+		ResourceGroupsPerSubscription:   20,
+		VirtualMachinesPerResourceGroup: 5,
+		KeyVaultsPerResourceGroup:       2,
+		OwnersPerObject:                 2,
+		RoleAssignmentsPerScope:         3,
+	}},
+}
+
+// BenchmarkListAll collects synthetic tenants from a fake server, reporting throughput along with the peak number of
+// goroutines and the peak heap in use during collection
+func BenchmarkListAll(b *testing.B) {
+	defer func(logger logr.Logger) { log = logger }(log)
+	log = logr.Discard()
+
+	selection, err := newKindSelection(nil, nil)
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, bench := range benchShapes {
+		shape := scaleShape(bench.shape, *benchScale)
+		b.Run(fmt.Sprintf("%s-x%d", bench.name, *benchScale), func(b *testing.B) {
+			server := fake.NewServer(fake.Generate(shape, 1))
+			defer server.Close()
+			server.PageSize = 999
+
+			azClient, err := client.NewClient(server.Config())
+			if err != nil {
+				b.Fatalf("unexpected error: %v", err)
+			}
+
+			var (
+				ctx, stop = context.WithCancel(context.Background())
+				peak      = samplePeaks(ctx, 10*time.Millisecond)
+				items     int
+			)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for range listAll(ctx, azClient, selection) {
+					items++
+				}
+			}
+			b.StopTimer()
+
+			stop()
+			goroutines, heap := peak()
+			b.ReportMetric(float64(items)/b.Elapsed().Seconds(), "items/s")
+			b.ReportMetric(float64(shape.Objects()*b.N)/b.Elapsed().Seconds(), "objects/s")
+			b.ReportMetric(float64(goroutines), "peak-goroutines")
+			b.ReportMetric(float64(heap)/(1<<20), "peak-heap-MiB")
+		})
+	}
+}
+
+func scaleShape(shape fake.Shape, scale int) fake.Shape {
+	shape.Users *= scale
+	shape.Groups *= scale
+	shape.ServicePrincipals *= scale
+	shape.Subscriptions *= scale
+	return shape
+}
+
+// samplePeaks samples the number of goroutines and the heap in use until the context is done; the returned function
+// waits for sampling to stop and returns the peaks
+func samplePeaks(ctx context.Context, interval time.Duration) func() (int, uint64) {
+	var (
+		wg         sync.WaitGroup
+		goroutines int
+		heap       uint64
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var stats runtime.MemStats
+		for {
+			if n := runtime.NumGoroutine(); n > goroutines {
+				goroutines = n
+			}
+
+			runtime.ReadMemStats(&stats)
+			if stats.HeapInuse > heap {
+				heap = stats.HeapInuse
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() (int, uint64) {
+		wg.Wait()
+		return goroutines, heap
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/client/fake"
+	"github.com/bloodhoundad/azurehound/enums"
+)
+
+func TestListAllFakeTenant(t *testing.T) {
+	tenant, err := fake.ReadTenant("../client/fake/testdata/tenant.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := fake.NewServer(tenant)
+	defer server.Close()
+	server.PageSize = 2
+
+	azClient, err := client.NewClient(server.Config())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	selection, err := newKindSelection(nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	kinds := map[enums.Kind]int{}
+	for item := range listAll(context.Background(), azClient, selection) {
+		kinds[item.Kind]++
+	}
+
+	for kind, want := range map[enums.Kind]int{
+		enums.KindAZUser:             len(tenant.Users),
+		enums.KindAZGroup:            len(tenant.Groups),
+		enums.KindAZServicePrincipal: len(tenant.ServicePrincipals),
+		enums.KindAZSubscription:     len(tenant.Subscriptions),
+		enums.KindAZResourceGroup:    len(tenant.ResourceGroups),
+		enums.KindAZVM:               len(tenant.VirtualMachines),
+		enums.KindAZKeyVault:         len(tenant.KeyVaults),
+		enums.KindAZTenant:           1,
+	} {
+		if kinds[kind] != want {
+			t.Errorf("got %v %v, want %v", kinds[kind], kind, want)
+		}
+	}
+}
+
+func TestListAllSelectedKinds(t *testing.T) {
+	tenant, err := fake.ReadTenant("../client/fake/testdata/tenant.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := fake.NewServer(tenant)
+	defer server.Close()
+
+	azClient, err := client.NewClient(server.Config())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	selection, err := newKindSelection([]string{"AZUser", "AZGroupMember", "AZVMOwner"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	kinds := map[enums.Kind]int{}
+	for item := range listAll(context.Background(), azClient, selection) {
+		kinds[item.Kind]++
+	}
+
+	if kinds[enums.KindAZUser] != len(tenant.Users) {
+		t.Errorf("got %v %v, want %v", kinds[enums.KindAZUser], enums.KindAZUser, len(tenant.Users))
+	}
+	for kind := range kinds {
+		if !selection.Has(kind) {
+			t.Errorf("collected unselected kind %v", kind)
+		}
+	}
+
+	// Groups and subscriptions feed the selected kinds; nothing else should have been requested
+	for path, requested := range map[string]bool{
+		"/v1.0/users":             true,
+		"/v1.0/groups":            true,
+		"/subscriptions":          true,
+		"/v1.0/applications":      false,
+		"/v1.0/servicePrincipals": false,
+		"/v1.0/roleManagement/directory/roleDefinitions":   false,
+		"/providers/Microsoft.Management/managementGroups": false,
+		"/tenants": false,
+	} {
+		if got := server.Requests(path) > 0; got != requested {
+			t.Errorf("got requested %v for %v, want %v", got, path, requested)
+		}
+	}
+}
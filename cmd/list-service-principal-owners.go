@@ -19,13 +19,13 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"sync"
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
 	"github.com/bloodhoundad/azurehound/pipeline"
@@ -62,23 +62,20 @@ func listServicePrincipalOwnersCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listServicePrincipalOwners(ctx context.Context, client client.AzureClient, servicePrincipals <-chan interface{}) <-chan interface{} {
+func listServicePrincipalOwners(ctx context.Context, client client.AzureClient, servicePrincipals <-chan AzureWrapper[models.ServicePrincipal]) <-chan AzureWrapper[models.ServicePrincipalOwners] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.ServicePrincipalOwners])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), servicePrincipals) {
-			if servicePrincipal, ok := result.(AzureWrapper).Data.(models.ServicePrincipal); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating service principal owners", "result", result)
-				return
-			} else {
-				ids <- servicePrincipal.Id
+		for servicePrincipal := range pipeline.OrDone(ctx.Done(), servicePrincipals) {
+			if !completed(enums.KindAZServicePrincipalOwner, servicePrincipal.Data.Id) {
+				ids <- servicePrincipal.Data.Id
 			}
 		}
 	}()
@@ -91,13 +88,15 @@ func listServicePrincipalOwners(ctx context.Context, client client.AzureClient,
 			for id := range stream {
 				var (
 					servicePrincipalOwners = models.ServicePrincipalOwners{
-						ServicePrincipalId: id.(string),
+						ServicePrincipalId: id,
 					}
-					count = 0
+					count  = 0
+					failed = false
 				)
-				for item := range client.ListAzureADServicePrincipalOwners(ctx, id.(string), "", "", "", nil) {
+				for item := range client.ListAzureADServicePrincipalOwners(ctx, id, "", "", "", nil) {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing owners for this service principal", "servicePrincipalId", id)
+						logChildError(item.Error, "unable to continue processing owners for this service principal", "servicePrincipalId", id)
+						failed = true
 					} else {
 						servicePrincipalOwner := models.ServicePrincipalOwner{
 							Owner:              item.Ok,
@@ -108,8 +107,12 @@ func listServicePrincipalOwners(ctx context.Context, client client.AzureClient,
 						servicePrincipalOwners.Owners = append(servicePrincipalOwners.Owners, servicePrincipalOwner)
 					}
 				}
-				out <- AzureWrapper{
+				if ctx.Err() != nil {
+					return
+				}
+				out <- AzureWrapper[models.ServicePrincipalOwners]{
 					Kind: enums.KindAZServicePrincipalOwner,
+					id:   checkpointKey(id, failed),
 					Data: servicePrincipalOwners,
 				}
 				log.V(1).Info("finished listing service principal owners", "servicePrincipalId", id, "count", count)
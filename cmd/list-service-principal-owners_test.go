@@ -40,7 +40,7 @@ func TestListServicePrincipalOwners(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockServicePrincipalsChannel := make(chan interface{})
+	mockServicePrincipalsChannel := make(chan AzureWrapper[models.ServicePrincipal])
 	mockServicePrincipalOwnerChannel := make(chan azure.ServicePrincipalOwnerResult)
 	mockServicePrincipalOwnerChannel2 := make(chan azure.ServicePrincipalOwnerResult)
 
@@ -53,10 +53,10 @@ func TestListServicePrincipalOwners(t *testing.T) {
 
 	go func() {
 		defer close(mockServicePrincipalsChannel)
-		mockServicePrincipalsChannel <- AzureWrapper{
+		mockServicePrincipalsChannel <- AzureWrapper[models.ServicePrincipal]{
 			Data: models.ServicePrincipal{},
 		}
-		mockServicePrincipalsChannel <- AzureWrapper{
+		mockServicePrincipalsChannel <- AzureWrapper[models.ServicePrincipal]{
 			Data: models.ServicePrincipal{},
 		}
 	}()
@@ -81,21 +81,13 @@ func TestListServicePrincipalOwners(t *testing.T) {
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.ServicePrincipalOwners); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.ServicePrincipalOwners{})
-	} else if len(data.Owners) != 2 {
-		t.Errorf("got %v, want %v", len(data.Owners), 2)
+	} else if len(result.Data.Owners) != 2 {
+		t.Errorf("got %v, want %v", len(result.Data.Owners), 2)
 	}
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.ServicePrincipalOwners); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.ServicePrincipalOwners{})
-	} else if len(data.Owners) != 1 {
-		t.Errorf("got %v, want %v", len(data.Owners), 2)
+	} else if len(result.Data.Owners) != 1 {
+		t.Errorf("got %v, want %v", len(result.Data.Owners), 2)
 	}
 }
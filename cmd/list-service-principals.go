@@ -59,8 +59,8 @@ func listServicePrincipalsCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listServicePrincipals(ctx context.Context, client client.AzureClient) <-chan interface{} {
-	out := make(chan interface{})
+func listServicePrincipals(ctx context.Context, client client.AzureClient) <-chan AzureWrapper[models.ServicePrincipal] {
+	out := make(chan AzureWrapper[models.ServicePrincipal])
 
 	go func() {
 		defer close(out)
@@ -72,8 +72,9 @@ func listServicePrincipals(ctx context.Context, client client.AzureClient) <-cha
 			} else {
 				log.V(2).Info("found service principal", "servicePrincipal", item)
 				count++
-				out <- AzureWrapper{
+				out <- AzureWrapper[models.ServicePrincipal]{
 					Kind: enums.KindAZServicePrincipal,
+					id:   item.Ok.Id,
 					Data: models.ServicePrincipal{
 						ServicePrincipal: item.Ok,
 						TenantId:         client.TenantInfo().TenantId,
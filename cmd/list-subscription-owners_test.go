@@ -40,7 +40,7 @@ func TestListSubscriptionOwners(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockSubscriptionsChannel := make(chan interface{})
+	mockSubscriptionsChannel := make(chan AzureWrapper[models.Subscription])
 	mockSubscriptionOwnerChannel := make(chan azure.RoleAssignmentResult)
 	mockSubscriptionOwnerChannel2 := make(chan azure.RoleAssignmentResult)
 
@@ -53,10 +53,10 @@ func TestListSubscriptionOwners(t *testing.T) {
 
 	go func() {
 		defer close(mockSubscriptionsChannel)
-		mockSubscriptionsChannel <- AzureWrapper{
+		mockSubscriptionsChannel <- AzureWrapper[models.Subscription]{
 			Data: models.Subscription{},
 		}
-		mockSubscriptionsChannel <- AzureWrapper{
+		mockSubscriptionsChannel <- AzureWrapper[models.Subscription]{
 			Data: models.Subscription{},
 		}
 	}()
@@ -93,21 +93,13 @@ func TestListSubscriptionOwners(t *testing.T) {
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.SubscriptionOwners); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.SubscriptionOwners{})
-	} else if len(data.Owners) != 2 {
-		t.Errorf("got %v, want %v", len(data.Owners), 2)
+	} else if len(result.Data.Owners) != 2 {
+		t.Errorf("got %v, want %v", len(result.Data.Owners), 2)
 	}
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.SubscriptionOwners); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.SubscriptionOwners{})
-	} else if len(data.Owners) != 1 {
-		t.Errorf("got %v, want %v", len(data.Owners), 2)
+	} else if len(result.Data.Owners) != 1 {
+		t.Errorf("got %v, want %v", len(result.Data.Owners), 2)
 	}
 }
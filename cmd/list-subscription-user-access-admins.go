@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"path"
@@ -27,6 +26,7 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/constants"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
@@ -65,23 +65,20 @@ func listSubscriptionUserAccessAdminsCmdImpl(cmd *cobra.Command, args []string)
 	}
 }
 
-func listSubscriptionUserAccessAdmins(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}) <-chan interface{} {
+func listSubscriptionUserAccessAdmins(ctx context.Context, client client.AzureClient, subscriptions <-chan AzureWrapper[models.Subscription]) <-chan AzureWrapper[models.SubscriptionUserAccessAdmins] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.SubscriptionUserAccessAdmins])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), subscriptions) {
-			if subscription, ok := result.(AzureWrapper).Data.(models.Subscription); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating subscription user access admins", "result", result)
-				return
-			} else {
-				ids <- subscription.Id
+		for subscription := range pipeline.OrDone(ctx.Done(), subscriptions) {
+			if !completed(enums.KindAZSubscriptionUserAccessAdmin, subscription.Data.Id) {
+				ids <- subscription.Data.Id
 			}
 		}
 	}()
@@ -94,13 +91,15 @@ func listSubscriptionUserAccessAdmins(ctx context.Context, client client.AzureCl
 			for id := range stream {
 				var (
 					subscriptionUserAccessAdmins = models.SubscriptionUserAccessAdmins{
-						SubscriptionId: id.(string),
+						SubscriptionId: id,
 					}
-					count = 0
+					count  = 0
+					failed = false
 				)
-				for item := range client.ListRoleAssignmentsForResource(ctx, id.(string), "") {
+				for item := range client.ListRoleAssignmentsForResource(ctx, id, "") {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing user access admins for this subscription", "subscriptionId", id)
+						logChildError(item.Error, "unable to continue processing user access admins for this subscription", "subscriptionId", id)
+						failed = true
 					} else {
 						roleDefinitionId := path.Base(item.Ok.Properties.RoleDefinitionId)
 
@@ -115,8 +114,12 @@ func listSubscriptionUserAccessAdmins(ctx context.Context, client client.AzureCl
 						}
 					}
 				}
-				out <- AzureWrapper{
+				if ctx.Err() != nil {
+					return
+				}
+				out <- AzureWrapper[models.SubscriptionUserAccessAdmins]{
 					Kind: enums.KindAZSubscriptionUserAccessAdmin,
+					id:   checkpointKey(id, failed),
 					Data: subscriptionUserAccessAdmins,
 				}
 				log.V(1).Info("finished listing subscription user access admins", "subscriptionId", id, "count", count)
@@ -40,7 +40,7 @@ func TestListSubscriptionUserAccessAdmins(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockSubscriptionsChannel := make(chan interface{})
+	mockSubscriptionsChannel := make(chan AzureWrapper[models.Subscription])
 	mockSubscriptionUserAccessAdminChannel := make(chan azure.RoleAssignmentResult)
 	mockSubscriptionUserAccessAdminChannel2 := make(chan azure.RoleAssignmentResult)
 
@@ -53,10 +53,10 @@ func TestListSubscriptionUserAccessAdmins(t *testing.T) {
 
 	go func() {
 		defer close(mockSubscriptionsChannel)
-		mockSubscriptionsChannel <- AzureWrapper{
+		mockSubscriptionsChannel <- AzureWrapper[models.Subscription]{
 			Data: models.Subscription{},
 		}
-		mockSubscriptionsChannel <- AzureWrapper{
+		mockSubscriptionsChannel <- AzureWrapper[models.Subscription]{
 			Data: models.Subscription{},
 		}
 	}()
@@ -93,21 +93,13 @@ func TestListSubscriptionUserAccessAdmins(t *testing.T) {
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.SubscriptionUserAccessAdmins); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.SubscriptionUserAccessAdmins{})
-	} else if len(data.UserAccessAdmins) != 2 {
-		t.Errorf("got %v, want %v", len(data.UserAccessAdmins), 2)
+	} else if len(result.Data.UserAccessAdmins) != 2 {
+		t.Errorf("got %v, want %v", len(result.Data.UserAccessAdmins), 2)
 	}
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.SubscriptionUserAccessAdmins); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.SubscriptionUserAccessAdmins{})
-	} else if len(data.UserAccessAdmins) != 1 {
-		t.Errorf("got %v, want %v", len(data.UserAccessAdmins), 2)
+	} else if len(result.Data.UserAccessAdmins) != 1 {
+		t.Errorf("got %v, want %v", len(result.Data.UserAccessAdmins), 2)
 	}
 }
@@ -19,13 +19,11 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"time"
 
 	"github.com/bloodhoundad/azurehound/models"
-	"github.com/bloodhoundad/azurehound/models/azure"
 
 	"github.com/bloodhoundad/azurehound/client"
 	"github.com/bloodhoundad/azurehound/config"
@@ -63,8 +61,8 @@ func listSubscriptionsCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listSubscriptions(ctx context.Context, client client.AzureClient) <-chan interface{} {
-	out := make(chan interface{})
+func listSubscriptions(ctx context.Context, client client.AzureClient) <-chan AzureWrapper[models.Subscription] {
+	out := make(chan AzureWrapper[models.Subscription])
 
 	go func() {
 		defer close(out)
@@ -77,12 +75,9 @@ func listSubscriptions(ctx context.Context, client client.AzureClient) <-chan in
 
 		if len(selectedMgmtGroupIds) != 0 {
 			descendantChannel := listManagementGroupDescendants(ctx, client, listManagementGroups(ctx, client))
-			for i := range descendantChannel {
-				if item, ok := i.(AzureWrapper).Data.(azure.DescendantInfo); !ok {
-					log.Error(fmt.Errorf("failed type assertion"), "unable to continue evaluating management group descendants", "result", i)
-					return
-				} else if item.Type == "Microsoft.Management/managementGroups/subscriptions" {
-					selectedSubIds = append(selectedSubIds, item.Name)
+			for item := range descendantChannel {
+				if item.Data.Type == "Microsoft.Management/managementGroups/subscriptions" {
+					selectedSubIds = append(selectedSubIds, item.Data.Name)
 				}
 			}
 		}
@@ -101,8 +96,9 @@ func listSubscriptions(ctx context.Context, client client.AzureClient) <-chan in
 					Subscription: item.Ok,
 				}
 				data.TenantId = client.TenantInfo().TenantId
-				out <- AzureWrapper{
+				out <- AzureWrapper[models.Subscription]{
 					Kind: enums.KindAZSubscription,
+					id:   item.Ok.Id,
 					Data: data,
 				}
 			}
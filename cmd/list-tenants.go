@@ -59,16 +59,17 @@ func listTenantsCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listTenants(ctx context.Context, client client.AzureClient) <-chan interface{} {
-	out := make(chan interface{})
+func listTenants(ctx context.Context, client client.AzureClient) <-chan AzureWrapper[models.Tenant] {
+	out := make(chan AzureWrapper[models.Tenant])
 
 	go func() {
 		defer close(out)
 
 		// Send the fully hydrated tenant that is being collected
 		collectedTenant := client.TenantInfo()
-		out <- AzureWrapper{
+		out <- AzureWrapper[models.Tenant]{
 			Kind: enums.KindAZTenant,
+			id:   collectedTenant.TenantId,
 			Data: models.Tenant{
 				Tenant:    collectedTenant,
 				Collected: true,
@@ -85,8 +86,9 @@ func listTenants(ctx context.Context, client client.AzureClient) <-chan interfac
 
 				// Send the remaining tenant trusts
 				if item.Ok.TenantId != collectedTenant.TenantId {
-					out <- AzureWrapper{
+					out <- AzureWrapper[models.Tenant]{
 						Kind: enums.KindAZTenant,
+						id:   item.Ok.TenantId,
 						Data: models.Tenant{
 							Tenant: item.Ok,
 						},
@@ -59,8 +59,8 @@ func listUsersCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listUsers(ctx context.Context, client client.AzureClient) <-chan interface{} {
-	out := make(chan interface{})
+func listUsers(ctx context.Context, client client.AzureClient) <-chan AzureWrapper[models.User] {
+	out := make(chan AzureWrapper[models.User])
 
 	go func() {
 		defer close(out)
@@ -77,8 +77,9 @@ func listUsers(ctx context.Context, client client.AzureClient) <-chan interface{
 					TenantId:   client.TenantInfo().TenantId,
 					TenantName: client.TenantInfo().DisplayName,
 				}
-				out <- AzureWrapper{
+				out <- AzureWrapper[models.User]{
 					Kind: enums.KindAZUser,
+					id:   item.Ok.Id,
 					Data: user,
 				}
 			}
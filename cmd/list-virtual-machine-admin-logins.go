@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"path"
@@ -66,42 +65,39 @@ func listVirtualMachineAdminLoginsCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listVirtualMachineAdminLogins(ctx context.Context, client client.AzureClient, vmRoleAssignments <-chan interface{}) <-chan interface{} {
-	out := make(chan interface{})
+func listVirtualMachineAdminLogins(ctx context.Context, client client.AzureClient, vmRoleAssignments <-chan AzureWrapper[models.VirtualMachineRoleAssignments]) <-chan AzureWrapper[models.VirtualMachineAdminLogins] {
+	out := make(chan AzureWrapper[models.VirtualMachineAdminLogins])
 
 	go func() {
 		defer close(out)
 
 		for result := range pipeline.OrDone(ctx.Done(), vmRoleAssignments) {
-			if roleAssignments, ok := result.(AzureWrapper).Data.(models.VirtualMachineRoleAssignments); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating virtual machine contributors", "result", result)
-				return
-			} else {
-				var (
-					virtualMachineAdminLogins = models.VirtualMachineAdminLogins{
-						VirtualMachineId: roleAssignments.VirtualMachineId,
-					}
-					count = 0
-				)
-				for _, item := range roleAssignments.RoleAssignments {
-					roleDefinitionId := path.Base(item.RoleAssignment.Properties.RoleDefinitionId)
+			roleAssignments := result.Data
+			var (
+				virtualMachineAdminLogins = models.VirtualMachineAdminLogins{
+					VirtualMachineId: roleAssignments.VirtualMachineId,
+				}
+				count = 0
+			)
+			for _, item := range roleAssignments.RoleAssignments {
+				roleDefinitionId := path.Base(item.RoleAssignment.Properties.RoleDefinitionId)
 
-					if roleDefinitionId == constants.VirtualMachineAdministratorLoginRoleID {
-						virtualMachineAdminLogin := models.VirtualMachineAdminLogin{
-							AdminLogin:       item.RoleAssignment,
-							VirtualMachineId: item.VirtualMachineId,
-						}
-						log.V(2).Info("found virtual machine admin login", "virtualMachineAdminLogin", virtualMachineAdminLogin)
-						count++
-						virtualMachineAdminLogins.AdminLogins = append(virtualMachineAdminLogins.AdminLogins, virtualMachineAdminLogin)
+				if roleDefinitionId == constants.VirtualMachineAdministratorLoginRoleID {
+					virtualMachineAdminLogin := models.VirtualMachineAdminLogin{
+						AdminLogin:       item.RoleAssignment,
+						VirtualMachineId: item.VirtualMachineId,
 					}
+					log.V(2).Info("found virtual machine admin login", "virtualMachineAdminLogin", virtualMachineAdminLogin)
+					count++
+					virtualMachineAdminLogins.AdminLogins = append(virtualMachineAdminLogins.AdminLogins, virtualMachineAdminLogin)
 				}
-				out <- AzureWrapper{
-					Kind: enums.KindAZVMAdminLogin,
-					Data: virtualMachineAdminLogins,
-				}
-				log.V(1).Info("finished listing virtual machine admin logins", "virtualMachineId", roleAssignments.VirtualMachineId, "count", count)
 			}
+			out <- AzureWrapper[models.VirtualMachineAdminLogins]{
+				Kind: enums.KindAZVMAdminLogin,
+				id:   roleAssignments.VirtualMachineId,
+				Data: virtualMachineAdminLogins,
+			}
+			log.V(1).Info("finished listing virtual machine admin logins", "virtualMachineId", roleAssignments.VirtualMachineId, "count", count)
 		}
 		log.Info("finished listing all virtual machine admin logins")
 	}()
@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"path"
@@ -66,42 +65,39 @@ func listVirtualMachineAvereContributorsCmdImpl(cmd *cobra.Command, args []strin
 	}
 }
 
-func listVirtualMachineAvereContributors(ctx context.Context, client client.AzureClient, vmRoleAssignments <-chan interface{}) <-chan interface{} {
-	out := make(chan interface{})
+func listVirtualMachineAvereContributors(ctx context.Context, client client.AzureClient, vmRoleAssignments <-chan AzureWrapper[models.VirtualMachineRoleAssignments]) <-chan AzureWrapper[models.VirtualMachineAvereContributors] {
+	out := make(chan AzureWrapper[models.VirtualMachineAvereContributors])
 
 	go func() {
 		defer close(out)
 
 		for result := range pipeline.OrDone(ctx.Done(), vmRoleAssignments) {
-			if roleAssignments, ok := result.(AzureWrapper).Data.(models.VirtualMachineRoleAssignments); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating virtual machine avere contributors", "result", result)
-				return
-			} else {
-				var (
-					virtualMachineAvereContributors = models.VirtualMachineAvereContributors{
-						VirtualMachineId: roleAssignments.VirtualMachineId,
-					}
-					count = 0
-				)
-				for _, item := range roleAssignments.RoleAssignments {
-					roleDefinitionId := path.Base(item.RoleAssignment.Properties.RoleDefinitionId)
+			roleAssignments := result.Data
+			var (
+				virtualMachineAvereContributors = models.VirtualMachineAvereContributors{
+					VirtualMachineId: roleAssignments.VirtualMachineId,
+				}
+				count = 0
+			)
+			for _, item := range roleAssignments.RoleAssignments {
+				roleDefinitionId := path.Base(item.RoleAssignment.Properties.RoleDefinitionId)
 
-					if roleDefinitionId == constants.AvereContributorRoleID {
-						virtualMachineAvereContributor := models.VirtualMachineAvereContributor{
-							AvereContributor: item.RoleAssignment,
-							VirtualMachineId: item.VirtualMachineId,
-						}
-						log.V(2).Info("found virtual machine avere contributor", "virtualMachineAvereContributor", virtualMachineAvereContributor)
-						count++
-						virtualMachineAvereContributors.AvereContributors = append(virtualMachineAvereContributors.AvereContributors, virtualMachineAvereContributor)
+				if roleDefinitionId == constants.AvereContributorRoleID {
+					virtualMachineAvereContributor := models.VirtualMachineAvereContributor{
+						AvereContributor: item.RoleAssignment,
+						VirtualMachineId: item.VirtualMachineId,
 					}
+					log.V(2).Info("found virtual machine avere contributor", "virtualMachineAvereContributor", virtualMachineAvereContributor)
+					count++
+					virtualMachineAvereContributors.AvereContributors = append(virtualMachineAvereContributors.AvereContributors, virtualMachineAvereContributor)
 				}
-				out <- AzureWrapper{
-					Kind: enums.KindAZVMAvereContributor,
-					Data: virtualMachineAvereContributors,
-				}
-				log.V(1).Info("finished listing virtual machine avere contributors", "virtualMachineId", roleAssignments.VirtualMachineId, "count", count)
 			}
+			out <- AzureWrapper[models.VirtualMachineAvereContributors]{
+				Kind: enums.KindAZVMAvereContributor,
+				id:   roleAssignments.VirtualMachineId,
+				Data: virtualMachineAvereContributors,
+			}
+			log.V(1).Info("finished listing virtual machine avere contributors", "virtualMachineId", roleAssignments.VirtualMachineId, "count", count)
 		}
 		log.Info("finished listing all virtual machine avere contributors")
 	}()
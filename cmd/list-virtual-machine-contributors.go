@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"path"
@@ -66,42 +65,39 @@ func listVirtualMachineContributorsCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listVirtualMachineContributors(ctx context.Context, client client.AzureClient, vmRoleAssignments <-chan interface{}) <-chan interface{} {
-	out := make(chan interface{})
+func listVirtualMachineContributors(ctx context.Context, client client.AzureClient, vmRoleAssignments <-chan AzureWrapper[models.VirtualMachineRoleAssignments]) <-chan AzureWrapper[models.VirtualMachineContributors] {
+	out := make(chan AzureWrapper[models.VirtualMachineContributors])
 
 	go func() {
 		defer close(out)
 
 		for result := range pipeline.OrDone(ctx.Done(), vmRoleAssignments) {
-			if roleAssignments, ok := result.(AzureWrapper).Data.(models.VirtualMachineRoleAssignments); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating virtual machine contributors", "result", result)
-				return
-			} else {
-				var (
-					virtualMachineContributors = models.VirtualMachineContributors{
-						VirtualMachineId: roleAssignments.VirtualMachineId,
-					}
-					count = 0
-				)
-				for _, item := range roleAssignments.RoleAssignments {
-					roleDefinitionId := path.Base(item.RoleAssignment.Properties.RoleDefinitionId)
+			roleAssignments := result.Data
+			var (
+				virtualMachineContributors = models.VirtualMachineContributors{
+					VirtualMachineId: roleAssignments.VirtualMachineId,
+				}
+				count = 0
+			)
+			for _, item := range roleAssignments.RoleAssignments {
+				roleDefinitionId := path.Base(item.RoleAssignment.Properties.RoleDefinitionId)
 
-					if roleDefinitionId == constants.ContributorRoleID {
-						virtualMachineContributor := models.VirtualMachineContributor{
-							Contributor:      item.RoleAssignment,
-							VirtualMachineId: item.VirtualMachineId,
-						}
-						log.V(2).Info("found virtual machine contributor", "virtualMachineContributor", virtualMachineContributor)
-						count++
-						virtualMachineContributors.Contributors = append(virtualMachineContributors.Contributors, virtualMachineContributor)
+				if roleDefinitionId == constants.ContributorRoleID {
+					virtualMachineContributor := models.VirtualMachineContributor{
+						Contributor:      item.RoleAssignment,
+						VirtualMachineId: item.VirtualMachineId,
 					}
+					log.V(2).Info("found virtual machine contributor", "virtualMachineContributor", virtualMachineContributor)
+					count++
+					virtualMachineContributors.Contributors = append(virtualMachineContributors.Contributors, virtualMachineContributor)
 				}
-				out <- AzureWrapper{
-					Kind: enums.KindAZVMContributor,
-					Data: virtualMachineContributors,
-				}
-				log.V(1).Info("finished listing virtual machine contributors", "virtualMachineId", roleAssignments.VirtualMachineId, "count", count)
 			}
+			out <- AzureWrapper[models.VirtualMachineContributors]{
+				Kind: enums.KindAZVMContributor,
+				id:   roleAssignments.VirtualMachineId,
+				Data: virtualMachineContributors,
+			}
+			log.V(1).Info("finished listing virtual machine contributors", "virtualMachineId", roleAssignments.VirtualMachineId, "count", count)
 		}
 		log.Info("finished listing all virtual machine contributors")
 	}()
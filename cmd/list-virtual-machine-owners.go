@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"path"
@@ -66,42 +65,39 @@ func listVirtualMachineOwnersCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listVirtualMachineOwners(ctx context.Context, client client.AzureClient, vmRoleAssignments <-chan interface{}) <-chan interface{} {
-	out := make(chan interface{})
+func listVirtualMachineOwners(ctx context.Context, client client.AzureClient, vmRoleAssignments <-chan AzureWrapper[models.VirtualMachineRoleAssignments]) <-chan AzureWrapper[models.VirtualMachineOwners] {
+	out := make(chan AzureWrapper[models.VirtualMachineOwners])
 
 	go func() {
 		defer close(out)
 
 		for result := range pipeline.OrDone(ctx.Done(), vmRoleAssignments) {
-			if roleAssignments, ok := result.(AzureWrapper).Data.(models.VirtualMachineRoleAssignments); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating virtual machine owners", "result", result)
-				return
-			} else {
-				var (
-					virtualMachineOwners = models.VirtualMachineOwners{
-						VirtualMachineId: roleAssignments.VirtualMachineId,
-					}
-					count = 0
-				)
-				for _, item := range roleAssignments.RoleAssignments {
-					roleDefinitionId := path.Base(item.RoleAssignment.Properties.RoleDefinitionId)
+			roleAssignments := result.Data
+			var (
+				virtualMachineOwners = models.VirtualMachineOwners{
+					VirtualMachineId: roleAssignments.VirtualMachineId,
+				}
+				count = 0
+			)
+			for _, item := range roleAssignments.RoleAssignments {
+				roleDefinitionId := path.Base(item.RoleAssignment.Properties.RoleDefinitionId)
 
-					if roleDefinitionId == constants.OwnerRoleID {
-						virtualMachineOwner := models.VirtualMachineOwner{
-							Owner:            item.RoleAssignment,
-							VirtualMachineId: item.VirtualMachineId,
-						}
-						log.V(2).Info("found virtual machine owner", "virtualMachineOwner", virtualMachineOwner)
-						count++
-						virtualMachineOwners.Owners = append(virtualMachineOwners.Owners, virtualMachineOwner)
+				if roleDefinitionId == constants.OwnerRoleID {
+					virtualMachineOwner := models.VirtualMachineOwner{
+						Owner:            item.RoleAssignment,
+						VirtualMachineId: item.VirtualMachineId,
 					}
+					log.V(2).Info("found virtual machine owner", "virtualMachineOwner", virtualMachineOwner)
+					count++
+					virtualMachineOwners.Owners = append(virtualMachineOwners.Owners, virtualMachineOwner)
 				}
-				out <- AzureWrapper{
-					Kind: enums.KindAZVMOwner,
-					Data: virtualMachineOwners,
-				}
-				log.V(1).Info("finished listing virtual machine owners", "virtualMachineId", roleAssignments.VirtualMachineId, "count", count)
 			}
+			out <- AzureWrapper[models.VirtualMachineOwners]{
+				Kind: enums.KindAZVMOwner,
+				id:   roleAssignments.VirtualMachineId,
+				Data: virtualMachineOwners,
+			}
+			log.V(1).Info("finished listing virtual machine owners", "virtualMachineId", roleAssignments.VirtualMachineId, "count", count)
 		}
 	}()
 
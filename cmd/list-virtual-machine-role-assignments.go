@@ -19,13 +19,13 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"sync"
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
 	"github.com/bloodhoundad/azurehound/pipeline"
@@ -63,24 +63,19 @@ func listVirtualMachineRoleAssignmentsCmdImpl(cmd *cobra.Command, args []string)
 	}
 }
 
-func listVirtualMachineRoleAssignments(ctx context.Context, client client.AzureClient, virtualMachines <-chan interface{}) <-chan interface{} {
+func listVirtualMachineRoleAssignments(ctx context.Context, client client.AzureClient, virtualMachines <-chan AzureWrapper[models.VirtualMachine]) <-chan AzureWrapper[models.VirtualMachineRoleAssignments] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.VirtualMachineRoleAssignments])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
 
-		for result := range pipeline.OrDone(ctx.Done(), virtualMachines) {
-			if virtualMachine, ok := result.(AzureWrapper).Data.(models.VirtualMachine); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating virtual machine role assignments", "result", result)
-				return
-			} else {
-				ids <- virtualMachine.Id
-			}
+		for virtualMachine := range pipeline.OrDone(ctx.Done(), virtualMachines) {
+			ids <- virtualMachine.Data.Id
 		}
 	}()
 
@@ -92,13 +87,13 @@ func listVirtualMachineRoleAssignments(ctx context.Context, client client.AzureC
 			for id := range stream {
 				var (
 					virtualMachineRoleAssignments = models.VirtualMachineRoleAssignments{
-						VirtualMachineId: id.(string),
+						VirtualMachineId: id,
 					}
 					count = 0
 				)
-				for item := range client.ListRoleAssignmentsForResource(ctx, id.(string), "") {
+				for item := range client.ListRoleAssignmentsForResource(ctx, id, "") {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing role assignments for this virtual machine", "virtualMachineId", id)
+						logChildError(item.Error, "unable to continue processing role assignments for this virtual machine", "virtualMachineId", id)
 					} else {
 						virtualMachineRoleAssignment := models.VirtualMachineRoleAssignment{
 							VirtualMachineId: item.ParentId,
@@ -109,8 +104,9 @@ func listVirtualMachineRoleAssignments(ctx context.Context, client client.AzureC
 						virtualMachineRoleAssignments.RoleAssignments = append(virtualMachineRoleAssignments.RoleAssignments, virtualMachineRoleAssignment)
 					}
 				}
-				out <- AzureWrapper{
+				out <- AzureWrapper[models.VirtualMachineRoleAssignments]{
 					Kind: enums.KindAZVMRoleAssignment,
+					id:   id,
 					Data: virtualMachineRoleAssignments,
 				}
 				log.V(1).Info("finished listing virtual machine role assignments", "virtualMachineId", id, "count", count)
@@ -40,7 +40,7 @@ func TestListVirtualMachineRoleAssignments(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockVirtualMachinesChannel := make(chan interface{})
+	mockVirtualMachinesChannel := make(chan AzureWrapper[models.VirtualMachine])
 	mockVirtualMachineRoleAssignmentChannel := make(chan azure.RoleAssignmentResult)
 	mockVirtualMachineRoleAssignmentChannel2 := make(chan azure.RoleAssignmentResult)
 
@@ -53,10 +53,10 @@ func TestListVirtualMachineRoleAssignments(t *testing.T) {
 
 	go func() {
 		defer close(mockVirtualMachinesChannel)
-		mockVirtualMachinesChannel <- AzureWrapper{
+		mockVirtualMachinesChannel <- AzureWrapper[models.VirtualMachine]{
 			Data: models.VirtualMachine{},
 		}
-		mockVirtualMachinesChannel <- AzureWrapper{
+		mockVirtualMachinesChannel <- AzureWrapper[models.VirtualMachine]{
 			Data: models.VirtualMachine{},
 		}
 	}()
@@ -93,21 +93,13 @@ func TestListVirtualMachineRoleAssignments(t *testing.T) {
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.VirtualMachineRoleAssignments); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.VirtualMachineRoleAssignments{})
-	} else if len(data.RoleAssignments) != 2 {
-		t.Errorf("got %v, want %v", len(data.RoleAssignments), 2)
+	} else if len(result.Data.RoleAssignments) != 2 {
+		t.Errorf("got %v, want %v", len(result.Data.RoleAssignments), 2)
 	}
 
 	if result, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if data, ok := wrapper.Data.(models.VirtualMachineRoleAssignments); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.VirtualMachineRoleAssignments{})
-	} else if len(data.RoleAssignments) != 1 {
-		t.Errorf("got %v, want %v", len(data.RoleAssignments), 2)
+	} else if len(result.Data.RoleAssignments) != 1 {
+		t.Errorf("got %v, want %v", len(result.Data.RoleAssignments), 2)
 	}
 }
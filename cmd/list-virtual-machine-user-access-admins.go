@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"path"
@@ -66,42 +65,39 @@ func listVirtualMachineUserAccessAdminsCmdImpl(cmd *cobra.Command, args []string
 	}
 }
 
-func listVirtualMachineUserAccessAdmins(ctx context.Context, client client.AzureClient, vmRoleAssignments <-chan interface{}) <-chan interface{} {
-	out := make(chan interface{})
+func listVirtualMachineUserAccessAdmins(ctx context.Context, client client.AzureClient, vmRoleAssignments <-chan AzureWrapper[models.VirtualMachineRoleAssignments]) <-chan AzureWrapper[models.VirtualMachineUserAccessAdmins] {
+	out := make(chan AzureWrapper[models.VirtualMachineUserAccessAdmins])
 
 	go func() {
 		defer close(out)
 
 		for result := range pipeline.OrDone(ctx.Done(), vmRoleAssignments) {
-			if roleAssignments, ok := result.(AzureWrapper).Data.(models.VirtualMachineRoleAssignments); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating virtual machine user access admins", "result", result)
-				return
-			} else {
-				var (
-					virtualMachineUserAccessAdmins = models.VirtualMachineUserAccessAdmins{
-						VirtualMachineId: roleAssignments.VirtualMachineId,
-					}
-					count = 0
-				)
-				for _, item := range roleAssignments.RoleAssignments {
-					roleDefinitionId := path.Base(item.RoleAssignment.Properties.RoleDefinitionId)
+			roleAssignments := result.Data
+			var (
+				virtualMachineUserAccessAdmins = models.VirtualMachineUserAccessAdmins{
+					VirtualMachineId: roleAssignments.VirtualMachineId,
+				}
+				count = 0
+			)
+			for _, item := range roleAssignments.RoleAssignments {
+				roleDefinitionId := path.Base(item.RoleAssignment.Properties.RoleDefinitionId)
 
-					if roleDefinitionId == constants.UserAccessAdminRoleID {
-						virtualMachineUserAccessAdmin := models.VirtualMachineUserAccessAdmin{
-							UserAccessAdmin:  item.RoleAssignment,
-							VirtualMachineId: item.VirtualMachineId,
-						}
-						log.V(2).Info("found virtual machine user access admin", "virtualMachineUserAccessAdmin", virtualMachineUserAccessAdmin)
-						count++
-						virtualMachineUserAccessAdmins.UserAccessAdmins = append(virtualMachineUserAccessAdmins.UserAccessAdmins, virtualMachineUserAccessAdmin)
+				if roleDefinitionId == constants.UserAccessAdminRoleID {
+					virtualMachineUserAccessAdmin := models.VirtualMachineUserAccessAdmin{
+						UserAccessAdmin:  item.RoleAssignment,
+						VirtualMachineId: item.VirtualMachineId,
 					}
+					log.V(2).Info("found virtual machine user access admin", "virtualMachineUserAccessAdmin", virtualMachineUserAccessAdmin)
+					count++
+					virtualMachineUserAccessAdmins.UserAccessAdmins = append(virtualMachineUserAccessAdmins.UserAccessAdmins, virtualMachineUserAccessAdmin)
 				}
-				out <- AzureWrapper{
-					Kind: enums.KindAZVMUserAccessAdmin,
-					Data: virtualMachineUserAccessAdmins,
-				}
-				log.V(1).Info("finished listing virtual machine user access admins", "virtualMachineId", roleAssignments.VirtualMachineId, "count", count)
 			}
+			out <- AzureWrapper[models.VirtualMachineUserAccessAdmins]{
+				Kind: enums.KindAZVMUserAccessAdmin,
+				id:   roleAssignments.VirtualMachineId,
+				Data: virtualMachineUserAccessAdmins,
+			}
+			log.V(1).Info("finished listing virtual machine user access admins", "virtualMachineId", roleAssignments.VirtualMachineId, "count", count)
 		}
 		log.Info("finished listing all virtual machine user access admins")
 	}()
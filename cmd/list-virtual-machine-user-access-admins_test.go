@@ -39,7 +39,7 @@ func TestListVirtualMachineUserAccessAdmins(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockVMRoleAssignmentsChannel := make(chan interface{})
+	mockVMRoleAssignmentsChannel := make(chan AzureWrapper[models.VirtualMachineRoleAssignments])
 	mockTenant := azure.Tenant{}
 	mockClient.EXPECT().TenantInfo().Return(mockTenant).AnyTimes()
 	channel := listVirtualMachineUserAccessAdmins(ctx, mockClient, mockVMRoleAssignmentsChannel)
@@ -47,7 +47,7 @@ func TestListVirtualMachineUserAccessAdmins(t *testing.T) {
 	go func() {
 		defer close(mockVMRoleAssignmentsChannel)
 
-		mockVMRoleAssignmentsChannel <- AzureWrapper{
+		mockVMRoleAssignmentsChannel <- AzureWrapper[models.VirtualMachineRoleAssignments]{
 			Data: models.VirtualMachineRoleAssignments{
 				VirtualMachineId: "foo",
 				RoleAssignments: []models.VirtualMachineRoleAssignment{
@@ -64,12 +64,8 @@ func TestListVirtualMachineUserAccessAdmins(t *testing.T) {
 		}
 	}()
 
-	if result, ok := <-channel; !ok {
+	if _, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if _, ok := wrapper.Data.(models.VirtualMachineUserAccessAdmins); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.VirtualMachineUserAccessAdmins{})
 	}
 
 	if _, ok := <-channel; ok {
@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"path"
@@ -66,42 +65,39 @@ func listVirtualMachineVMContributorsCmdImpl(cmd *cobra.Command, args []string)
 	}
 }
 
-func listVirtualMachineVMContributors(ctx context.Context, client client.AzureClient, vmRoleAssignments <-chan interface{}) <-chan interface{} {
-	out := make(chan interface{})
+func listVirtualMachineVMContributors(ctx context.Context, client client.AzureClient, vmRoleAssignments <-chan AzureWrapper[models.VirtualMachineRoleAssignments]) <-chan AzureWrapper[models.VirtualMachineVMContributors] {
+	out := make(chan AzureWrapper[models.VirtualMachineVMContributors])
 
 	go func() {
 		defer close(out)
 
 		for result := range pipeline.OrDone(ctx.Done(), vmRoleAssignments) {
-			if roleAssignments, ok := result.(AzureWrapper).Data.(models.VirtualMachineRoleAssignments); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating virtual machine vmcontributors", "result", result)
-				return
-			} else {
-				var (
-					virtualMachineVMContributors = models.VirtualMachineVMContributors{
-						VirtualMachineId: roleAssignments.VirtualMachineId,
-					}
-					count = 0
-				)
-				for _, item := range roleAssignments.RoleAssignments {
-					roleDefinitionId := path.Base(item.RoleAssignment.Properties.RoleDefinitionId)
+			roleAssignments := result.Data
+			var (
+				virtualMachineVMContributors = models.VirtualMachineVMContributors{
+					VirtualMachineId: roleAssignments.VirtualMachineId,
+				}
+				count = 0
+			)
+			for _, item := range roleAssignments.RoleAssignments {
+				roleDefinitionId := path.Base(item.RoleAssignment.Properties.RoleDefinitionId)
 
-					if roleDefinitionId == constants.VirtualMachineContributorRoleID {
-						virtualMachineVMContributor := models.VirtualMachineVMContributor{
-							VMContributor:    item.RoleAssignment,
-							VirtualMachineId: item.VirtualMachineId,
-						}
-						log.V(2).Info("found virtual machine contributor", "vmContributor", virtualMachineVMContributor)
-						count++
-						virtualMachineVMContributors.VMContributors = append(virtualMachineVMContributors.VMContributors, virtualMachineVMContributor)
+				if roleDefinitionId == constants.VirtualMachineContributorRoleID {
+					virtualMachineVMContributor := models.VirtualMachineVMContributor{
+						VMContributor:    item.RoleAssignment,
+						VirtualMachineId: item.VirtualMachineId,
 					}
+					log.V(2).Info("found virtual machine contributor", "vmContributor", virtualMachineVMContributor)
+					count++
+					virtualMachineVMContributors.VMContributors = append(virtualMachineVMContributors.VMContributors, virtualMachineVMContributor)
 				}
-				out <- AzureWrapper{
-					Kind: enums.KindAZVMVMContributor,
-					Data: virtualMachineVMContributors,
-				}
-				log.V(1).Info("finished listing virtual machine contributors", "virtualMachineId", roleAssignments.VirtualMachineId, "count", count)
 			}
+			out <- AzureWrapper[models.VirtualMachineVMContributors]{
+				Kind: enums.KindAZVMVMContributor,
+				id:   roleAssignments.VirtualMachineId,
+				Data: virtualMachineVMContributors,
+			}
+			log.V(1).Info("finished listing virtual machine contributors", "virtualMachineId", roleAssignments.VirtualMachineId, "count", count)
 		}
 		log.Info("finished listing all virtual machine vmcontributors")
 	}()
@@ -19,13 +19,13 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
 	"sync"
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
 	"github.com/bloodhoundad/azurehound/pipeline"
@@ -62,23 +62,18 @@ func listVirtualMachinesCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
-func listVirtualMachines(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}) <-chan interface{} {
+func listVirtualMachines(ctx context.Context, client client.AzureClient, subscriptions <-chan AzureWrapper[models.Subscription]) <-chan AzureWrapper[models.VirtualMachine] {
 	var (
-		out     = make(chan interface{})
+		out     = make(chan AzureWrapper[models.VirtualMachine])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, config.AzWorkers.Value().(int))
 		wg      sync.WaitGroup
 	)
 
 	go func() {
 		defer close(ids)
-		for result := range pipeline.OrDone(ctx.Done(), subscriptions) {
-			if subscription, ok := result.(AzureWrapper).Data.(models.Subscription); !ok {
-				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating virtual machines", "result", result)
-				return
-			} else {
-				ids <- subscription.SubscriptionId
-			}
+		for subscription := range pipeline.OrDone(ctx.Done(), subscriptions) {
+			ids <- subscription.Data.SubscriptionId
 		}
 	}()
 
@@ -89,9 +84,9 @@ func listVirtualMachines(ctx context.Context, client client.AzureClient, subscri
 			defer wg.Done()
 			for id := range stream {
 				count := 0
-				for item := range client.ListAzureVirtualMachines(ctx, id.(string), false) {
+				for item := range client.ListAzureVirtualMachines(ctx, id, false) {
 					if item.Error != nil {
-						log.Error(item.Error, "unable to continue processing virtual machines for this subscription", "subscriptionId", id)
+						logChildError(item.Error, "unable to continue processing virtual machines for this subscription", "subscriptionId", id)
 					} else {
 						resourceGroupId := item.Ok.ResourceGroupId()
 						virtualMachine := models.VirtualMachine{
@@ -102,8 +97,9 @@ func listVirtualMachines(ctx context.Context, client client.AzureClient, subscri
 						}
 						log.V(2).Info("found virtual machine", "virtualMachine", virtualMachine)
 						count++
-						out <- AzureWrapper{
+						out <- AzureWrapper[models.VirtualMachine]{
 							Kind: enums.KindAZVM,
+							id:   item.Ok.Id,
 							Data: virtualMachine,
 						}
 					}
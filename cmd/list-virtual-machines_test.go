@@ -39,7 +39,7 @@ func TestListVirtualMachines(t *testing.T) {
 
 	mockClient := mocks.NewMockAzureClient(ctrl)
 
-	mockSubscriptionsChannel := make(chan interface{})
+	mockSubscriptionsChannel := make(chan AzureWrapper[models.Subscription])
 	mockVirtualMachineChannel := make(chan azure.VirtualMachineResult)
 	mockVirtualMachineChannel2 := make(chan azure.VirtualMachineResult)
 
@@ -52,10 +52,10 @@ func TestListVirtualMachines(t *testing.T) {
 
 	go func() {
 		defer close(mockSubscriptionsChannel)
-		mockSubscriptionsChannel <- AzureWrapper{
+		mockSubscriptionsChannel <- AzureWrapper[models.Subscription]{
 			Data: models.Subscription{},
 		}
-		mockSubscriptionsChannel <- AzureWrapper{
+		mockSubscriptionsChannel <- AzureWrapper[models.Subscription]{
 			Data: models.Subscription{},
 		}
 	}()
@@ -78,28 +78,16 @@ func TestListVirtualMachines(t *testing.T) {
 		}
 	}()
 
-	if result, ok := <-channel; !ok {
+	if _, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if _, ok := wrapper.Data.(models.VirtualMachine); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.VirtualMachine{})
 	}
 
-	if result, ok := <-channel; !ok {
+	if _, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if _, ok := wrapper.Data.(models.VirtualMachine); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.VirtualMachine{})
 	}
 
-	if result, ok := <-channel; !ok {
+	if _, ok := <-channel; !ok {
 		t.Fatalf("failed to receive from channel")
-	} else if wrapper, ok := result.(AzureWrapper); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
-	} else if _, ok := wrapper.Data.(models.VirtualMachine); !ok {
-		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.VirtualMachine{})
 	}
 
 	if _, ok := <-channel; ok {
@@ -0,0 +1,58 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client"
+	client_config "github.com/bloodhoundad/azurehound/client/config"
+	"github.com/bloodhoundad/azurehound/constants"
+)
+
+// newReplayClient returns a client that replays the responses recorded to the cassette with --record
+func newReplayClient(t *testing.T, cassette string) client.AzureClient {
+	azClient, err := client.NewClient(client_config.Config{
+		Region: constants.Cloud,
+		Replay: cassette,
+	})
+	if err != nil {
+		t.Fatalf("unable to replay %s: %v", cassette, err)
+	}
+	return azClient
+}
+
+func TestListUsersReplay(t *testing.T) {
+	ctx := context.Background()
+	azClient := newReplayClient(t, "testdata/list-users.cassette")
+
+	var names []string
+	for item := range listUsers(ctx, azClient) {
+		if user := item.Data; user.TenantId != "6c12b0b0-b2cc-4a73-8252-0b94bfca2145" {
+			t.Errorf("got tenant %v, want the tenant from the organization response", user.TenantId)
+		} else {
+			names = append(names, user.DisplayName)
+		}
+	}
+
+	// the users are listed across two pages
+	if len(names) != 3 {
+		t.Errorf("got %v users, want %v", names, 3)
+	}
+}
@@ -33,7 +33,6 @@ import (
 	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/constants"
 	"github.com/bloodhoundad/azurehound/models"
-	"github.com/bloodhoundad/azurehound/pipeline"
 	"github.com/spf13/cobra"
 )
 
@@ -76,25 +75,50 @@ func start(ctx context.Context) {
 		exit(err)
 	} else if azClient, err := newAzureClient(); err != nil {
 		exit(err)
+	} else if _, err := configuredKinds(); err != nil {
+		exit(err)
 	} else if bheInstance, err := url.Parse(config.BHEUrl.Value().(string)); err != nil {
 		exit(err)
 	} else if bheClient, err := newSigningHttpClient(BHEAuthSignature, config.BHETokenId.Value().(string), config.BHEToken.Value().(string), config.Proxy.Value().(string)); err != nil {
 		exit(err)
+	} else if bheSpool, err := openSpool(); err != nil {
+		exit(err)
 	} else {
+		defer bheSpool.Close()
 
 		if err := updateClient(ctx, *bheInstance, bheClient); err != nil {
 			exit(err)
 		}
 
+		// Upload spooled data in the background, including any left by a previous run
+		uploader := newUploader(*bheInstance, bheClient, rest.DefaultRetryPolicy(), config.BHEUploadWorkers.Value().(int), bheSpool)
+		if pending := bheSpool.Pending(); pending > 0 {
+			log.Info("resuming upload of spooled batches", "batches", pending)
+		}
+		go uploader.run(ctx)
+
 		log.Info("connected successfully! waiting for tasks...")
 		ticker := time.NewTicker(5 * time.Second)
 		defer ticker.Stop()
 
-		var currentTask *models.ClientTask
+		var (
+			currentTask *models.ClientTask
+			uploaded    ingestResult
+		)
 
 		for {
 			select {
 			case <-ticker.C:
+				// Uploads are reported once the spool has been drained, which may be long after the task ended
+				uploaded.add(uploader.take())
+				if bheSpool.Pending() == 0 && uploaded != (ingestResult{}) {
+					log.Info("uploaded spooled data",
+						"acceptedBatches", uploaded.AcceptedBatches, "acceptedObjects", uploaded.AcceptedObjects,
+						"failedBatches", uploaded.FailedBatches, "failedObjects", uploaded.FailedObjects,
+						"uploadedBytes", uploaded.Bytes, "uploadThroughput", uploaded.throughput())
+					uploaded = ingestResult{}
+				}
+
 				if currentTask != nil {
 					log.V(1).Info("curently performing collection; continuing...")
 				} else {
@@ -126,15 +150,23 @@ func start(ctx context.Context) {
 							startTask(ctx, *bheInstance, bheClient, currentTask.Id)
 							start := time.Now()
 
-							// Batch data out for ingestion
-							stream := listAll(ctx, azClient)
-							batches := pipeline.Batch(ctx.Done(), stream, 999, 10*time.Second)
-							ingest(ctx, *bheInstance, bheClient, batches)
+							if kinds, err := taskKinds(*currentTask); err != nil {
+								log.Error(err, "unable to select the kinds to collect for task", "id", currentTask.Id)
+							} else {
+								// Batch data out for ingestion; the uploader delivers the spooled batches in the background so that
+								// the task ends even while BloodHound Enterprise is unavailable
+								stream := listAll(ctx, azClient, kinds)
+								batches := batchForIngest(ctx, stream)
+								if err := spoolBatches(ctx, bheSpool, batches); err != nil && ctx.Err() == nil {
+									log.Error(err, "unable to spool collected data", "id", currentTask.Id)
+								}
+							}
 
 							// Notify BHE instance of task end
 							duration := time.Since(start)
 							endTask(ctx, *bheInstance, bheClient)
-							log.Info("finished collection task", "id", currentTask.Id, "duration", duration.String())
+							log.Info("finished collection task", "id", currentTask.Id, "duration", duration.String(),
+								"pendingBatches", bheSpool.Pending())
 
 							currentTask = nil
 						}
@@ -147,25 +179,6 @@ func start(ctx context.Context) {
 	}
 }
 
-func ingest(ctx context.Context, bheUrl url.URL, bheClient *http.Client, in <-chan []interface{}) {
-	endpoint := bheUrl.ResolveReference(&url.URL{Path: "/api/v1/ingest"})
-
-	for data := range pipeline.OrDone(ctx.Done(), in) {
-		body := models.IngestRequest{
-			Meta: models.Meta{
-				Type: "azure",
-			},
-			Data: data,
-		}
-
-		if req, err := rest.NewRequest(ctx, "POST", endpoint, body, nil, nil); err != nil {
-			log.Error(err, "unable to create request")
-		} else if _, err := bheClient.Do(req); err != nil {
-			log.Error(err, "unable to send data to bloodhound enterprise", "bheUrl", bheUrl)
-		}
-	}
-}
-
 func getAvailableTasks(ctx context.Context, bheUrl url.URL, bheClient *http.Client) ([]models.ClientTask, error) {
 	var (
 		endpoint = bheUrl.ResolveReference(&url.URL{Path: "/api/v1/clients/availabletasks"})
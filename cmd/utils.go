@@ -23,7 +23,9 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"io/ioutil"
 	"net"
@@ -61,7 +63,9 @@ func persistentPreRunE(cmd *cobra.Command, args []string) error {
 	config.LoadValues(cmd, config.Options())
 	config.SetAzureDefaults()
 
-	if logr, err := logger.GetLogger(); err != nil {
+	if workers := config.AzWorkers.Value().(int); workers < 1 {
+		return fmt.Errorf("invalid --%s %d; each collection stage needs at least 1 worker", config.AzWorkers.Name, workers)
+	} else if logr, err := logger.GetLogger(); err != nil {
 		return err
 	} else {
 		log = *logr
@@ -85,12 +89,22 @@ func gracefulShutdown(stop context.CancelFunc) {
 }
 
 func testConnections() error {
-	if _, err := dial(config.AzAuthUrl.Value().(string)); err != nil {
-		return fmt.Errorf("unable to connect to %s: %w", config.AzAuthUrl.Value(), err)
-	} else if _, err := dial(config.AzGraphUrl.Value().(string)); err != nil {
-		return fmt.Errorf("unable to connect to %s: %w", config.AzGraphUrl.Value(), err)
-	} else if _, err := dial(config.AzMgmtUrl.Value().(string)); err != nil {
-		return fmt.Errorf("unable to connect to %s: %w", config.AzMgmtUrl.Value(), err)
+	// set from the region unless explicitly configured
+	var (
+		authUrl = config.AzAuthUrl.Value().(string)
+		graph   = config.AzGraphUrl.Value().(string)
+		mgmt    = config.AzMgmtUrl.Value().(string)
+	)
+
+	if config.AzReplay.Value().(string) != "" {
+		// recorded responses are replayed without connecting to Azure
+		return nil
+	} else if _, err := dial(authUrl); err != nil {
+		return fmt.Errorf("unable to connect to %s: %w", authUrl, err)
+	} else if _, err := dial(graph); err != nil {
+		return fmt.Errorf("unable to connect to %s: %w", graph, err)
+	} else if _, err := dial(mgmt); err != nil {
+		return fmt.Errorf("unable to connect to %s: %w", mgmt, err)
 	} else {
 		return nil
 	}
@@ -121,17 +135,50 @@ func newAzureClient() (client.AzureClient, error) {
 	var (
 		certFile   = config.AzCert.Value()
 		keyFile    = config.AzKey.Value()
+		keyPass    = config.AzKeyPass.Value().(string)
 		clientCert string
 		clientKey  string
+		tokenCache = config.AzTokenCache.Value().(string)
+		cacheKey   = config.AzTokenCacheKey.Value().(string)
+		appId      = config.AzAppId.Value().(string)
+		tokenFile  = config.AzFederatedTokenFile.Value().(string)
+
+		refreshToken         = config.RefreshToken.Value().(string)
+		refreshTokenClientId string
 	)
 
+	if msalCache := config.AzMsalCache.Value().(string); msalCache != "" && refreshToken == "" {
+		if clientId, token, err := readMSALRefreshToken(msalCache); err != nil {
+			return nil, err
+		} else {
+			refreshToken, refreshTokenClientId = token, clientId
+		}
+	}
+
+	if tokenFile == "" && refreshToken == "" && !hasCredential() {
+		// set by the Azure Workload Identity webhook for Kubernetes, and only used when no other credential is configured
+		if tokenFile = os.Getenv("AZURE_FEDERATED_TOKEN_FILE"); tokenFile != "" && appId == "" {
+			appId = os.Getenv("AZURE_CLIENT_ID")
+		}
+	}
+
+	if tokenCache != "" && cacheKey == "" {
+		return nil, fmt.Errorf("--%s requires --%s, from which the key that encrypts the cache is derived", config.AzTokenCache.Name, config.AzTokenCacheKey.Name)
+	}
+
 	if file, ok := certFile.(string); ok && file != "" {
 		if content, err := ioutil.ReadFile(certFile.(string)); err != nil {
 			return nil, fmt.Errorf("unable to read provided certificate: %w", err)
-		} else {
+		} else if !rest.IsPKCS12(content) {
 			clientCert = string(content)
+		} else if file, ok := keyFile.(string); ok && file != "" {
+			return nil, fmt.Errorf("a key file may not be provided with a PKCS#12 certificate, which contains its own key")
+		} else if cert, key, err := rest.ReadPFX(content, keyPass); err != nil {
+			return nil, fmt.Errorf("unable to read provided certificate: %w", err)
+		} else {
+			// the key is decrypted with the PFX password
+			clientCert, clientKey, keyPass = cert, key, ""
 		}
-
 	}
 
 	if file, ok := keyFile.(string); ok && file != "" {
@@ -143,29 +190,74 @@ func newAzureClient() (client.AzureClient, error) {
 	}
 
 	config := client_config.Config{
-		ApplicationId:  config.AzAppId.Value().(string),
-		Authority:      config.AzAuthUrl.Value().(string),
-		ClientSecret:   config.AzSecret.Value().(string),
-		ClientCert:     clientCert,
-		ClientKey:      clientKey,
-		ClientKeyPass:  config.AzKeyPass.Value().(string),
-		Graph:          config.AzGraphUrl.Value().(string),
-		JWT:            config.JWT.Value().(string),
-		Management:     config.AzMgmtUrl.Value().(string),
-		MgmtGroupId:    config.AzMgmtGroupId.Value().([]string),
-		Password:       config.AzPassword.Value().(string),
-		ProxyUrl:       config.Proxy.Value().(string),
-		RefreshToken:   config.RefreshToken.Value().(string),
-		Region:         config.AzRegion.Value().(string),
-		SubscriptionId: config.AzSubId.Value().([]string),
-		Tenant:         config.AzTenant.Value().(string),
-		Username:       config.AzUsername.Value().(string),
+		ApplicationId:           appId,
+		Authority:               config.AzAuthUrl.Value().(string),
+		AuthRateLimit:           config.AzAuthRateLimit.Value().(int),
+		CACerts:                 config.CACert.Value().([]string),
+		ClientSecret:            config.AzSecret.Value().(string),
+		ClientCert:              clientCert,
+		ClientKey:               clientKey,
+		ClientKeyPass:           keyPass,
+		DeviceCode:              config.AzDeviceCode.Value().(bool),
+		FederatedTokenFile:      tokenFile,
+		Graph:                   config.AzGraphUrl.Value().(string),
+		GraphBatch:              config.AzGraphBatch.Value().(bool),
+		GraphRateLimit:          config.AzGraphRateLimit.Value().(int),
+		JWT:                     config.JWT.Value().([]string),
+		Management:              config.AzMgmtUrl.Value().(string),
+		ManagedIdentity:         config.AzManagedIdentity.Value().(bool),
+		ManagedIdentityEndpoint: config.AzManagedIdentityEndpoint.Value().(string),
+		MaxRetries:              config.AzMaxRetries.Value().(int),
+		MgmtGroupId:             config.AzMgmtGroupId.Value().([]string),
+		MgmtRateLimit:           config.AzMgmtRateLimit.Value().(int),
+		Password:                config.AzPassword.Value().(string),
+		ProxyUrl:                config.Proxy.Value().(string),
+		Record:                  config.AzRecord.Value().(string),
+		RefreshToken:            refreshToken,
+		RefreshTokenClientId:    refreshTokenClientId,
+		Region:                  config.AzRegion.Value().(string),
+		Replay:                  config.AzReplay.Value().(string),
+		SendCertChain:           config.AzSendCertChain.Value().(bool),
+		SubscriptionId:          config.AzSubId.Value().([]string),
+		Tenant:                  config.AzTenant.Value().(string),
+		TLSCert:                 config.TLSCert.Value().(string),
+		TLSKey:                  config.TLSKey.Value().(string),
+		TokenCache:              tokenCache,
+		TokenCacheKey:           cacheKey,
+		Username:                config.AzUsername.Value().(string),
 	}
 	return client.NewClient(config)
 }
 
+// hasCredential returns true if a secret, certificate, username and password, JWT, device code sign in or managed
+// identity is configured
+func hasCredential() bool {
+	cert, _ := config.AzCert.Value().(string)
+	return config.AzSecret.Value().(string) != "" ||
+		cert != "" ||
+		config.AzUsername.Value().(string) != "" ||
+		config.AzPassword.Value().(string) != "" ||
+		len(config.JWT.Value().([]string)) > 0 ||
+		config.AzDeviceCode.Value().(bool) ||
+		config.AzManagedIdentity.Value().(bool)
+}
+
+// readMSALRefreshToken returns the client id and refresh token of the selected account from the MSAL token cache
+func readMSALRefreshToken(path string) (string, string, error) {
+	if authUrl, err := url.Parse(config.AzAuthUrl.Value().(string)); err != nil {
+		return "", "", err
+	} else if cache, err := rest.ReadMSALCache(path); err != nil {
+		return "", "", err
+	} else if account, err := cache.SelectAccount(authUrl.Host, config.AzTenant.Value().(string), config.AzMsalAccount.Value().(string)); err != nil {
+		return "", "", err
+	} else {
+		log.V(1).Info("using refresh token from MSAL token cache", "account", account.Username, "tenant", account.Realm)
+		return cache.GetRefreshToken(account)
+	}
+}
+
 func newSigningHttpClient(signature, tokenId, token, proxyUrl string) (*http.Client, error) {
-	if client, err := rest.NewHTTPClient(proxyUrl); err != nil {
+	if client, err := rest.NewHTTPClient(proxyUrl, config.CACert.Value().([]string), config.TLSCert.Value().(string), config.TLSKey.Value().(string)); err != nil {
 		return nil, err
 	} else {
 		client.Transport = signingTransport{
@@ -203,7 +295,7 @@ func (s signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		return nil, err
 	}
 
-	// body
+	// body, as sent; an encoded body, e.g. one with a gzip Content-Encoding, is signed in its encoded form
 	body := &bytes.Buffer{}
 	digester = hmac.New(sha256.New, digester.Sum(nil))
 	if req.Body != nil {
@@ -213,6 +305,10 @@ func (s signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		} else if contentLength != 0 {
 			req.Body = ioutil.NopCloser(bytes.NewReader(body.Bytes()))
 			clone.Body = ioutil.NopCloser(bytes.NewReader(body.Bytes()))
+			clone.ContentLength = contentLength
+			clone.GetBody = func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(bytes.NewReader(body.Bytes())), nil
+			}
 		}
 	}
 	if _, err := digester.Write(body.Bytes()); err != nil {
@@ -280,18 +376,73 @@ func setupLogger() {
 	}
 }
 
-type AzureWrapper struct {
-	Kind enums.Kind  `json:"kind"`
-	Data interface{} `json:"data"`
+// logChildError logs an error encountered while enumerating the children of an object, e.g. a group's members.
+// Objects may be deleted between being listed and having their children enumerated so Azure's not found errors are
+// expected in large tenants and are only logged at the debug level.
+func logChildError(err error, msg string, keysAndValues ...interface{}) {
+	if rest.IsNotFound(err) {
+		log.V(1).Info(msg, append(keysAndValues, "error", err.Error())...)
+	} else {
+		log.Error(err, msg, keysAndValues...)
+	}
+}
+
+// AzureWrapper is the envelope collected objects are output in. Collectors stream wrappers of the type they collect so
+// that those enumerating an object's children receive it without a type assertion.
+type AzureWrapper[T any] struct {
+	Kind enums.Kind `json:"kind"`
+	Data T          `json:"data"`
+
+	// id identifies the object among those of its kind, or the parent whose children it lists, to the checkpoint store
+	id string
+}
+
+// untyped converts a stream of collected objects to the stream of objects of any type that is output
+func untyped[T any](ctx context.Context, stream <-chan AzureWrapper[T]) <-chan AzureWrapper[interface{}] {
+	return pipeline.Map(ctx.Done(), stream, func(item AzureWrapper[T]) AzureWrapper[interface{}] {
+		return AzureWrapper[interface{}]{Kind: item.Kind, Data: item.Data, id: item.id}
+	})
+}
+
+// isDirectoryPath returns true if the path is an existing directory or may be created as one
+func isDirectoryPath(path string) bool {
+	if path == "" || path == sinks.Stdout {
+		return false
+	} else if info, err := os.Stat(path); err == nil {
+		return info.IsDir()
+	} else {
+		return errors.Is(err, fs.ErrNotExist)
+	}
 }
 
-func outputStream(ctx context.Context, stream <-chan interface{}) {
-	formatted := pipeline.FormatJson(ctx.Done(), stream)
-	if path := config.OutputFile.Value().(string); path != "" {
-		if err := sinks.WriteToFile(ctx, path, formatted); err != nil {
+func outputStream[T any](ctx context.Context, stream <-chan AzureWrapper[T]) {
+	var (
+		path   = config.OutputFile.Value().(string)
+		format = config.OutputFormat.Value().(string)
+		route  = func(item AzureWrapper[T]) string { return string(item.Kind) }
+	)
+
+	if path != "" && config.ZipOutput.Value().(bool) {
+		if archive, err := sinks.WriteToZip(ctx, path, format, stream, route); err != nil {
+			exit(err)
+		} else {
+			log.Info("packaged output", "archive", archive)
+		}
+	} else if path != "" && config.SplitOutput.Value().(bool) {
+		if err := sinks.WriteToDirectory(ctx, path, format, stream, route); err != nil {
+			exit(err)
+		}
+	} else if path != "" {
+		defer closeCheckpoints(ctx)
+
+		// objects completed by the run being resumed are already in the output
+		remaining := pipeline.Filter(ctx.Done(), stream, func(item AzureWrapper[T]) bool {
+			return !checkpoints.Done(string(item.Kind), item.id)
+		})
+		if err := sinks.WriteToFile(ctx, path, format, config.Resume.Value().(bool), remaining, checkpointWritten[T]); err != nil {
 			exit(err)
 		}
 	} else {
-		sinks.WriteToConsole(ctx, formatted)
+		sinks.WriteToConsole(ctx, pipeline.FormatJson(ctx.Done(), stream))
 	}
 }
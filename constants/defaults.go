@@ -0,0 +1,36 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package constants
+
+// Defaults shared by the command line options and the clients they configure
+const (
+	// The maximum number of times a throttled or transiently failed request is retried
+	DefaultMaxRetries int = 5
+
+	// Microsoft Graph allows thousands of requests per 10 seconds per app and tenant depending on the tenant's size
+	DefaultGraphRateLimit int = 150
+
+	// Azure Resource Manager refills read tokens at 25 per second per principal and region
+	DefaultMgmtRateLimit int = 25
+
+	// The Microsoft identity platform is only asked for tokens, which are cached until they expire
+	DefaultAuthRateLimit int = 10
+
+	// The number of concurrent requests each collection stage sends
+	DefaultWorkers int = 25
+)
@@ -21,6 +21,9 @@ type AuthMethod = string
 
 const (
 	Certificate      string = "Certificate"
+	DeviceCode       string = "Device Code"
+	ManagedIdentity  string = "Managed Identity"
+	MSALTokenCache   string = "MSAL Token Cache"
 	Secret           string = "Client Secret"
 	UsernamePassword string = "Username and Password"
 )
@@ -28,6 +31,9 @@ const (
 func AuthMethods() []AuthMethod {
 	return []AuthMethod{
 		Certificate,
+		DeviceCode,
+		ManagedIdentity,
+		MSALTokenCache,
 		Secret,
 		UsernamePassword,
 	}
@@ -58,3 +58,45 @@ const (
 	KindAZVMUserAccessAdmin              Kind = "AZVMUserAccessAdmin"
 	KindAZVMVMContributor                Kind = "AZVMVMContributor"
 )
+
+// Kinds returns the kinds that a collection produces and that may therefore be selected for collection. AZAppMember is
+// never collected and AZVMRoleAssignment is only listed on the way to the VM role kinds, so neither is included.
+func Kinds() []Kind {
+	return []Kind{
+		KindAZApp,
+		KindAZAppOwner,
+		KindAZDevice,
+		KindAZDeviceOwner,
+		KindAZGroup,
+		KindAZGroupMember,
+		KindAZGroupOwner,
+		KindAZKeyVault,
+		KindAZKeyVaultAccessPolicy,
+		KindAZKeyVaultContributor,
+		KindAZKeyVaultOwner,
+		KindAZKeyVaultUserAccessAdmin,
+		KindAZManagementGroup,
+		KindAZManagementGroupOwner,
+		KindAZManagementGroupDescendant,
+		KindAZManagementGroupUserAccessAdmin,
+		KindAZResourceGroup,
+		KindAZResourceGroupOwner,
+		KindAZResourceGroupUserAccessAdmin,
+		KindAZRole,
+		KindAZRoleAssignment,
+		KindAZServicePrincipal,
+		KindAZServicePrincipalOwner,
+		KindAZSubscription,
+		KindAZSubscriptionOwner,
+		KindAZSubscriptionUserAccessAdmin,
+		KindAZTenant,
+		KindAZUser,
+		KindAZVM,
+		KindAZVMAdminLogin,
+		KindAZVMAvereContributor,
+		KindAZVMContributor,
+		KindAZVMOwner,
+		KindAZVMUserAccessAdmin,
+		KindAZVMVMContributor,
+	}
+}
@@ -181,10 +181,12 @@ type ApplicationList struct {
 type ApplicationResult struct {
 	Error error
 	Ok    Application
+	Page  int
 }
 
 type AppOwnerResult struct {
 	AppId string
 	Error error
 	Ok    json.RawMessage
+	Page  int
 }
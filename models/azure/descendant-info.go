@@ -72,4 +72,5 @@ type DescendantInfoList struct {
 type DescendantInfoResult struct {
 	Error error
 	Ok    DescendantInfo
+	Page  int
 }
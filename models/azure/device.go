@@ -141,10 +141,12 @@ type DeviceList struct {
 type DeviceResult struct {
 	Error error
 	Ok    Device
+	Page  int
 }
 
 type DeviceRegisteredOwnerResult struct {
 	DeviceId string
 	Error    error
 	Ok       json.RawMessage
+	Page     int
 }
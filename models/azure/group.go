@@ -291,10 +291,12 @@ type GroupList struct {
 type GroupResult struct {
 	Error error
 	Ok    Group
+	Page  int
 }
 
 type GroupOwnerResult struct {
 	Error   error
 	GroupId string
 	Ok      json.RawMessage
+	Page    int
 }
@@ -66,4 +66,5 @@ type KeyVaultResult struct {
 	SubscriptionId string
 	Error          error
 	Ok             KeyVault
+	Page           int
 }
@@ -38,4 +38,5 @@ type ManagementGroupList struct {
 type ManagementGroupResult struct {
 	Error error
 	Ok    ManagementGroup
+	Page  int
 }
@@ -31,4 +31,5 @@ type MemberObjectResult struct {
 	ParentType string
 	Error      error
 	Ok         json.RawMessage
+	Page       int
 }
@@ -49,4 +49,5 @@ type ResourceGroupResult struct {
 	SubscriptionId string
 	Error          error
 	Ok             ResourceGroup
+	Page           int
 }
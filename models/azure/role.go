@@ -77,4 +77,5 @@ type RoleList struct {
 type RoleResult struct {
 	Error error
 	Ok    Role
+	Page  int
 }
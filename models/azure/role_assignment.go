@@ -54,6 +54,7 @@ type RoleAssignmentResult struct {
 	ParentId string
 	Error    error
 	Ok       RoleAssignment
+	Page     int
 }
 
 func (s RoleAssignment) GetPrincipalId() string {
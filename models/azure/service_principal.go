@@ -189,10 +189,12 @@ type ServicePrincipalList struct {
 type ServicePrincipalResult struct {
 	Error error
 	Ok    ServicePrincipal
+	Page  int
 }
 
 type ServicePrincipalOwnerResult struct {
 	Error              error
 	ServicePrincipalId string
 	Ok                 json.RawMessage
+	Page               int
 }
@@ -58,4 +58,5 @@ type SubscriptionList struct {
 type SubscriptionResult struct {
 	Error error
 	Ok    Subscription
+	Page  int
 }
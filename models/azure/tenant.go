@@ -40,4 +40,5 @@ type TenantList struct {
 type TenantResult struct {
 	Error error
 	Ok    Tenant
+	Page  int
 }
@@ -84,4 +84,5 @@ type UnifiedRoleAssignmentList struct {
 type UnifiedRoleAssignmentResult struct {
 	Error error
 	Ok    UnifiedRoleAssignment
+	Page  int
 }
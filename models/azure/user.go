@@ -475,4 +475,5 @@ type UserList struct {
 type UserResult struct {
 	Error error
 	Ok    User
+	Page  int
 }
@@ -61,4 +61,5 @@ type VirtualMachineResult struct {
 	SubscriptionId string
 	Error          error
 	Ok             VirtualMachine
+	Page           int
 }
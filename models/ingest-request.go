@@ -26,4 +26,5 @@ type Meta struct {
 	Type    string `json:"type"`
 	Version int    `json:"version"`
 	Count   int    `json:"count"`
+	Partial bool   `json:"partial,omitempty"` // the collection was interrupted before it completed
 }
@@ -29,6 +29,8 @@ type ClientTask struct {
 	EventTitle            string    `json:"event_title"`
 	ExectionTime          time.Time `json:"exection_time"`
 	Id                    int       `json:"id"`
+	IncludeKinds          []string  `json:"include_kinds"`
+	ExcludeKinds          []string  `json:"exclude_kinds"`
 	LocalGroupCollection  bool      `json:"local_group_collection"`
 	LogPath               string    `json:"log_path"`
 	SessionCollection     bool      `json:"session_collection"`
@@ -19,8 +19,6 @@ package pipeline
 
 import (
 	"encoding/json"
-	"fmt"
-	"reflect"
 	"sync"
 	"time"
 )
@@ -33,62 +31,39 @@ type Result struct {
 // OrDone provides an explicit cancellation mechanism to ensure the encapsulated and downstream goroutines are cleaned
 // up. This frees the caller from depending on the input channel to close in order to free the goroutine, thus
 // preventing possible leaks.
-func OrDone(done, in interface{}) <-chan interface{} {
-	if !isReadable(done) || !isReadable(in) {
-		panic(fmt.Errorf("channels must be readable"))
-	}
-	out := make(chan interface{})
+func OrDone[D, T any](done <-chan D, in <-chan T) <-chan T {
+	out := make(chan T)
 
 	go func() {
 		defer close(out)
-		doneCase := reflect.SelectCase{
-			Dir:  reflect.SelectRecv,
-			Chan: reflect.ValueOf(done),
-		}
-
-		outerCases := []reflect.SelectCase{
-			doneCase,
-			{
-				Dir:  reflect.SelectRecv,
-				Chan: reflect.ValueOf(in),
-			},
-		}
-
-		innerCases := []reflect.SelectCase{
-			doneCase,
-			{
-				Dir:  reflect.SelectSend,
-				Chan: reflect.ValueOf(out),
-			},
-		}
 		for {
-			if chosen, item, ok := reflect.Select(outerCases); chosen == 0 || !ok {
-				// If received on done then return
+			select {
+			case <-done:
 				return
-			} else {
+			case item, ok := <-in:
 				if !ok {
 					return
-				} else {
-					innerCases[1].Send = item
-					if chosen, _, _ := reflect.Select(innerCases); chosen == 0 || !ok {
-						return
-					}
+				} else if !send(done, out, item) {
+					return
 				}
 			}
 		}
 	}()
+
 	return out
 }
 
 // Mux joins multiple channels and returns a channel as single stream of data.
-func Mux(done interface{}, channels ...interface{}) <-chan interface{} {
+func Mux[D, T any](done <-chan D, channels ...<-chan T) <-chan T {
 	var wg sync.WaitGroup
-	out := make(chan interface{})
+	out := make(chan T)
 
-	muxer := func(channel interface{}) {
+	muxer := func(channel <-chan T) {
 		defer wg.Done()
 		for item := range OrDone(done, channel) {
-			out <- item
+			if !send(done, out, item) {
+				return
+			}
 		}
 	}
 
@@ -105,58 +80,23 @@ func Mux(done interface{}, channels ...interface{}) <-chan interface{} {
 	return out
 }
 
-// Demux distributes the stream of data from a single channel across multiple channels to parallelize CPU use and I/O
-func Demux(done interface{}, in interface{}, size int) []<-chan interface{} {
-	// use reflection to dynamically create select statement
-	outputs := make([]chan interface{}, size)
-	readChans := []<-chan interface{}{}
-	for i := range outputs {
-		out := make(chan interface{})
-		outputs[i] = out
-		readChans = append(readChans, out)
-	}
+// Demux distributes the stream of data from a single channel across multiple channels to parallelize CPU use and I/O.
+// Each item is received from exactly one of the channels, by whichever reader is ready first.
+func Demux[D, T any](done <-chan D, in <-chan T, size int) []<-chan T {
+	var (
+		shared  = OrDone(done, in)
+		outputs = make([]<-chan T, size)
+	)
 
-	closeOutputs := func() {
-		for i := range outputs {
-			close(outputs[i])
-		}
-	}
-
-	cases := make([]reflect.SelectCase, len(outputs))
-	for i := range cases {
-		cases[i].Dir = reflect.SelectSend
-		cases[i].Chan = reflect.ValueOf(outputs[i])
+	for i := range outputs {
+		outputs[i] = shared
 	}
-	cases = append(cases, reflect.SelectCase{
-		Dir:  reflect.SelectRecv,
-		Chan: reflect.ValueOf(done),
-	})
-
-	go func() {
-		defer closeOutputs()
-		for item := range OrDone(done, in) {
-			// send item to exactly once channel or cancel
-			for i := range cases {
-				if cases[i].Dir == reflect.SelectSend {
-					cases[i].Send = reflect.ValueOf(item)
-				}
-			}
-
-			reflect.Select(cases)
-		}
-	}()
-
-	return readChans
+	return outputs
 }
 
-// Tee copies the stream of data from a single channel to zero or more channels
-func Tee(done interface{}, in interface{}, outputs ...chan<- interface{}) {
-	// use reflection to dynamically create select block
-	cases := make([]reflect.SelectCase, len(outputs))
-	for i := range cases {
-		cases[i].Dir = reflect.SelectSend
-	}
-
+// Tee copies the stream of data from a single channel to zero or more channels. Each item is sent to every output
+// before the next is received, so the outputs must be read concurrently.
+func Tee[D, T any](done <-chan D, in <-chan T, outputs ...chan<- T) {
 	go func() {
 		// Need to close outputs when goroutine exits to ensure we avoid deadlock
 		defer func() {
@@ -166,71 +106,77 @@ func Tee(done interface{}, in interface{}, outputs ...chan<- interface{}) {
 		}()
 
 		for item := range OrDone(done, in) {
-			// setup all possible select cases
-			for i := range cases {
-				cases[i].Chan = reflect.ValueOf(outputs[i])
-				cases[i].Send = reflect.ValueOf(item)
-			}
-
-			// send item to each channel no more than once or cancel
-			for range cases {
-				chosen, _, _ := reflect.Select(cases)
-				cases[chosen].Chan = reflect.ValueOf(nil)
+			for _, output := range outputs {
+				if !send(done, output, item) {
+					return
+				}
 			}
 		}
 	}()
 }
 
-func Batch(done interface{}, in interface{}, maxItems int, maxTimeout time.Duration) <-chan []interface{} {
-	if !isReadable(done) || !isReadable(in) {
-		panic(fmt.Errorf("channels must be readable"))
-	}
-	out := make(chan []interface{})
+// Batch groups the stream of data into slices of up to maxItems, flushing a partial batch once maxTimeout has elapsed
+// since the last flush
+func Batch[D, T any](done <-chan D, in <-chan T, maxItems int, maxTimeout time.Duration) <-chan []T {
+	return BatchBySize(done, in, maxItems, 0, nil, maxTimeout)
+}
+
+// BatchBySize groups the stream of data into slices of up to maxItems whose sizes, as measured by size, total no more
+// than maxBytes, flushing a partial batch once maxTimeout has elapsed since the last flush. An item larger than maxBytes
+// is batched alone. If maxBytes is zero batches are limited by maxItems only.
+func BatchBySize[D, T any](done <-chan D, in <-chan T, maxItems int, maxBytes int, size func(T) int, maxTimeout time.Duration) <-chan []T {
+	out := make(chan []T)
 
 	go func() {
 		defer close(out)
 
-		doneCase := reflect.SelectCase{
-			Dir:  reflect.SelectRecv,
-			Chan: reflect.ValueOf(done),
-		}
-
-		itemCase := reflect.SelectCase{
-			Dir:  reflect.SelectRecv,
-			Chan: reflect.ValueOf(in),
-		}
-
-		timeoutCase := reflect.SelectCase{
-			Dir:  reflect.SelectRecv,
-			Chan: reflect.ValueOf(time.After(maxTimeout)),
+		var (
+			batch      []T
+			batchBytes int
+			timeout    = time.NewTimer(maxTimeout)
+		)
+		defer timeout.Stop()
+
+		flush := func() {
+			if len(batch) > 0 {
+				out <- batch
+				batch = nil
+				batchBytes = 0
+			}
 		}
 
-		var batch []interface{}
 		for {
-			if chosen, item, ok := reflect.Select([]reflect.SelectCase{doneCase, itemCase, timeoutCase}); chosen == 0 || !ok {
-				// Flush and return when canceled or closed
-				if len(batch) > 0 {
-					out <- batch
-					batch = nil
-				}
+			select {
+			case <-done:
+				// Flush and return when canceled
+				flush()
 				return
-			} else if chosen == 1 {
-				// Add to batch
-				batch = append(batch, item.Interface())
-
-				// Flush if limit is reached
-				if len(batch) >= maxItems {
-					out <- batch
-					batch = nil
-					timeoutCase.Chan = reflect.ValueOf(time.After(maxTimeout))
+			case item, ok := <-in:
+				if !ok {
+					// Flush and return when closed
+					flush()
+					return
 				}
-			} else {
-				// Timeout triggered, flush and reset
-				if len(batch) > 0 {
-					out <- batch
-					batch = nil
+
+				// Flush first if the item would take the batch over its size limit
+				itemBytes := 0
+				if maxBytes > 0 {
+					if itemBytes = size(item); batchBytes+itemBytes > maxBytes && len(batch) > 0 {
+						flush()
+						resetTimer(timeout, maxTimeout)
+					}
 				}
-				timeoutCase.Chan = reflect.ValueOf(time.After(maxTimeout))
+
+				// Add to batch and flush if limit is reached
+				batchBytes += itemBytes
+				if batch = append(batch, item); len(batch) >= maxItems || maxBytes > 0 && batchBytes >= maxBytes {
+					flush()
+					resetTimer(timeout, maxTimeout)
+				}
+			case <-timeout.C:
+				// Timeout triggered, flush and reset
+				flush()
+				timeout.Reset(maxTimeout)
 			}
 		}
 	}()
@@ -238,17 +184,31 @@ func Batch(done interface{}, in interface{}, maxItems int, maxTimeout time.Durat
 	return out
 }
 
-func FormatJson(done interface{}, in interface{}) <-chan interface{} {
-	out := make(chan interface{})
+// Map applies fn to each item of the stream
+func Map[D, T, U any](done <-chan D, in <-chan T, fn func(T) U) <-chan U {
+	out := make(chan U)
 
 	go func() {
 		defer close(out)
+		for item := range OrDone(done, in) {
+			if !send(done, out, fn(item)) {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Filter passes on the items of the stream for which fn returns true
+func Filter[D, T any](done <-chan D, in <-chan T, fn func(T) bool) <-chan T {
+	out := make(chan T)
 
+	go func() {
+		defer close(out)
 		for item := range OrDone(done, in) {
-			if bytes, err := json.Marshal(item); err != nil {
-				panic(err)
-			} else {
-				out <- string(bytes)
+			if fn(item) && !send(done, out, item) {
+				return
 			}
 		}
 	}()
@@ -256,7 +216,33 @@ func FormatJson(done interface{}, in interface{}) <-chan interface{} {
 	return out
 }
 
-func isReadable(channel interface{}) bool {
-	channelType := reflect.TypeOf(channel)
-	return channelType.Kind() == reflect.Chan && channelType.ChanDir() != reflect.SendDir
+func FormatJson[D, T any](done <-chan D, in <-chan T) <-chan string {
+	return Map(done, in, func(item T) string {
+		if bytes, err := json.Marshal(item); err != nil {
+			panic(err)
+		} else {
+			return string(bytes)
+		}
+	})
+}
+
+// send sends the item unless done first, returning whether it was sent
+func send[D, T any](done <-chan D, out chan<- T, item T) bool {
+	select {
+	case out <- item:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+// resetTimer resets a timer that may have fired without being received from
+func resetTimer(timer *time.Timer, d time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(d)
 }
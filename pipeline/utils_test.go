@@ -68,6 +68,29 @@ func TestBatch(t *testing.T) {
 	}
 }
 
+func TestBatchBySize(t *testing.T) {
+
+	done := make(chan interface{})
+	in := make(chan string)
+
+	go func() {
+		for _, item := range []string{"foo", "bar", "bazz", "a-very-long-item", "a", "b", "c"} {
+			in <- item
+		}
+		close(in)
+	}()
+
+	var batches [][]string
+	for batch := range pipeline.BatchBySize(done, in, 3, 8, func(item string) int { return len(item) }, time.Second) {
+		batches = append(batches, batch)
+	}
+
+	want := [][]string{{"foo", "bar"}, {"bazz"}, {"a-very-long-item"}, {"a", "b", "c"}}
+	if fmt.Sprint(batches) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", batches, want)
+	}
+}
+
 func TestDemux(t *testing.T) {
 
 	var (
@@ -104,3 +127,75 @@ func TestDemux(t *testing.T) {
 	}
 
 }
+
+func TestTee(t *testing.T) {
+	var (
+		done = make(chan interface{})
+		in   = make(chan int)
+		a    = make(chan int)
+		b    = make(chan int)
+		wg   sync.WaitGroup
+		sums [2]int
+	)
+
+	go func() {
+		defer close(in)
+		for i := 1; i <= 4; i++ {
+			in <- i
+		}
+	}()
+
+	pipeline.Tee(done, in, a, b)
+	wg.Add(2)
+	for i, out := range []chan int{a, b} {
+		go func(i int, out <-chan int) {
+			defer wg.Done()
+			for item := range out {
+				sums[i] += item
+			}
+		}(i, out)
+	}
+
+	wg.Wait()
+	if sums[0] != 10 || sums[1] != 10 {
+		t.Errorf("got %v, want %v", sums, [2]int{10, 10})
+	}
+}
+
+func TestMapFilter(t *testing.T) {
+	var (
+		done = make(chan interface{})
+		in   = make(chan int)
+	)
+
+	go func() {
+		defer close(in)
+		for i := 1; i <= 4; i++ {
+			in <- i
+		}
+	}()
+
+	even := pipeline.Filter(done, in, func(i int) bool { return i%2 == 0 })
+	var got []string
+	for item := range pipeline.Map(done, even, func(i int) string { return fmt.Sprint(i) }) {
+		got = append(got, item)
+	}
+
+	if len(got) != 2 || got[0] != "2" || got[1] != "4" {
+		t.Errorf("got %v, want %v", got, []string{"2", "4"})
+	}
+}
+
+func TestOrDone(t *testing.T) {
+	var (
+		done = make(chan struct{})
+		in   = make(chan int)
+	)
+
+	out := pipeline.OrDone(done, in)
+	close(done)
+
+	if _, ok := <-out; ok {
+		t.Error("expected the channel to close when done")
+	}
+}
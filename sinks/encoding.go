@@ -0,0 +1,146 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sinks
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bloodhoundad/azurehound/models"
+)
+
+// encoding lays out the items written to an output
+type encoding interface {
+	// begin writes what precedes the first item
+	begin(w io.Writer) error
+
+	// item writes an item following count others
+	item(w io.Writer, count int, bytes []byte) error
+
+	// end writes what follows the last item
+	end(w io.Writer, meta models.Meta) error
+
+	// read returns the number of complete items in an output and the offset following the last of them
+	read(r *bufio.Reader) (int, int64, error)
+}
+
+const (
+	documentHeader    = "{\n\t\"data\": [\n"
+	documentSeparator = ",\n"
+	documentIndent    = "\t\t"
+)
+
+// document lays out items as the data of a document BloodHound can ingest, one item per line
+type document struct{}
+
+func (document) begin(w io.Writer) error {
+	_, err := io.WriteString(w, documentHeader)
+	return err
+}
+
+func (document) item(w io.Writer, count int, bytes []byte) error {
+	var entry []byte
+	if count > 0 {
+		entry = append(entry, documentSeparator...)
+	}
+	entry = append(entry, documentIndent...)
+	_, err := w.Write(append(entry, bytes...))
+	return err
+}
+
+func (document) end(w io.Writer, meta models.Meta) error {
+	if bytes, err := json.Marshal(meta); err != nil {
+		return err
+	} else {
+		_, err := io.WriteString(w, "\n\t],\n\t\"meta\": "+string(bytes)+"\n}\n")
+		return err
+	}
+}
+
+func (document) read(r *bufio.Reader) (int, int64, error) {
+	header := make([]byte, len(documentHeader))
+	if _, err := io.ReadFull(r, header); err != nil || string(header) != documentHeader {
+		return 0, 0, fmt.Errorf("file was not written by azurehound")
+	}
+
+	var (
+		count  = 0
+		offset = int64(len(documentHeader))
+		end    = offset
+	)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, 0, err
+		} else if !strings.HasPrefix(line, documentIndent) {
+			// reached the end of the data
+			return count, end, nil
+		} else if item := strings.TrimRight(line[len(documentIndent):], documentSeparator); !json.Valid([]byte(item)) {
+			// the item was partially written when the run was interrupted
+			return count, end, nil
+		} else {
+			count++
+			end = offset + int64(len(documentIndent)+len(item))
+			offset += int64(len(line))
+		}
+
+		if err != nil {
+			return count, end, nil
+		}
+	}
+}
+
+// lines lays out items as newline delimited JSON
+type lines struct{}
+
+func (lines) begin(w io.Writer) error {
+	return nil
+}
+
+func (lines) item(w io.Writer, count int, bytes []byte) error {
+	_, err := w.Write(append(bytes, '\n'))
+	return err
+}
+
+func (lines) end(w io.Writer, meta models.Meta) error {
+	return nil
+}
+
+func (lines) read(r *bufio.Reader) (int, int64, error) {
+	var (
+		count  = 0
+		offset int64
+	)
+	for {
+		if line, err := r.ReadBytes('\n'); errors.Is(err, io.EOF) {
+			// a line without its newline was partially written when the run was interrupted
+			return count, offset, nil
+		} else if err != nil {
+			return 0, 0, err
+		} else if !json.Valid(line) {
+			return 0, 0, fmt.Errorf("file was not written by azurehound")
+		} else {
+			count++
+			offset += int64(len(line))
+		}
+	}
+}
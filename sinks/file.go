@@ -18,47 +18,199 @@
 package sinks
 
 import (
+	"bufio"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 
+	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
 	"github.com/bloodhoundad/azurehound/pipeline"
+	"github.com/klauspost/compress/zstd"
 )
 
-func WriteToFile(ctx context.Context, filePath string, stream <-chan interface{}) error {
+// Stdout is the file path that writes to stdout
+const Stdout = "-"
 
-	if file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY, 0666); err != nil {
+// WriteToFile writes the stream to the file in the format. The output is written to a temporary file that replaces
+// the file once complete, so the file is only ever complete. If the context is cancelled the output is completed with
+// the items written so far and, in formats with a meta, marked as partial.
+//
+// If appending, the stream is added to the items in the file, or to those in the temporary file left by a run that
+// crashed. Only uncompressed files may be appended to. If written is not nil it is called with each item once the item
+// has been written.
+func WriteToFile[T any](ctx context.Context, filePath string, format enums.OutputFormat, appending bool, stream <-chan T, written func(T)) error {
+	if encoding, compressed, err := parseFormat(format); err != nil {
 		return err
+	} else if appending && !Appendable(filePath, format) {
+		return fmt.Errorf("unable to append %s output to %s", format, filePath)
+	} else if filePath == Stdout {
+		return write(ctx, os.Stdout, encoding, compressed, 0, stream, written)
 	} else {
-		defer file.Close()
-
-		if _, err := file.WriteString("{\n\t\"data\": [\n"); err != nil {
+		tempPath := filePath + ".tmp"
+		if file, count, err := openFile(tempPath, filePath, encoding, appending); err != nil {
+			return err
+		} else if err := write(ctx, file, encoding, compressed, count, stream, written); err != nil {
+			file.Close()
+			return err
+		} else if err := file.Sync(); err != nil {
+			file.Close()
+			return err
+		} else if err := file.Close(); err != nil {
 			return err
 		} else {
-			meta := models.Meta{
-				Type:    "azure",
-				Version: 5,
-				Count:   0,
-			}
+			return os.Rename(tempPath, filePath)
+		}
+	}
+}
 
-			format := "\t\t%v"
-			for item := range pipeline.OrDone(ctx.Done(), stream) {
-				if _, err := file.WriteString(fmt.Sprintf(format, item)); err != nil {
-					return err
-				}
-				meta.Count++
-				format = ",\n\t\t%v"
-			}
+// Appendable returns true if output in the format may be appended to the file
+func Appendable(filePath string, format enums.OutputFormat) bool {
+	_, compressed, err := parseFormat(format)
+	return err == nil && compressed == "" && filePath != Stdout
+}
 
-			if bytes, err := json.Marshal(meta); err != nil {
-				return err
-			} else if _, err := file.WriteString(fmt.Sprintf("\n\t],\n\t\"meta\": %s\n}\n", string(bytes))); err != nil {
+func parseFormat(format enums.OutputFormat) (encoding, string, error) {
+	switch format {
+	case enums.OutputFormatJson:
+		return document{}, "", nil
+	case enums.OutputFormatJsonGzip:
+		return document{}, "gzip", nil
+	case enums.OutputFormatJsonZstd:
+		return document{}, "zstd", nil
+	case enums.OutputFormatNdjson:
+		return lines{}, "", nil
+	case enums.OutputFormatNdjsonGzip:
+		return lines{}, "gzip", nil
+	case enums.OutputFormatNdjsonZstd:
+		return lines{}, "zstd", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// write encodes the stream to an output already containing count items
+func write[T any](ctx context.Context, out io.Writer, encoding encoding, compressed string, count int, stream <-chan T, written func(T)) error {
+	if writer, err := newItemWriter(out, encoding, compressed, count); err != nil {
+		return err
+	} else {
+		for item := range pipeline.OrDone(ctx.Done(), stream) {
+			if err := writer.write(item); err != nil {
 				return err
-			} else {
-				return nil
+			} else if written != nil {
+				written(item)
 			}
 		}
+		return writer.close(ctx.Err() != nil)
 	}
 }
+
+// itemWriter encodes items to an output one at a time
+type itemWriter struct {
+	writer   io.WriteCloser
+	encoding encoding
+	count    int
+}
+
+// newItemWriter returns a writer to an output already containing count items
+func newItemWriter(out io.Writer, encoding encoding, compressed string, count int) (*itemWriter, error) {
+	writer := &itemWriter{encoding: encoding, count: count}
+
+	switch compressed {
+	case "gzip":
+		writer.writer = gzip.NewWriter(out)
+	case "zstd":
+		if encoder, err := zstd.NewWriter(out); err != nil {
+			return nil, err
+		} else {
+			writer.writer = encoder
+		}
+	default:
+		writer.writer = nopCloser{out}
+	}
+
+	if count == 0 {
+		if err := encoding.begin(writer.writer); err != nil {
+			return nil, err
+		}
+	}
+	return writer, nil
+}
+
+func (s *itemWriter) write(item interface{}) error {
+	if bytes, err := json.Marshal(item); err != nil {
+		return err
+	} else if err := s.encoding.item(s.writer, s.count, bytes); err != nil {
+		return err
+	} else {
+		s.count++
+		return nil
+	}
+}
+
+// close completes the output, marking it partial if the items written are not all there are
+func (s *itemWriter) close(partial bool) error {
+	if err := s.encoding.end(s.writer, models.Meta{Type: "azure", Version: 5, Count: s.count, Partial: partial}); err != nil {
+		return err
+	} else {
+		return s.writer.Close()
+	}
+}
+
+// openFile opens the temporary file to write to, returning the number of items it already contains
+func openFile(tempPath, filePath string, encoding encoding, appending bool) (*os.File, int, error) {
+	if !appending {
+		file, err := os.OpenFile(tempPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+		return file, 0, err
+	} else if file, err := os.OpenFile(tempPath, os.O_RDWR, 0666); err == nil {
+		return resumeFile(file, encoding)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, err
+	} else if source, err := os.Open(filePath); errors.Is(err, fs.ErrNotExist) {
+		return openFile(tempPath, filePath, encoding, false)
+	} else if err != nil {
+		return nil, 0, err
+	} else {
+		defer source.Close()
+		if file, err := os.OpenFile(tempPath, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0666); err != nil {
+			return nil, 0, err
+		} else if _, err := io.Copy(file, source); err != nil {
+			file.Close()
+			return nil, 0, err
+		} else {
+			return resumeFile(file, encoding)
+		}
+	}
+}
+
+// resumeFile positions the file after the last complete item in it, returning the number of items
+func resumeFile(file *os.File, encoding encoding) (*os.File, int, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, 0, err
+	} else if count, offset, err := encoding.read(bufio.NewReader(file)); err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("unable to append to %s: %w", file.Name(), err)
+	} else if err := file.Truncate(offset); err != nil {
+		file.Close()
+		return nil, 0, err
+	} else if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, 0, err
+	} else {
+		return file, count, nil
+	}
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (s nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,220 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sinks_test
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/sinks"
+	"github.com/klauspost/compress/zstd"
+)
+
+type document struct {
+	Data []int       `json:"data"`
+	Meta models.Meta `json:"meta"`
+}
+
+func stream(items ...int) <-chan int {
+	out := make(chan int, len(items))
+	for _, item := range items {
+		out <- item
+	}
+	close(out)
+	return out
+}
+
+func readDocument(t *testing.T, path string) document {
+	var doc document
+	if content, err := os.ReadFile(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := json.Unmarshal(content, &doc); err != nil {
+		t.Fatalf("output is not valid json: %v\n%s", err, content)
+	}
+	return doc
+}
+
+func TestWriteToFile(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		path    = filepath.Join(t.TempDir(), "output.json")
+		written []int
+	)
+
+	// a shorter output must replace a longer one
+	if err := sinks.WriteToFile(ctx, path, enums.OutputFormatJson, false, stream(1, 2, 3, 4, 5), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := sinks.WriteToFile(ctx, path, enums.OutputFormatJson, false, stream(1, 2), func(item int) { written = append(written, item) }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if doc := readDocument(t, path); len(doc.Data) != 2 || doc.Meta.Count != 2 {
+		t.Errorf("got %v, want %v", doc, []int{1, 2})
+	} else if len(written) != 2 {
+		t.Errorf("got %v written, want %v", len(written), 2)
+	} else if doc.Meta.Partial {
+		t.Error("a completed document should not be partial")
+	} else if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("expected the temporary file to have replaced the output")
+	}
+}
+
+func TestWriteToFileCancelled(t *testing.T) {
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		path        = filepath.Join(t.TempDir(), "output.json")
+		in          = make(chan int)
+	)
+
+	go func() {
+		in <- 1
+		in <- 2
+		cancel()
+	}()
+
+	if err := sinks.WriteToFile(ctx, path, enums.OutputFormatJson, false, in, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if doc := readDocument(t, path); !doc.Meta.Partial || doc.Meta.Count != len(doc.Data) || doc.Meta.Count == 0 {
+		t.Errorf("got %+v, want the items written before cancellation marked partial", doc)
+	}
+}
+
+func TestWriteToFileAppending(t *testing.T) {
+	ctx := context.Background()
+
+	for name, content := range map[string]string{
+		"complete":    "{\n\t\"data\": [\n\t\t1,\n\t\t2\n\t],\n\t\"meta\": {\"type\":\"azure\",\"version\":5,\"count\":2}\n}\n",
+		"interrupted": "{\n\t\"data\": [\n\t\t1,\n\t\t2",
+		"partial":     "{\n\t\"data\": [\n\t\t1,\n\t\t2\n\t],\n\t\"meta\": {\"type\":\"azure\",\"version\":5,\"count\":2,\"partial\":true}\n}\n",
+		"crashed":     "{\n\t\"data\": [\n\t\t1,\n\t\t2,\n\t\t{\"kind\":\"AZ",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "output.json")
+			if name == "crashed" {
+				// a crash leaves the output in the temporary file
+				if err := os.WriteFile(path, []byte("{}"), 0666); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				path += ".tmp"
+			}
+			if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			path = strings.TrimSuffix(path, ".tmp")
+			if err := sinks.WriteToFile(ctx, path, enums.OutputFormatJson, true, stream(3, 4), nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if doc := readDocument(t, path); len(doc.Data) != 4 || doc.Meta.Count != 4 || doc.Meta.Partial {
+				t.Errorf("got %v, want %v", doc, []int{1, 2, 3, 4})
+			}
+		})
+	}
+
+	// there's nothing to append to
+	path := filepath.Join(t.TempDir(), "output.json")
+	if err := sinks.WriteToFile(ctx, path, enums.OutputFormatJson, true, stream(1), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if doc := readDocument(t, path); doc.Meta.Count != 1 {
+		t.Errorf("got %v, want %v", doc.Meta.Count, 1)
+	}
+
+	if err := os.WriteFile(path, []byte("not azurehound output"), 0666); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := sinks.WriteToFile(ctx, path, enums.OutputFormatJson, true, stream(1), nil); err == nil {
+		t.Error("expected an error appending to a file azurehound did not write")
+	}
+}
+
+func TestWriteToFileFormats(t *testing.T) {
+	ctx := context.Background()
+
+	for _, format := range enums.OutputFormats() {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "output")
+			if err := sinks.WriteToFile(ctx, path, format, false, stream(1, 2, 3), nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer file.Close()
+
+			var reader io.Reader = file
+			if strings.HasSuffix(format, ".gz") {
+				if reader, err = gzip.NewReader(file); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			} else if strings.HasSuffix(format, ".zst") {
+				if decoder, err := zstd.NewReader(file); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				} else {
+					defer decoder.Close()
+					reader = decoder
+				}
+			}
+
+			var items []int
+			if strings.HasPrefix(format, enums.OutputFormatNdjson) {
+				decoder := json.NewDecoder(reader)
+				for decoder.More() {
+					var item int
+					if err := decoder.Decode(&item); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					items = append(items, item)
+				}
+			} else {
+				var doc document
+				if err := json.NewDecoder(reader).Decode(&doc); err != nil {
+					t.Fatalf("output is not valid json: %v", err)
+				} else if doc.Meta.Count != len(doc.Data) {
+					t.Errorf("got count %v, want %v", doc.Meta.Count, len(doc.Data))
+				}
+				items = doc.Data
+			}
+
+			if len(items) != 3 {
+				t.Errorf("got %v, want %v", items, []int{1, 2, 3})
+			}
+		})
+	}
+}
+
+func TestWriteToFileAppendingNdjson(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.ndjson")
+	if err := os.WriteFile(path, []byte("1\n2\n{\"kind\":"), 0666); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := sinks.WriteToFile(context.Background(), path, enums.OutputFormatNdjson, true, stream(3), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if content, err := os.ReadFile(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if string(content) != "1\n2\n3\n" {
+		t.Errorf("got %q, want %q", content, "1\n2\n3\n")
+	}
+
+	if err := sinks.WriteToFile(context.Background(), path, enums.OutputFormatNdjsonGzip, true, stream(4), nil); err == nil {
+		t.Error("expected an error appending to compressed output")
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sinks
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/pipeline"
+)
+
+// WriteToDirectory writes each item in the stream to a file in the directory named for the item's route, such as its
+// kind, e.g. AZUser.json. Each file is a complete output in the format with its own count, written to a temporary file
+// that replaces it once complete.
+func WriteToDirectory[T any](ctx context.Context, dirPath string, format enums.OutputFormat, stream <-chan T, route func(T) string) error {
+	if err := os.MkdirAll(dirPath, 0777); err != nil {
+		return err
+	} else if files, err := split(ctx, dirPath, ".tmp", format, stream, route); err != nil {
+		return err
+	} else {
+		for _, file := range files {
+			path := filepath.Join(dirPath, file.Name)
+			if err := os.Rename(path+".tmp", path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WriteToZip splits the stream into files as WriteToDirectory does and packages them with a manifest into a zip archive
+// named for the time it was created in the directory, returning the path of the archive
+func WriteToZip[T any](ctx context.Context, dirPath string, format enums.OutputFormat, stream <-chan T, route func(T) string) (string, error) {
+	manifest := models.Manifest{
+		Created: time.Now().UTC(),
+		Format:  format,
+	}
+
+	if _, compressed, err := parseFormat(format); err != nil {
+		return "", err
+	} else if compressed != "" {
+		return "", fmt.Errorf("the files in a zip archive are compressed by the archive and may not be %s", format)
+	} else if err := os.MkdirAll(dirPath, 0777); err != nil {
+		return "", err
+	} else if tempDir, err := os.MkdirTemp(dirPath, ".azurehound-"); err != nil {
+		return "", err
+	} else {
+		defer os.RemoveAll(tempDir)
+
+		if files, err := split(ctx, tempDir, "", format, stream, route); err != nil {
+			return "", err
+		} else {
+			manifest.Files = files
+			manifest.Partial = ctx.Err() != nil
+
+			archivePath := filepath.Join(dirPath, fmt.Sprintf("azurehound_%s.zip", manifest.Created.Format("20060102150405")))
+			if err := writeZip(archivePath+".tmp", tempDir, manifest); err != nil {
+				os.Remove(archivePath + ".tmp")
+				return "", err
+			} else {
+				return archivePath, os.Rename(archivePath+".tmp", archivePath)
+			}
+		}
+	}
+}
+
+// split writes the stream to a file per route in the directory, returning the files written in order of their names
+func split[T any](ctx context.Context, dirPath, suffix string, format enums.OutputFormat, stream <-chan T, route func(T) string) ([]models.ManifestFile, error) {
+	type splitFile struct {
+		file   *os.File
+		writer *itemWriter
+	}
+
+	encoding, compressed, err := parseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]splitFile{}
+	defer func() {
+		for _, file := range files {
+			file.file.Close()
+		}
+	}()
+
+	for item := range pipeline.OrDone(ctx.Done(), stream) {
+		key := route(item)
+		if _, ok := files[key]; !ok {
+			var file splitFile
+			if file.file, err = os.Create(filepath.Join(dirPath, key+"."+format+suffix)); err != nil {
+				return nil, err
+			} else if file.writer, err = newItemWriter(file.file, encoding, compressed, 0); err != nil {
+				return nil, err
+			}
+			files[key] = file
+		}
+
+		if err := files[key].writer.write(item); err != nil {
+			return nil, err
+		}
+	}
+
+	var manifest []models.ManifestFile
+	for key, file := range files {
+		if err := file.writer.close(ctx.Err() != nil); err != nil {
+			return nil, err
+		} else if err := file.file.Sync(); err != nil {
+			return nil, err
+		} else {
+			manifest = append(manifest, models.ManifestFile{
+				Name:  key + "." + format,
+				Kind:  key,
+				Count: file.writer.count,
+			})
+		}
+	}
+
+	sort.Slice(manifest, func(i, j int) bool {
+		return manifest[i].Name < manifest[j].Name
+	})
+	return manifest, nil
+}
+
+// writeZip packages the files of the manifest in the directory into a zip archive along with the manifest
+func writeZip(archivePath, dirPath string, manifest models.Manifest) error {
+	if archive, err := os.Create(archivePath); err != nil {
+		return err
+	} else {
+		defer archive.Close()
+		writer := zip.NewWriter(archive)
+
+		for _, file := range manifest.Files {
+			if err := addToZip(writer, filepath.Join(dirPath, file.Name), file.Name, manifest.Created); err != nil {
+				return err
+			}
+		}
+
+		if bytes, err := json.MarshalIndent(manifest, "", "\t"); err != nil {
+			return err
+		} else if entry, err := writer.CreateHeader(&zip.FileHeader{Name: "manifest.json", Method: zip.Deflate, Modified: manifest.Created}); err != nil {
+			return err
+		} else if _, err := entry.Write(bytes); err != nil {
+			return err
+		} else if err := writer.Close(); err != nil {
+			return err
+		} else {
+			return archive.Sync()
+		}
+	}
+}
+
+func addToZip(writer *zip.Writer, filePath, name string, modified time.Time) error {
+	if file, err := os.Open(filePath); err != nil {
+		return err
+	} else {
+		defer file.Close()
+		if entry, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified}); err != nil {
+			return err
+		} else {
+			_, err := io.Copy(entry, file)
+			return err
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sinks_test
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/sinks"
+)
+
+func parity(item int) string {
+	if item%2 == 0 {
+		return "even"
+	} else {
+		return "odd"
+	}
+}
+
+func TestWriteToDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "output")
+	if err := sinks.WriteToDirectory(context.Background(), dir, enums.OutputFormatJson, stream(1, 2, 3, 4, 5), parity); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, want := range map[string]int{"odd.json": 3, "even.json": 2} {
+		if doc := readDocument(t, filepath.Join(dir, name)); doc.Meta.Count != want || len(doc.Data) != want {
+			t.Errorf("got %+v in %s, want %v items", doc, name, want)
+		}
+	}
+}
+
+func TestWriteToZip(t *testing.T) {
+	dir := t.TempDir()
+	archivePath, err := sinks.WriteToZip(context.Background(), dir, enums.OutputFormatJson, stream(1, 2, 3, 4, 5), parity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer archive.Close()
+
+	var (
+		manifest models.Manifest
+		counts   = map[string]int{}
+	)
+	for _, file := range archive.File {
+		if entry, err := file.Open(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else if content, err := io.ReadAll(entry); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else if file.Name == "manifest.json" {
+			if err := json.Unmarshal(content, &manifest); err != nil {
+				t.Fatalf("manifest is not valid json: %v", err)
+			}
+		} else {
+			var doc document
+			if err := json.Unmarshal(content, &doc); err != nil {
+				t.Fatalf("%s is not valid json: %v", file.Name, err)
+			}
+			counts[file.Name] = doc.Meta.Count
+		}
+	}
+
+	if len(manifest.Files) != 2 {
+		t.Fatalf("got %v files in the manifest, want 2", len(manifest.Files))
+	}
+	for _, file := range manifest.Files {
+		if counts[file.Name] != file.Count {
+			t.Errorf("got %v items in %s, want %v", counts[file.Name], file.Name, file.Count)
+		}
+	}
+	if name := fmt.Sprintf("azurehound_%s.zip", manifest.Created.Format("20060102150405")); filepath.Base(archivePath) != name {
+		t.Errorf("got %v, want %v", filepath.Base(archivePath), name)
+	}
+
+	if _, err := sinks.WriteToZip(context.Background(), dir, enums.OutputFormatJsonGzip, stream(1), parity); err == nil {
+		t.Error("expected an error for compressed files in a zip archive")
+	}
+}
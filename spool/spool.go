@@ -0,0 +1,565 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package spool queues records on disk so that they survive until they have been delivered.
+package spool
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSegmentSize int64 = 16 << 20
+
+	indexFile     = "index.json"
+	segmentSuffix = ".seg"
+	headerSize    = 8 // the record's length followed by its CRC-32, both big-endian uint32s
+)
+
+var ErrClosed = errors.New("spool closed")
+
+// Options bound the records a spool holds
+type Options struct {
+	MaxSize     int64             // The maximum size of the records awaiting delivery; zero is unbounded
+	SegmentSize int64             // The size a segment file may grow to before another is started
+	Retention   time.Duration     // How long records await delivery before they are dropped; zero keeps them until delivered
+	Expired     func(records int) // If not nil, called with the number of records dropped for exceeding the retention
+}
+
+// Spool is a first-in, first-out queue of records kept in a directory. Records are appended to segment files and an
+// index records the position of the oldest record awaiting delivery, so that a spool that is reopened resumes delivery
+// where it left off. Several records may be out for delivery at once and acknowledged in any order; the index only
+// moves past records once every record before them has been acknowledged. Segments are removed once all of their
+// records have been delivered or have expired.
+type Spool struct {
+	mutex    sync.Mutex
+	changed  chan struct{}
+	dir      string
+	options  Options
+	segments []*segment
+	writer   *os.File
+	index    index
+	read     index     // The position of the next record for Next to return
+	leased   []*Record // The records returned by Next that await acknowledgement, oldest first
+	closed   bool
+}
+
+// Record is a record out for delivery, which remains in the spool until it is acknowledged
+type Record struct {
+	Data     []byte
+	position index
+	size     int64
+	acked    bool
+}
+
+type segment struct {
+	seq      int64
+	size     int64     // The size of the segment file
+	records  int       // The number of records in the segment awaiting delivery
+	modified time.Time // When a record was last appended to the segment
+}
+
+type index struct {
+	Segment int64 `json:"segment"` // The segment holding the next record to deliver
+	Offset  int64 `json:"offset"`  // The offset of the next record to deliver within the segment
+}
+
+// Open opens the spool in the directory, creating it if it does not exist
+func Open(dir string, options Options) (*Spool, error) {
+	if options.SegmentSize <= 0 {
+		options.SegmentSize = DefaultSegmentSize
+	}
+
+	spool := &Spool{changed: make(chan struct{}), dir: dir, options: options}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	} else if err := spool.readIndex(); err != nil {
+		return nil, err
+	} else if err := spool.load(); err != nil {
+		spool.Close()
+		return nil, err
+	} else {
+		return spool, nil
+	}
+}
+
+// Put appends the record to the spool. If the spool is full, Put blocks until enough records have been delivered or
+// have expired for the record to fit, or until the context is done.
+func (s *Spool) Put(ctx context.Context, record []byte) error {
+	framed := int64(headerSize + len(record))
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for {
+		if s.closed {
+			return ErrClosed
+		} else if err := s.expire(); err != nil {
+			return err
+		} else if s.pending() == 0 || s.options.MaxSize <= 0 || s.size()+framed <= s.options.MaxSize {
+			break
+		} else if err := s.wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	if last := s.last(); s.writer == nil || last.size > 0 && last.size+framed > s.options.SegmentSize {
+		if err := s.roll(); err != nil {
+			return err
+		}
+	}
+
+	buffer := make([]byte, framed)
+	binary.BigEndian.PutUint32(buffer[0:4], uint32(len(record)))
+	binary.BigEndian.PutUint32(buffer[4:8], crc32.ChecksumIEEE(record))
+	copy(buffer[headerSize:], record)
+
+	last := s.last()
+	if _, err := s.writer.Write(buffer); err != nil {
+		s.writer.Truncate(last.size)
+		return err
+	} else if err := s.writer.Sync(); err != nil {
+		s.writer.Truncate(last.size)
+		return err
+	} else {
+		last.size += framed
+		last.records++
+		last.modified = time.Now()
+		s.notify()
+		return nil
+	}
+}
+
+// Next returns the oldest record that is not already out for delivery, blocking until there is one or the context is
+// done. The record is returned again after the spool is reopened unless it is acknowledged.
+func (s *Spool) Next(ctx context.Context) (*Record, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for {
+		if s.closed {
+			return nil, ErrClosed
+		} else if err := s.expire(); err != nil {
+			return nil, err
+		} else if s.pending() > len(s.leased) {
+			break
+		} else if err := s.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// move past segments that have been read to their end
+	current := s.segment(s.read.Segment)
+	for s.read.Offset >= s.segments[current].size && current < len(s.segments)-1 {
+		current++
+		s.read = index{Segment: s.segments[current].seq}
+	}
+
+	if file, err := os.Open(s.segmentPath(s.read.Segment)); err != nil {
+		return nil, err
+	} else {
+		defer file.Close()
+		if data, err := readRecord(io.NewSectionReader(file, s.read.Offset, s.segments[current].size-s.read.Offset)); err != nil {
+			return nil, fmt.Errorf("unable to read spooled record: %w", err)
+		} else {
+			record := &Record{Data: data, position: s.read, size: int64(headerSize + len(data))}
+			s.read.Offset += record.size
+			s.leased = append(s.leased, record)
+			return record, nil
+		}
+	}
+}
+
+// Ack removes the record from the spool once it has been delivered. It does nothing if the record has since expired.
+func (s *Spool) Ack(record *Record) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	found := false
+	for _, leased := range s.leased {
+		if leased == record {
+			found = true
+			leased.acked = true
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	defer s.notify()
+	for len(s.leased) > 0 && s.leased[0].acked {
+		s.index.Offset += s.leased[0].size
+		s.segments[0].records--
+		s.leased = s.leased[1:]
+		if err := s.compact(); err != nil {
+			return err
+		}
+	}
+	return s.writeIndex()
+}
+
+// Drain blocks until every record in the spool has been delivered or has expired, or until the context is done
+func (s *Spool) Drain(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for {
+		if s.closed {
+			return ErrClosed
+		} else if err := s.expire(); err != nil {
+			return err
+		} else if s.pending() == 0 {
+			return nil
+		} else if err := s.wait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// Pending returns the number of records that remain in the spool
+func (s *Spool) Pending() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.pending()
+}
+
+// Close closes the spool, releasing any callers blocked on it
+func (s *Spool) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+	s.notify()
+	if s.writer != nil {
+		return s.writer.Close()
+	} else {
+		return nil
+	}
+}
+
+// wait releases the lock until the spool changes or the context is done
+func (s *Spool) wait(ctx context.Context) error {
+	changed := s.changed
+	s.mutex.Unlock()
+	defer s.mutex.Lock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-changed:
+		return nil
+	}
+}
+
+func (s *Spool) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *Spool) pending() int {
+	count := 0
+	for _, segment := range s.segments {
+		count += segment.records
+	}
+	return count
+}
+
+// size returns the size of the records awaiting delivery, including any delivered since the head segment was started
+func (s *Spool) size() int64 {
+	size := -s.index.Offset
+	for _, segment := range s.segments {
+		size += segment.size
+	}
+	return size
+}
+
+func (s *Spool) last() *segment {
+	if len(s.segments) == 0 {
+		return nil
+	} else {
+		return s.segments[len(s.segments)-1]
+	}
+}
+
+// expire drops the head segments whose records have all exceeded the retention
+func (s *Spool) expire() error {
+	if s.options.Retention <= 0 {
+		return nil
+	}
+
+	var (
+		cutoff  = time.Now().Add(-s.options.Retention)
+		dropped = 0
+	)
+	for len(s.segments) > 0 && s.segments[0].records > 0 && s.segments[0].modified.Before(cutoff) {
+		head := s.segments[0]
+		dropped += head.records
+		head.records = 0
+		for len(s.leased) > 0 && s.leased[0].position.Segment == head.seq {
+			s.leased = s.leased[1:]
+		}
+
+		if len(s.segments) > 1 {
+			if err := s.compact(); err != nil {
+				return err
+			}
+		} else {
+			// the next record is appended to a new segment that replaces this one
+			s.index.Offset = head.size
+			s.read = s.index
+			if err := s.writeIndex(); err != nil {
+				return err
+			} else if s.writer != nil {
+				if err := s.writer.Close(); err != nil {
+					return err
+				}
+				s.writer = nil
+			}
+		}
+	}
+
+	if dropped > 0 {
+		s.notify()
+		if s.options.Expired != nil {
+			s.options.Expired(dropped)
+		}
+	}
+	return nil
+}
+
+// roll starts a new segment for records to be appended to
+func (s *Spool) roll() error {
+	seq := s.index.Segment
+	if last := s.last(); last != nil {
+		seq = last.seq + 1
+	}
+
+	if s.writer != nil {
+		if err := s.writer.Close(); err != nil {
+			return err
+		}
+		s.writer = nil
+	}
+
+	if file, err := os.OpenFile(s.segmentPath(seq), os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_APPEND, 0600); err != nil {
+		return err
+	} else {
+		s.writer = file
+		s.segments = append(s.segments, &segment{seq: seq, modified: time.Now()})
+		if len(s.segments) == 1 {
+			s.index = index{Segment: seq}
+			s.read = s.index
+			if err := s.writeIndex(); err != nil {
+				return err
+			}
+		}
+		return s.compact()
+	}
+}
+
+// compact removes the head segments that have no records awaiting delivery, keeping the segment being appended to
+func (s *Spool) compact() error {
+	for len(s.segments) > 1 && s.segments[0].records == 0 {
+		head := s.segments[0]
+		s.segments = s.segments[1:]
+		s.index = index{Segment: s.segments[0].seq}
+
+		// the index must move past the segment before it is removed
+		if err := s.writeIndex(); err != nil {
+			return err
+		} else if err := os.Remove(s.segmentPath(head.seq)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	if s.read.Segment < s.index.Segment {
+		s.read = s.index
+	}
+	return nil
+}
+
+// segment returns the position of the segment in the spool's segments
+func (s *Spool) segment(seq int64) int {
+	for i, segment := range s.segments {
+		if segment.seq == seq {
+			return i
+		}
+	}
+	return 0
+}
+
+func (s *Spool) segmentPath(seq int64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, segmentSuffix))
+}
+
+func (s *Spool) readIndex() error {
+	if content, err := os.ReadFile(filepath.Join(s.dir, indexFile)); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	} else if err := json.Unmarshal(content, &s.index); err != nil {
+		return fmt.Errorf("unable to read spool index: %w", err)
+	} else {
+		return nil
+	}
+}
+
+// writeIndex replaces the index file so that it is only ever complete
+func (s *Spool) writeIndex() error {
+	path := filepath.Join(s.dir, indexFile)
+	if content, err := json.Marshal(s.index); err != nil {
+		return err
+	} else if file, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600); err != nil {
+		return err
+	} else if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	} else if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	} else if err := file.Close(); err != nil {
+		return err
+	} else {
+		return os.Rename(path+".tmp", path)
+	}
+}
+
+// load reads the segments in the directory, removing those already delivered and truncating any record that was
+// partially written when the spool was last closed
+func (s *Spool) load() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+
+	var seqs []int64
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && strings.HasSuffix(name, segmentSuffix) {
+			if seq, err := strconv.ParseInt(strings.TrimSuffix(name, segmentSuffix), 10, 64); err == nil {
+				seqs = append(seqs, seq)
+			}
+		}
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+
+	for _, seq := range seqs {
+		if seq < s.index.Segment {
+			if err := os.Remove(s.segmentPath(seq)); err != nil {
+				return err
+			}
+		} else if segment, err := s.scan(seq); err != nil {
+			return err
+		} else {
+			s.segments = append(s.segments, segment)
+		}
+	}
+
+	if len(s.segments) > 0 {
+		if s.segments[0].seq != s.index.Segment {
+			s.index = index{Segment: s.segments[0].seq}
+		}
+		if writer, err := os.OpenFile(s.segmentPath(s.last().seq), os.O_WRONLY|os.O_APPEND, 0600); err != nil {
+			return err
+		} else {
+			s.writer = writer
+		}
+		if err := s.writeIndex(); err != nil {
+			return err
+		}
+	}
+	s.read = s.index
+	return s.compact()
+}
+
+// scan counts the records in the segment awaiting delivery and truncates the segment after its last complete record
+func (s *Spool) scan(seq int64) (*segment, error) {
+	file, err := os.OpenFile(s.segmentPath(seq), os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		segment = &segment{seq: seq, modified: info.ModTime()}
+		start   int64
+		reader  = io.NewSectionReader(file, 0, info.Size())
+	)
+	if seq == s.index.Segment {
+		start = s.index.Offset
+	}
+
+	for {
+		if record, err := readRecord(reader); err != nil {
+			break
+		} else {
+			if segment.size >= start {
+				segment.records++
+			}
+			segment.size += int64(headerSize + len(record))
+		}
+	}
+
+	if segment.size < info.Size() {
+		if err := file.Truncate(segment.size); err != nil {
+			return nil, err
+		}
+	}
+	if seq == s.index.Segment && s.index.Offset > segment.size {
+		s.index.Offset = segment.size
+	}
+	return segment, nil
+}
+
+// readRecord reads the next record, returning an error if the record is incomplete or corrupt
+func readRecord(reader *io.SectionReader) ([]byte, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	} else if offset, err := reader.Seek(0, io.SeekCurrent); err != nil {
+		return nil, err
+	} else if length := int64(binary.BigEndian.Uint32(header[0:4])); length > reader.Size()-offset {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	record := make([]byte, binary.BigEndian.Uint32(header[0:4]))
+	if _, err := io.ReadFull(reader, record); err != nil {
+		return nil, err
+	} else if crc32.ChecksumIEEE(record) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, fmt.Errorf("record checksum mismatch")
+	} else {
+		return record, nil
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package spool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSpoolResume(t *testing.T) {
+	dir := t.TempDir()
+
+	spool, err := Open(dir, Options{SegmentSize: 32})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := spool.Put(context.Background(), []byte(fmt.Sprintf("record %d", i))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if record, err := spool.Next(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else if string(record.Data) != fmt.Sprintf("record %d", i) {
+			t.Errorf("got %s, want record %d", record.Data, i)
+		} else if err := spool.Ack(record); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// a record that is not acknowledged is delivered again once the spool is reopened
+	if _, err := spool.Next(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := spool.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spool, err = Open(dir, Options{SegmentSize: 32})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer spool.Close()
+
+	if spool.Pending() != 3 {
+		t.Errorf("got %d pending records, want 3", spool.Pending())
+	}
+	for i := 2; i < 5; i++ {
+		if record, err := spool.Next(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else if string(record.Data) != fmt.Sprintf("record %d", i) {
+			t.Errorf("got %s, want record %d", record.Data, i)
+		} else if err := spool.Ack(record); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := spool.Drain(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if segments, _ := filepath.Glob(filepath.Join(dir, "*"+segmentSuffix)); len(segments) != 1 {
+		t.Errorf("got %d segments, want the one being appended to", len(segments))
+	}
+}
+
+func TestSpoolAckOutOfOrder(t *testing.T) {
+	dir := t.TempDir()
+
+	spool, err := Open(dir, Options{SegmentSize: 32})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		if err := spool.Put(context.Background(), []byte(fmt.Sprintf("record %d", i))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var records []*Record
+	for i := 0; i < 3; i++ {
+		if record, err := spool.Next(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else {
+			records = append(records, record)
+		}
+	}
+
+	// the first record is still awaiting acknowledgement, so the records after it remain in the spool
+	for _, record := range records[1:] {
+		if err := spool.Ack(record); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if spool.Pending() != 4 {
+		t.Errorf("got %d pending records, want 4", spool.Pending())
+	}
+	spool.Close()
+
+	spool, err = Open(dir, Options{SegmentSize: 32})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer spool.Close()
+
+	if spool.Pending() != 4 {
+		t.Errorf("got %d pending records, want 4 after reopening", spool.Pending())
+	}
+
+	records = nil
+	for i := 0; i < 4; i++ {
+		if record, err := spool.Next(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else if string(record.Data) != fmt.Sprintf("record %d", i) {
+			t.Errorf("got %s, want record %d", record.Data, i)
+		} else {
+			records = append(records, record)
+		}
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		if err := spool.Ack(records[i]); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if spool.Pending() != 0 {
+		t.Errorf("got %d pending records, want 0", spool.Pending())
+	}
+}
+
+func TestSpoolPartialRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	spool, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, record := range []string{"first", "second"} {
+		if err := spool.Put(context.Background(), []byte(record)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	spool.Close()
+
+	// simulate a crash part way through appending the second record
+	segment := spool.segmentPath(spool.index.Segment)
+	if info, err := os.Stat(segment); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := os.Truncate(segment, info.Size()-3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spool, err = Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer spool.Close()
+
+	if spool.Pending() != 1 {
+		t.Errorf("got %d pending records, want 1", spool.Pending())
+	} else if err := spool.Put(context.Background(), []byte("third")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"first", "third"} {
+		if record, err := spool.Next(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else if string(record.Data) != want {
+			t.Errorf("got %s, want %s", record.Data, want)
+		} else if err := spool.Ack(record); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestSpoolMaxSize(t *testing.T) {
+	record := []byte("0123456789")
+
+	spool, err := Open(t.TempDir(), Options{MaxSize: 2 * (headerSize + int64(len(record)))})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer spool.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := spool.Put(context.Background(), record); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := spool.Put(ctx, record); err != context.DeadlineExceeded {
+		t.Errorf("got %v, want the put to block while the spool is full", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- spool.Put(context.Background(), record)
+	}()
+
+	if record, err := spool.Next(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := spool.Ack(record); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("put remained blocked after a record was delivered")
+	}
+}
+
+func TestSpoolRetention(t *testing.T) {
+	var expired int
+
+	spool, err := Open(t.TempDir(), Options{Retention: 10 * time.Millisecond, Expired: func(records int) { expired += records }})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer spool.Close()
+
+	if err := spool.Put(context.Background(), []byte("stale")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if err := spool.Put(context.Background(), []byte("fresh")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if record, err := spool.Next(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if string(record.Data) != "fresh" {
+		t.Errorf("got %s, want fresh", record.Data)
+	} else if expired != 1 {
+		t.Errorf("got %d expired records, want 1", expired)
+	}
+}